@@ -2,8 +2,13 @@ package cmd
 
 import (
 	"database/sql"
+	"fmt"
+	"io"
 	"log"
 	"os"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/Ayobami6/schema_dump/internal"
 	_ "github.com/lib/pq"
@@ -11,12 +16,37 @@ import (
 )
 
 var (
-	dbType    string
-	dbURL     string
-	tableName string
-	lang      string
+	dbType        string
+	dbURL         string
+	tableName     string
+	lang          string
+	dialect       string
+	outPath       string
+	split         bool
+	format        string
+	reportFormat  string
+	fromURL       string
+	toURL         string
+	verifyURL     string
+	snapshotPath  string
+	junitPath     string
+	jsonPath      string
+	migrationFmt  string
+	migrationNm   string
+	migrationsDir string
+	scratchURL    string
+	createScratch bool
+	migrationTool string
+	packageName   string
+	useAI         bool
+	frameworkName string
+	managed       bool
 )
 
+// exitUnsafeMigration is returned by analyze-migration when a statement is
+// dangerous to run against a busy database.
+const exitUnsafeMigration = 3
+
 var RootCmd = &cobra.Command{
 	Use:   "Models Dump",
 	Short: "Django Models Table Schema Dump",
@@ -30,6 +60,12 @@ var dumpSchemaCmd = &cobra.Command{
 		if dbType != "postgres" {
 			log.Fatalf("Only postgres is supported for now")
 		}
+		if !slices.Contains(internal.SupportedDialects, dialect) {
+			log.Fatalf("Dialect %s is not supported", dialect)
+		}
+		if !slices.Contains([]string{"sql", "json", "jsonschema", "openapi", "graphql", "proto", "avro"}, format) {
+			log.Fatalf("Format %s is not supported", format)
+		}
 
 		db, err := sql.Open("postgres", dbURL)
 		if err != nil {
@@ -37,7 +73,16 @@ var dumpSchemaCmd = &cobra.Command{
 		}
 		defer db.Close()
 
-		internal.PostgresSchemaDump(db, tableName)
+		opts := internal.DumpOptions{
+			TableName: tableName,
+			Dialect:   dialect,
+			Format:    format,
+			Package:   packageName,
+			Output:    internal.Output{Path: outPath, Split: split},
+		}
+		if err := internal.PostgresSchemaDump(db, opts); err != nil {
+			log.Fatalf("Failed to dump schema: %v", err)
+		}
 	},
 }
 
@@ -57,33 +102,19 @@ var listTableCommand = &cobra.Command{
 
 		tables := internal.Tables(db)
 		// write the table to json
-		outFile, err := os.Create("tables.json")
-		if err != nil {
-			log.Fatalf("Failed to create file: %v", err)
-		}
-		defer outFile.Close()
-		// write to the outfile
-		_, err = outFile.WriteString("[\n")
-		if err != nil {
-			log.Fatalf("Failed to write to file: %v", err)
-		}
+		var buf strings.Builder
+		buf.WriteString("[\n")
 		for i, table := range tables {
-			_, err = outFile.WriteString("{\"table_name\": \"" + table + "\"}")
-			if err != nil {
-				log.Fatalf("Failed to write to file: %v", err)
-			}
+			buf.WriteString("{\"table_name\": \"" + table + "\"}")
 			if i != len(tables)-1 {
-				_, err = outFile.WriteString(",\n")
-				if err != nil {
-					log.Fatalf("Failed to write to file: %v", err)
-				}
+				buf.WriteString(",\n")
 			}
 		}
-		_, err = outFile.WriteString("]\n")
-		if err != nil {
-			log.Fatalf("Failed to write to file: %v", err)
+		buf.WriteString("]\n")
+		out := internal.Output{Path: outPath}
+		if err := out.Write([]internal.File{{Name: "tables.json", Content: []byte(buf.String())}}); err != nil {
+			log.Fatalf("Failed to write tables: %v", err)
 		}
-		log.Println("Tables dumped to tables.json")
 
 	},
 }
@@ -102,16 +133,37 @@ var transformCommand = &cobra.Command{
 		}
 		defer db.Close()
 		supportedLangs := map[string]bool{
-			"py":   true,
-			"ts":   true,
-			"java": true,
-			"rs":   true,
-			"go":   true,
+			"py":     true,
+			"ts":     true,
+			"java":   true,
+			"rs":     true,
+			"go":     true,
+			"prisma": true,
+			"kt":     true,
+			"cs":     true,
 		}
 		if _, ok := supportedLangs[lang]; !ok {
 			log.Fatalf("Language %s is not supported", lang)
 		}
-		err = internal.TransformToORMModel(lang, tableName, db)
+		if frameworkName != "" && !useAI {
+			if _, ok := internal.GeneratorFor(lang, frameworkName); !ok {
+				log.Fatalf("Framework %s is not supported for %s (available: %s)", frameworkName, lang, strings.Join(internal.Frameworks(lang), ", "))
+			}
+		}
+		if !useAI {
+			if _, ok := internal.GeneratorFor(lang, frameworkName); ok {
+				opts := internal.GenerateOptions{Package: packageName, Managed: managed}
+				if err := internal.GenerateModels(db, lang, frameworkName, tableName, opts, internal.Output{Path: outPath}); err != nil {
+					log.Fatalf("Failed to generate models: %v", err)
+				}
+				log.Printf("Schema transformed to %s models", lang)
+				return
+			}
+		}
+		if tableName == "" {
+			log.Fatalf("--table is required for the AI transform")
+		}
+		err = internal.TransformToORMModel(lang, tableName, db, internal.Output{Path: outPath})
 		if err != nil {
 			log.Fatalf("Failed to transform schema: %v", err)
 		}
@@ -120,21 +172,424 @@ var transformCommand = &cobra.Command{
 	},
 }
 
+var diffCommand = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare the schemas of two databases or snapshots",
+	Run: func(cmd *cobra.Command, args []string) {
+		from, err := internal.LoadSchema(fromURL)
+		if err != nil {
+			log.Fatalf("Failed to load %s: %v", fromURL, err)
+		}
+		to, err := internal.LoadSchema(toURL)
+		if err != nil {
+			log.Fatalf("Failed to load %s: %v", toURL, err)
+		}
+
+		diff := internal.DiffSchemas(from, to)
+		var report []byte
+		switch reportFormat {
+		case "text":
+			report = []byte(diff.Report())
+		case "json":
+			report, err = diff.JSON()
+			if err != nil {
+				log.Fatalf("Failed to render diff: %v", err)
+			}
+		default:
+			log.Fatalf("Format %s is not supported", reportFormat)
+		}
+
+		out := internal.Output{Path: outPath}
+		if outPath == "" {
+			out.Path = "-"
+		}
+		if err := out.Write([]internal.File{{Name: "diff." + reportFormat, Content: report}}); err != nil {
+			log.Fatalf("Failed to write diff: %v", err)
+		}
+	},
+}
+
+var migratePlanCommand = &cobra.Command{
+	Use:   "migrate-plan",
+	Short: "Generate up and down migration SQL between two schemas",
+	Run: func(cmd *cobra.Command, args []string) {
+		from, err := internal.LoadSchema(fromURL)
+		if err != nil {
+			log.Fatalf("Failed to load %s: %v", fromURL, err)
+		}
+		to, err := internal.LoadSchema(toURL)
+		if err != nil {
+			log.Fatalf("Failed to load %s: %v", toURL, err)
+		}
+
+		if !slices.Contains(internal.MigrationFormats, migrationFmt) {
+			log.Fatalf("Migration format %s is not supported", migrationFmt)
+		}
+
+		plan := internal.PlanMigration(from, to)
+		for _, warning := range plan.Warnings {
+			log.Printf("Warning: %s", warning)
+		}
+		if plan.Empty() {
+			log.Println("Schemas are identical, nothing to migrate")
+			return
+		}
+
+		if verifyURL != "" {
+			db, err := sql.Open("postgres", verifyURL)
+			if err != nil {
+				log.Fatalf("Failed to connect: %v", err)
+			}
+			defer db.Close()
+			if err := internal.VerifyMigration(db, from, to, plan); err != nil {
+				log.Fatalf("Migration verification failed: %v", err)
+			}
+			log.Println("Migration verified: up reaches the target schema and down restores the original")
+		}
+
+		dir := outPath
+		if dir == "" || dir == "-" {
+			dir = "."
+		}
+		files, err := internal.MigrationFiles(migrationFmt, dir, migrationNm, plan, time.Now())
+		if err != nil {
+			log.Fatalf("Failed to render migration: %v", err)
+		}
+		// Framework migrations always land in the migrations directory.
+		out := internal.Output{Path: outPath, Split: migrationFmt != "sql" && outPath != "-"}
+		// Up and down concatenated would migrate and revert in one go, so a
+		// single file or stdout only gets the up migration.
+		if len(files) > 1 && out.Target(files[0].Name) == out.Target(files[1].Name) {
+			for _, f := range files[1:] {
+				log.Printf("Warning: %s not written, --out must be a directory to write it", f.Name)
+			}
+			files = files[:1]
+		}
+		if err := out.Write(files); err != nil {
+			log.Fatalf("Failed to write migration: %v", err)
+		}
+	},
+}
+
+var checkCommand = &cobra.Command{
+	Use:   "check",
+	Short: "Check a live database for drift against a schema snapshot",
+	Long: `Check a live database for drift against a schema snapshot.
+
+Exits with 0 when the database matches the snapshot, 2 when it only has
+additions (new tables, columns, indexes or enum values) and 3 when something
+was removed or changed. Errors exit with 1.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		expected, err := internal.ReadSnapshot(snapshotPath)
+		if err != nil {
+			log.Fatalf("Failed to load snapshot: %v", err)
+		}
+
+		db, err := sql.Open("postgres", dbURL)
+		if err != nil {
+			log.Fatalf("Failed to connect: %v", err)
+		}
+		defer db.Close()
+		actual, err := internal.IntrospectPostgres(db, internal.DefaultSchema)
+		if err != nil {
+			log.Fatalf("Failed to read schema: %v", err)
+		}
+
+		report := internal.CheckDrift(expected, actual)
+		fmt.Print(report.Text())
+
+		if junitPath != "" {
+			data, err := report.JUnit()
+			if err != nil {
+				log.Fatalf("Failed to render junit report: %v", err)
+			}
+			if err := (internal.Output{Path: junitPath}).Write([]internal.File{{Name: "drift.xml", Content: data}}); err != nil {
+				log.Fatalf("Failed to write junit report: %v", err)
+			}
+		}
+		if jsonPath != "" {
+			data, err := report.JSON()
+			if err != nil {
+				log.Fatalf("Failed to render json report: %v", err)
+			}
+			if err := (internal.Output{Path: jsonPath}).Write([]internal.File{{Name: "drift.json", Content: data}}); err != nil {
+				log.Fatalf("Failed to write json report: %v", err)
+			}
+		}
+
+		if code := report.ExitCode(); code != 0 {
+			os.Exit(code)
+		}
+	},
+}
+
+var verifyMigrationsCommand = &cobra.Command{
+	Use:   "verify-migrations",
+	Short: "Check that a migrations directory reproduces the live schema",
+	Long: `Check that a migrations directory reproduces the live schema.
+
+The up migrations are replayed into a scratch database, both databases are
+introspected and every difference is reported, catching changes applied to
+the live database that never made it into a migration. --scratch-url names
+an empty database to replay into; --create-scratch-on-source creates a
+temporary one on the server of --url instead and drops it again.
+
+Exits with 0 when the schemas match, 2 when the live database only has
+additions and 3 when something was removed or changed. Errors exit with 1.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// The --url server is usually production, creating databases there
+		// has to be asked for.
+		if scratchURL == "" && !createScratch {
+			log.Fatalf("Pass --scratch-url with an empty database to replay into, or --create-scratch-on-source to create a temporary one on the --url server")
+		}
+
+		scripts, skipped, err := internal.UpMigrations(migrationsDir)
+		if err != nil {
+			log.Fatalf("Failed to load migrations: %v", err)
+		}
+		for _, warning := range skipped {
+			log.Printf("Warning: %s", warning)
+		}
+
+		db, err := sql.Open("postgres", dbURL)
+		if err != nil {
+			log.Fatalf("Failed to connect: %v", err)
+		}
+		defer db.Close()
+		live, err := internal.IntrospectPostgres(db, internal.DefaultSchema)
+		if err != nil {
+			log.Fatalf("Failed to read schema: %v", err)
+		}
+		internal.RemoveMigrationHistory(live)
+
+		var report *internal.DriftReport
+		if scratchURL != "" {
+			report, err = replayAndCheck(scratchURL, scripts, live)
+		} else {
+			created, drop, createErr := internal.CreateScratchDatabase(db, dbURL)
+			if createErr != nil {
+				log.Fatalf("Failed to create scratch database, pass --scratch-url instead: %v", createErr)
+			}
+			report, err = replayAndCheck(created, scripts, live)
+			if dropErr := drop(); dropErr != nil {
+				log.Printf("Warning: %v", dropErr)
+			}
+		}
+		if err != nil {
+			log.Fatalf("Failed to replay migrations: %v", err)
+		}
+		log.Printf("Replayed %d migrations from %s", len(scripts), migrationsDir)
+		fmt.Print(report.Text())
+
+		if code := report.ExitCode(); code != 0 {
+			os.Exit(code)
+		}
+	},
+}
+
+// replayAndCheck applies the migrations to the scratch database and compares
+// the result with the live schema. The scratch connection is closed before
+// returning so the database can be dropped.
+func replayAndCheck(scratchURL string, scripts []internal.File, live *internal.Schema) (*internal.DriftReport, error) {
+	scratch, err := sql.Open("postgres", scratchURL)
+	if err != nil {
+		return nil, err
+	}
+	defer scratch.Close()
+	migrated, err := internal.ReplayMigrations(scratch, scripts)
+	if err != nil {
+		return nil, err
+	}
+	return internal.CheckDrift(migrated, live), nil
+}
+
+var migrationsCommand = &cobra.Command{
+	Use:   "migrations",
+	Short: "Inspect the migration history of a database",
+}
+
+var migrationsStatusCommand = &cobra.Command{
+	Use:   "status",
+	Short: "List applied migrations and compare them with a migrations directory",
+	Long: `List applied migrations and compare them with a migrations directory.
+
+The migration tool is detected from its history table: django_migrations,
+schema_migrations (golang-migrate or Rails), goose_db_version,
+flyway_schema_history or alembic_version. With --dir, pending, missing and
+out-of-order migrations are reported too.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if reportFormat != "text" && reportFormat != "json" {
+			log.Fatalf("Format %s is not supported", reportFormat)
+		}
+		db, err := sql.Open("postgres", dbURL)
+		if err != nil {
+			log.Fatalf("Failed to connect: %v", err)
+		}
+		defer db.Close()
+
+		tools, err := internal.DetectMigrationTools(db)
+		if err != nil {
+			log.Fatalf("Failed to detect migration tool: %v", err)
+		}
+		if migrationTool != "" {
+			if !slices.Contains(tools, migrationTool) {
+				log.Fatalf("No %s migration history found", migrationTool)
+			}
+			tools = []string{migrationTool}
+		}
+		if len(tools) == 0 {
+			log.Fatalf("No migration history table found")
+		}
+		if len(tools) > 1 && migrationsDir != "" {
+			log.Fatalf("Found history tables of %s, pick one with --tool", strings.Join(tools, ", "))
+		}
+
+		var statuses []*internal.MigrationStatus
+		for _, tool := range tools {
+			status, err := internal.ReadMigrationStatus(db, tool, migrationsDir)
+			if err != nil {
+				log.Fatalf("Failed to read migration status: %v", err)
+			}
+			statuses = append(statuses, status)
+		}
+
+		var report []byte
+		if reportFormat == "json" {
+			report, err = internal.MigrationStatusJSON(statuses)
+			if err != nil {
+				log.Fatalf("Failed to render migration status: %v", err)
+			}
+		} else {
+			for _, status := range statuses {
+				report = append(report, status.Text()...)
+			}
+		}
+		out := internal.Output{Path: outPath}
+		if outPath == "" {
+			out.Path = "-"
+		}
+		if err := out.Write([]internal.File{{Name: "migrations." + reportFormat, Content: report}}); err != nil {
+			log.Fatalf("Failed to write migration status: %v", err)
+		}
+	},
+}
+
+var analyzeMigrationCommand = &cobra.Command{
+	Use:   "analyze-migration [file...]",
+	Short: "Analyze migration SQL for locks, table rewrites and breaking changes",
+	Long: `Analyze migration SQL for locks, table rewrites and breaking changes.
+
+Every statement is classified by the table lock it takes, whether it rewrites
+the table and whether it breaks code deployed against the old schema, with a
+safer expand/contract sequence where one exists. Pass - to read from stdin.
+Exits with 3 when a statement is dangerous, 0 otherwise.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if reportFormat != "text" && reportFormat != "json" {
+			log.Fatalf("Format %s is not supported", reportFormat)
+		}
+		var scripts []internal.File
+		for _, path := range args {
+			var content []byte
+			var err error
+			if path == "-" {
+				content, err = io.ReadAll(os.Stdin)
+				path = "stdin"
+			} else {
+				content, err = os.ReadFile(path)
+			}
+			if err != nil {
+				log.Fatalf("Failed to read %s: %v", path, err)
+			}
+			scripts = append(scripts, internal.File{Name: path, Content: content})
+		}
+
+		analysis := internal.AnalyzeMigration(scripts)
+		report := []byte(analysis.Text())
+		if reportFormat == "json" {
+			data, err := analysis.JSON()
+			if err != nil {
+				log.Fatalf("Failed to render analysis: %v", err)
+			}
+			report = data
+		}
+		out := internal.Output{Path: outPath}
+		if outPath == "" {
+			out.Path = "-"
+		}
+		if err := out.Write([]internal.File{{Name: "analysis." + reportFormat, Content: report}}); err != nil {
+			log.Fatalf("Failed to write analysis: %v", err)
+		}
+		if analysis.Risk == internal.DangerRisk {
+			os.Exit(exitUnsafeMigration)
+		}
+	},
+}
+
 func init() {
 	RootCmd.AddCommand(dumpSchemaCmd)
 	RootCmd.AddCommand(listTableCommand)
 	RootCmd.AddCommand(transformCommand)
+	RootCmd.AddCommand(diffCommand)
+	RootCmd.AddCommand(migratePlanCommand)
+	RootCmd.AddCommand(checkCommand)
+	RootCmd.AddCommand(verifyMigrationsCommand)
+	RootCmd.AddCommand(migrationsCommand)
+	migrationsCommand.AddCommand(migrationsStatusCommand)
+	RootCmd.AddCommand(analyzeMigrationCommand)
 
 	listTableCommand.Flags().StringVar(&dbType, "db", "", "Database type (e.g., postgres)")
 	listTableCommand.Flags().StringVar(&dbURL, "url", "", "Database connection URL")
+	listTableCommand.Flags().StringVar(&outPath, "out", "", "Output file, directory or - for stdout (default tables.json)")
 
 	dumpSchemaCmd.Flags().StringVar(&dbType, "db", "", "Database type (e.g., postgres)")
 	dumpSchemaCmd.Flags().StringVar(&dbURL, "url", "", "Database connection URL")
 	dumpSchemaCmd.Flags().StringVar(&tableName, "table", "", "Table name to dump (optional)")
+	dumpSchemaCmd.Flags().StringVar(&dialect, "target-dialect", "postgres", "SQL dialect of the dump (postgres, mysql, sqlite, mssql)")
+	dumpSchemaCmd.Flags().StringVar(&outPath, "out", "", "Output file, directory or - for stdout (default schema.sql)")
+	dumpSchemaCmd.Flags().BoolVar(&split, "split", false, "Write one file per table or object into the --out directory")
+	dumpSchemaCmd.Flags().StringVar(&format, "format", "sql", "Output format (sql, json for a schema snapshot, jsonschema for a JSON Schema per table, openapi for OpenAPI 3.1 components, graphql for GraphQL SDL, proto for proto3 messages, or avro for an Avro schema per table)")
+	dumpSchemaCmd.Flags().StringVar(&packageName, "package", "", "Package of proto messages and namespace of Avro records (optional)")
 	transformCommand.Flags().StringVar(&dbType, "db", "", "Database type (e.g., postgres)")
 	transformCommand.Flags().StringVar(&dbURL, "url", "", "Database connection URL")
 	transformCommand.Flags().StringVar(&tableName, "table", "", "Table name to dump (optional)")
-	transformCommand.Flags().StringVar(&lang, "lang", "", "Language to transform to (e.g., python, typescript, java, rust, go)")
+	transformCommand.Flags().StringVar(&lang, "lang", "", "Language to transform to (py, ts, java, kt, cs, rs, go or prisma)")
+	transformCommand.Flags().StringVar(&outPath, "out", "", "Output file, directory or - for stdout (default the generator's file name, e.g. models.go, or orm_model.md with --ai)")
+	transformCommand.Flags().StringVar(&packageName, "package", "", "Package of the generated code (default models)")
+	transformCommand.Flags().StringVar(&frameworkName, "framework", "", "Framework of the offline generator: gorm or ent for go, django, sqlalchemy or pydantic for py, typeorm, drizzle, kysely or zod for ts, diesel, seaorm or sqlx for rs, jpa or spring-jdbc for java, exposed for kt, efcore for cs (default gorm, django, typeorm, diesel and jpa)")
+	transformCommand.Flags().BoolVar(&managed, "managed", false, "Let Django manage the generated models (sets Meta.managed = True)")
+	transformCommand.Flags().BoolVar(&useAI, "ai", false, "Generate the models with the AI service instead of the offline generator")
+
+	diffCommand.Flags().StringVar(&fromURL, "from", "", "Old schema: database URL or snapshot file")
+	diffCommand.Flags().StringVar(&toURL, "to", "", "New schema: database URL or snapshot file")
+	diffCommand.Flags().StringVar(&reportFormat, "format", "text", "Report format (text, json)")
+	diffCommand.Flags().StringVar(&outPath, "out", "", "Output file, directory or - for stdout (default stdout)")
+
+	migratePlanCommand.Flags().StringVar(&fromURL, "from", "", "Current schema: database URL or snapshot file")
+	migratePlanCommand.Flags().StringVar(&toURL, "to", "", "Target schema: database URL or snapshot file")
+	migratePlanCommand.Flags().StringVar(&outPath, "out", "", "Output directory, file or - for stdout (default up.sql and down.sql in the current directory)")
+	migratePlanCommand.Flags().StringVar(&migrationFmt, "migration-format", "sql", "Migration file format (sql, golang-migrate, goose, flyway, alembic, django)")
+	migratePlanCommand.Flags().StringVar(&migrationNm, "name", "schema_update", "Migration name used in file names")
+	migratePlanCommand.Flags().StringVar(&verifyURL, "verify-url", "", "Empty scratch database to verify the migration on (optional)")
+
+	checkCommand.Flags().StringVar(&snapshotPath, "snapshot", "", "Schema snapshot to compare with (see dump-schema --format json)")
+	checkCommand.Flags().StringVar(&dbURL, "url", "", "Database connection URL")
+	checkCommand.Flags().StringVar(&junitPath, "junit", "", "Write a JUnit XML report to this path (optional)")
+	checkCommand.Flags().StringVar(&jsonPath, "json", "", "Write a JSON report to this path (optional)")
+
+	verifyMigrationsCommand.Flags().StringVar(&migrationsDir, "dir", "", "Migrations directory (golang-migrate, goose, Flyway or plain .sql files)")
+	verifyMigrationsCommand.Flags().StringVar(&dbURL, "url", "", "Database connection URL of the live database")
+	verifyMigrationsCommand.Flags().StringVar(&scratchURL, "scratch-url", "", "Empty scratch database to replay into")
+	verifyMigrationsCommand.Flags().BoolVar(&createScratch, "create-scratch-on-source", false, "Replay into a temporary database created on the --url server and dropped afterwards")
+
+	migrationsStatusCommand.Flags().StringVar(&dbURL, "url", "", "Database connection URL")
+	migrationsStatusCommand.Flags().StringVar(&migrationsDir, "dir", "", "Local migrations directory to compare with (optional)")
+	migrationsStatusCommand.Flags().StringVar(&migrationTool, "tool", "", "Migration tool when several history tables exist (golang-migrate, rails, goose, flyway, alembic, django)")
+	migrationsStatusCommand.Flags().StringVar(&reportFormat, "format", "text", "Report format (text, json)")
+	migrationsStatusCommand.Flags().StringVar(&outPath, "out", "", "Output file, directory or - for stdout (default stdout)")
+
+	analyzeMigrationCommand.Flags().StringVar(&reportFormat, "format", "text", "Report format (text, json)")
+	analyzeMigrationCommand.Flags().StringVar(&outPath, "out", "", "Output file, directory or - for stdout (default stdout)")
 
 	dumpSchemaCmd.MarkFlagRequired("db")
 	dumpSchemaCmd.MarkFlagRequired("url")
@@ -143,5 +598,14 @@ func init() {
 	transformCommand.MarkFlagRequired("db")
 	transformCommand.MarkFlagRequired("url")
 	transformCommand.MarkFlagRequired("lang")
-	transformCommand.MarkFlagRequired("table")
+	diffCommand.MarkFlagRequired("from")
+	diffCommand.MarkFlagRequired("to")
+	migratePlanCommand.MarkFlagRequired("from")
+	migratePlanCommand.MarkFlagRequired("to")
+	checkCommand.MarkFlagRequired("snapshot")
+	checkCommand.MarkFlagRequired("url")
+	verifyMigrationsCommand.MarkFlagRequired("dir")
+	verifyMigrationsCommand.MarkFlagRequired("url")
+	verifyMigrationsCommand.MarkFlagsMutuallyExclusive("scratch-url", "create-scratch-on-source")
+	migrationsStatusCommand.MarkFlagRequired("url")
 }
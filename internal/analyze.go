@@ -0,0 +1,586 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// LockLevel is the strongest Postgres table lock a statement takes.
+type LockLevel string
+
+const (
+	NoLock                   LockLevel = "none"
+	RowExclusiveLock         LockLevel = "ROW EXCLUSIVE"
+	ShareUpdateExclusiveLock LockLevel = "SHARE UPDATE EXCLUSIVE"
+	ShareLock                LockLevel = "SHARE"
+	ShareRowExclusiveLock    LockLevel = "SHARE ROW EXCLUSIVE"
+	AccessExclusiveLock      LockLevel = "ACCESS EXCLUSIVE"
+)
+
+var lockStrength = map[LockLevel]int{
+	NoLock:                   0,
+	RowExclusiveLock:         1,
+	ShareUpdateExclusiveLock: 2,
+	ShareLock:                3,
+	ShareRowExclusiveLock:    4,
+	AccessExclusiveLock:      5,
+}
+
+// Risk grades how safe a statement is to run against a busy production
+// database.
+type Risk string
+
+const (
+	SafeRisk    Risk = "safe"
+	CautionRisk Risk = "caution"
+	DangerRisk  Risk = "danger"
+)
+
+var riskStrength = map[Risk]int{SafeRisk: 0, CautionRisk: 1, DangerRisk: 2}
+
+// SQLStatement is one statement of a SQL script and the line it starts on.
+type SQLStatement struct {
+	File string `json:"file,omitempty"`
+	Line int    `json:"line"`
+	SQL  string `json:"sql"`
+}
+
+// StatementAnalysis classifies a single migration statement.
+type StatementAnalysis struct {
+	SQLStatement
+	Lock LockLevel `json:"lock"`
+	// Rewrite is set when the statement rewrites the whole table, holding
+	// its lock for as long as that takes.
+	Rewrite bool `json:"rewrite"`
+	// Breaking is set when code deployed against the old schema stops
+	// working once the statement ran.
+	Breaking   bool     `json:"breaking"`
+	Risk       Risk     `json:"risk"`
+	Findings   []string `json:"findings"`
+	Suggestion string   `json:"suggestion,omitempty"`
+}
+
+// MigrationAnalysis is the result of analysing one or more migration scripts.
+type MigrationAnalysis struct {
+	Risk       Risk                `json:"risk"`
+	Statements []StatementAnalysis `json:"statements"`
+	Notes      []string            `json:"notes"`
+}
+
+// SplitStatements splits a SQL script into statements. Semicolons inside
+// quotes, dollar-quoted bodies and comments do not end a statement, and
+// comments are left out of the statement text.
+func SplitStatements(script string) []SQLStatement {
+	var statements []SQLStatement
+	var b strings.Builder
+	line, start := 1, 0
+	flush := func() {
+		if text := strings.TrimSpace(b.String()); text != "" {
+			statements = append(statements, SQLStatement{Line: start, SQL: text})
+		}
+		b.Reset()
+		start = 0
+	}
+	write := func(s string) {
+		if start == 0 && strings.TrimSpace(s) != "" {
+			start = line
+		}
+		b.WriteString(s)
+		line += strings.Count(s, "\n")
+	}
+
+	for i := 0; i < len(script); {
+		rest := script[i:]
+		switch {
+		case strings.HasPrefix(rest, "--"):
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				end = len(rest)
+			}
+			i += end
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest[2:], "*/")
+			if end < 0 {
+				end = len(rest)
+			} else {
+				end += 4
+			}
+			line += strings.Count(rest[:end], "\n")
+			b.WriteByte(' ')
+			i += end
+		case rest[0] == '\'' || rest[0] == '"':
+			end := 1
+			for end < len(rest) {
+				if rest[end] == rest[0] {
+					if end+1 < len(rest) && rest[end+1] == rest[0] {
+						end += 2
+						continue
+					}
+					end++
+					break
+				}
+				end++
+			}
+			write(rest[:end])
+			i += end
+		case rest[0] == '$':
+			tag := dollarQuote.FindString(rest)
+			if tag == "" {
+				write("$")
+				i++
+				continue
+			}
+			end := strings.Index(rest[len(tag):], tag)
+			if end < 0 {
+				end = len(rest)
+			} else {
+				end += 2 * len(tag)
+			}
+			write(rest[:end])
+			i += end
+		case rest[0] == ';':
+			flush()
+			i++
+		default:
+			write(rest[:1])
+			i++
+		}
+	}
+	flush()
+	return statements
+}
+
+var dollarQuote = regexp.MustCompile(`^\$[A-Za-z_]*\$`)
+
+// AnalyzeMigration classifies every statement of the given migration
+// scripts by lock level, rewrite risk and backward compatibility. Only the
+// up section of goose files is analysed.
+func AnalyzeMigration(scripts []File) *MigrationAnalysis {
+	analysis := &MigrationAnalysis{Risk: SafeRisk, Statements: []StatementAnalysis{}, Notes: []string{}}
+	lockTimeout := false
+	blocking := false
+	for _, script := range scripts {
+		body := string(script.Content)
+		if down := strings.Index(body, "-- +goose Down"); down >= 0 {
+			body = body[:down]
+		}
+		inTransaction := false
+		for _, stmt := range SplitStatements(body) {
+			stmt.File = script.Name
+			text := strings.Join(strings.Fields(stmt.SQL), " ")
+			switch {
+			case transactionStart.MatchString(text):
+				inTransaction = true
+				continue
+			case transactionEnd.MatchString(text):
+				inTransaction = false
+				continue
+			case lockTimeoutSetting.MatchString(text):
+				lockTimeout = true
+				continue
+			case sessionSetting.MatchString(text):
+				continue
+			}
+
+			a := analyzeStatement(text)
+			a.SQLStatement = stmt
+			if inTransaction && concurrently.MatchString(text) {
+				a.Findings = append(a.Findings, "CONCURRENTLY cannot run inside a transaction block")
+				a.Risk = DangerRisk
+			}
+			if inTransaction && enumAddValue.MatchString(text) {
+				a.Findings = append(a.Findings, "runs inside a transaction block: fails before PostgreSQL 12, and later statements of the transaction cannot use the new value")
+				if riskStrength[a.Risk] < riskStrength[CautionRisk] {
+					a.Risk = CautionRisk
+				}
+				a.Suggestion = text + "; -- before BEGIN, in a transaction of its own"
+			}
+			if lockStrength[a.Lock] >= lockStrength[ShareLock] {
+				blocking = true
+			}
+			if riskStrength[a.Risk] > riskStrength[analysis.Risk] {
+				analysis.Risk = a.Risk
+			}
+			analysis.Statements = append(analysis.Statements, a)
+		}
+	}
+	if blocking && !lockTimeout {
+		analysis.Notes = append(analysis.Notes, "Set lock_timeout (e.g. SET lock_timeout = '5s') so a statement waiting for its lock does not queue all other queries behind it")
+	}
+	return analysis
+}
+
+var (
+	transactionStart   = regexp.MustCompile(`(?i)^(BEGIN|START TRANSACTION)\b`)
+	transactionEnd     = regexp.MustCompile(`(?i)^(COMMIT|END|ROLLBACK)\b`)
+	lockTimeoutSetting = regexp.MustCompile(`(?i)^SET\s+(LOCAL\s+)?lock_timeout\b`)
+	sessionSetting     = regexp.MustCompile(`(?i)^(SET|RESET)\b`)
+	concurrently       = regexp.MustCompile(`(?i)\bCONCURRENTLY\b`)
+
+	identPattern        = `("(?:[^"]|"")+"|[^\s(),;]+)`
+	createIndex         = regexp.MustCompile(`(?i)^CREATE\s+(UNIQUE\s+)?INDEX\s+(CONCURRENTLY\s+)?`)
+	dropIndex           = regexp.MustCompile(`(?i)^DROP\s+INDEX\s+(CONCURRENTLY\s+)?`)
+	reindex             = regexp.MustCompile(`(?i)^REINDEX\s+(\(.*?\)\s+)?(INDEX|TABLE|SCHEMA|DATABASE|SYSTEM)\s+(CONCURRENTLY\s+)?`)
+	createTable         = regexp.MustCompile(`(?i)^CREATE\s+(UNLOGGED\s+|TEMP\s+|TEMPORARY\s+)?TABLE\b`)
+	dropObject          = regexp.MustCompile(`(?i)^DROP\s+(TABLE|VIEW|MATERIALIZED\s+VIEW|FUNCTION|PROCEDURE|TYPE|SEQUENCE)\b`)
+	alterTableStatement = regexp.MustCompile(`(?i)^ALTER\s+TABLE\s+(?:IF\s+EXISTS\s+)?(?:ONLY\s+)?` + identPattern + `\s+(.*)$`)
+	alterType           = regexp.MustCompile(`(?i)^ALTER\s+TYPE\s+\S+\s+(ADD\s+VALUE|RENAME\s+VALUE|RENAME\s+TO|OWNER)\b`)
+	createObject        = regexp.MustCompile(`(?i)^(CREATE|COMMENT|GRANT|REVOKE)\b`)
+	refreshView         = regexp.MustCompile(`(?i)^REFRESH\s+MATERIALIZED\s+VIEW\s+(CONCURRENTLY\s+)?`)
+	dataChange          = regexp.MustCompile(`(?i)^(UPDATE|DELETE)\b`)
+	whereClause         = regexp.MustCompile(`(?i)\bWHERE\b`)
+	insertRows          = regexp.MustCompile(`(?i)^(INSERT|COPY)\b`)
+	truncateTable       = regexp.MustCompile(`(?i)^TRUNCATE\b`)
+	rewriteCommand      = regexp.MustCompile(`(?i)^(VACUUM\s+FULL|CLUSTER)\b`)
+	lockTable           = regexp.MustCompile(`(?i)^LOCK\b`)
+)
+
+func analyzeStatement(text string) StatementAnalysis {
+	a := StatementAnalysis{Lock: NoLock, Risk: SafeRisk, Findings: []string{}}
+	switch {
+	case createIndex.MatchString(text):
+		if createIndex.FindStringSubmatch(text)[2] != "" {
+			a.Lock = ShareUpdateExclusiveLock
+			a.Findings = append(a.Findings, "builds the index without blocking writes")
+			break
+		}
+		a.Lock = ShareLock
+		a.Risk = CautionRisk
+		a.Findings = append(a.Findings, "blocks writes to the table while the index is built")
+		a.Suggestion = createIndex.ReplaceAllStringFunc(text, func(s string) string {
+			return strings.TrimSpace(s) + " CONCURRENTLY "
+		}) + "; -- outside a transaction"
+	case dropIndex.MatchString(text):
+		if dropIndex.FindStringSubmatch(text)[1] != "" {
+			a.Lock = ShareUpdateExclusiveLock
+			break
+		}
+		a.Lock = AccessExclusiveLock
+		a.Risk = CautionRisk
+		a.Findings = append(a.Findings, "blocks reads and writes of the table until the index is dropped")
+		a.Suggestion = dropIndex.ReplaceAllString(text, "DROP INDEX CONCURRENTLY ") + "; -- outside a transaction"
+	case reindex.MatchString(text):
+		if reindex.FindStringSubmatch(text)[3] != "" {
+			a.Lock = ShareUpdateExclusiveLock
+			break
+		}
+		a.Lock = AccessExclusiveLock
+		a.Risk = DangerRisk
+		a.Findings = append(a.Findings, "blocks the table while its indexes are rebuilt")
+		a.Suggestion = "REINDEX ... CONCURRENTLY (PostgreSQL 12 and later)"
+	case createTable.MatchString(text):
+		if strings.Contains(strings.ToUpper(text), "REFERENCES") {
+			a.Lock = ShareRowExclusiveLock
+			a.Findings = append(a.Findings, "briefly blocks writes to the referenced tables")
+		}
+	case dropObject.MatchString(text):
+		kind := strings.ToLower(dropObject.FindStringSubmatch(text)[1])
+		a.Lock = AccessExclusiveLock
+		a.Breaking = true
+		a.Risk = DangerRisk
+		a.Findings = append(a.Findings, fmt.Sprintf("code still using the %s fails once it is dropped", kind))
+		a.Suggestion = fmt.Sprintf("Remove every use of the %s from the application and deploy first, then drop it in a later migration", kind)
+	case alterTableStatement.MatchString(text):
+		m := alterTableStatement.FindStringSubmatch(text)
+		analyzeAlterTable(&a, m[1], m[2])
+	case alterType.MatchString(text):
+		switch action := strings.ToUpper(strings.Join(strings.Fields(alterType.FindStringSubmatch(text)[1]), " ")); action {
+		case "ADD VALUE":
+			a.Findings = append(a.Findings, "cannot run inside a transaction block before PostgreSQL 12; from 12 on it can, but the new value cannot be used until the transaction commits")
+		case "OWNER":
+		default:
+			a.Lock = AccessExclusiveLock
+			a.Breaking = true
+			a.Risk = DangerRisk
+			a.Findings = append(a.Findings, "code using the old name fails after the rename")
+		}
+	case refreshView.MatchString(text):
+		if refreshView.FindStringSubmatch(text)[1] != "" {
+			a.Lock = ShareUpdateExclusiveLock
+			break
+		}
+		a.Lock = AccessExclusiveLock
+		a.Risk = CautionRisk
+		a.Findings = append(a.Findings, "blocks reads of the view until it is refreshed")
+		a.Suggestion = refreshView.ReplaceAllString(text, "REFRESH MATERIALIZED VIEW CONCURRENTLY ") + "; -- needs a unique index on the view"
+	case dataChange.MatchString(text):
+		a.Lock = RowExclusiveLock
+		a.Risk = CautionRisk
+		if whereClause.MatchString(text) {
+			a.Findings = append(a.Findings, "locks every matched row until the transaction commits")
+		} else {
+			a.Findings = append(a.Findings, "touches every row of the table in one transaction")
+		}
+		a.Suggestion = "Backfill in batches of a few thousand rows, each in its own transaction"
+	case insertRows.MatchString(text):
+		a.Lock = RowExclusiveLock
+	case truncateTable.MatchString(text):
+		a.Lock = AccessExclusiveLock
+		a.Breaking = true
+		a.Risk = DangerRisk
+		a.Findings = append(a.Findings, "deletes all rows of the table")
+	case rewriteCommand.MatchString(text):
+		a.Lock = AccessExclusiveLock
+		a.Rewrite = true
+		a.Risk = DangerRisk
+		a.Findings = append(a.Findings, "rewrites the table while blocking reads and writes")
+	case lockTable.MatchString(text):
+		a.Lock = AccessExclusiveLock
+		a.Risk = CautionRisk
+		a.Findings = append(a.Findings, "takes an explicit table lock, ACCESS EXCLUSIVE unless a mode is given")
+	case createObject.MatchString(text):
+	default:
+		a.Risk = CautionRisk
+		a.Findings = append(a.Findings, "statement not recognised, review it by hand")
+	}
+	return a
+}
+
+var (
+	addColumnAction   = regexp.MustCompile(`(?i)^ADD\s+(?:COLUMN\s+)?(?:IF\s+NOT\s+EXISTS\s+)?` + identPattern + `\s+(.*)$`)
+	addConstraint     = regexp.MustCompile(`(?i)^ADD\s+(?:CONSTRAINT\s+` + identPattern + `\s+)?(PRIMARY\s+KEY|UNIQUE|FOREIGN\s+KEY|CHECK|EXCLUDE)\b(.*)$`)
+	alterColumnAction = regexp.MustCompile(`(?i)^ALTER\s+(?:COLUMN\s+)?` + identPattern + `\s+(.*)$`)
+	dropColumnAction  = regexp.MustCompile(`(?i)^DROP\s+(?:COLUMN\s+)?(?:IF\s+EXISTS\s+)?` + identPattern)
+	dropConstraint    = regexp.MustCompile(`(?i)^DROP\s+CONSTRAINT\b`)
+	renameColumn      = regexp.MustCompile(`(?i)^RENAME\s+(?:COLUMN\s+)?` + identPattern + `\s+TO\s+` + identPattern)
+	renameTable       = regexp.MustCompile(`(?i)^RENAME\s+TO\b`)
+	renameConstraint  = regexp.MustCompile(`(?i)^RENAME\s+CONSTRAINT\b`)
+	validate          = regexp.MustCompile(`(?i)^VALIDATE\s+CONSTRAINT\b`)
+	tableRewrite      = regexp.MustCompile(`(?i)^SET\s+(LOGGED|UNLOGGED|TABLESPACE|ACCESS\s+METHOD)\b`)
+	notNull           = regexp.MustCompile(`(?i)\bNOT\s+NULL\b`)
+	defaultClause     = regexp.MustCompile(`(?i)\bDEFAULT\b`)
+	volatileDefault   = regexp.MustCompile(`(?i)\bDEFAULT\s+.*\b(random|gen_random_uuid|uuid_generate_v[14]|clock_timestamp|timeofday|nextval)\s*\(`)
+	splitDefault      = regexp.MustCompile(`(?i)^(.*?)\s+DEFAULT\s+(.*?)(\s+NOT\s+NULL)?$`)
+	serialType        = regexp.MustCompile(`(?i)^(small|big)?serial\d?\b`)
+	storedGenerated   = regexp.MustCompile(`(?i)\bGENERATED\s+ALWAYS\s+AS\s*\(.*\)\s*STORED\b`)
+	inlineIndex       = regexp.MustCompile(`(?i)\b(PRIMARY\s+KEY|UNIQUE)\b`)
+	notValid          = regexp.MustCompile(`(?i)\bNOT\s+VALID\b`)
+	usingIndex        = regexp.MustCompile(`(?i)\bUSING\s+INDEX\b`)
+	setType           = regexp.MustCompile(`(?i)^(?:SET\s+DATA\s+)?TYPE\s+(.*?)(\s+USING\s+.*)?$`)
+	textType          = regexp.MustCompile(`(?i)^(text|varchar|character\s+varying)(\s*\(\s*\d+\s*\))?(\s+COLLATE\s+.*)?$`)
+	setNotNull        = regexp.MustCompile(`(?i)^SET\s+NOT\s+NULL$`)
+	lightColumnAlter  = regexp.MustCompile(`(?i)^(DROP\s+NOT\s+NULL|SET\s+DEFAULT|DROP\s+DEFAULT|SET\s+STATISTICS|SET\s+STORAGE|SET\s*\(|RESET\s*\(|ADD\s+GENERATED|SET\s+GENERATED|DROP\s+IDENTITY|DROP\s+EXPRESSION)`)
+)
+
+// analyzeAlterTable classifies each action of an ALTER TABLE statement; the
+// statement takes the strongest lock of all its actions.
+func analyzeAlterTable(a *StatementAnalysis, table, actions string) {
+	raise := func(lock LockLevel, risk Risk) {
+		if lockStrength[lock] > lockStrength[a.Lock] {
+			a.Lock = lock
+		}
+		if riskStrength[risk] > riskStrength[a.Risk] {
+			a.Risk = risk
+		}
+	}
+	var suggestions []string
+	suggest := func(lines ...string) {
+		suggestions = append(suggestions, strings.Join(lines, "\n"))
+	}
+
+	for _, action := range splitTopLevel(actions) {
+		switch {
+		case addConstraint.MatchString(action):
+			m := addConstraint.FindStringSubmatch(action)
+			name, kind := m[1], strings.ToUpper(strings.Join(strings.Fields(m[2]), " "))
+			switch {
+			case notValid.MatchString(action):
+				raise(ShareRowExclusiveLock, SafeRisk)
+				if kind == "CHECK" {
+					raise(AccessExclusiveLock, SafeRisk)
+				}
+				a.Findings = append(a.Findings, fmt.Sprintf("%s %s skips checking existing rows; validate it separately", kind, name))
+			case usingIndex.MatchString(action):
+				raise(AccessExclusiveLock, SafeRisk)
+				a.Findings = append(a.Findings, fmt.Sprintf("%s %s reuses an existing index", kind, name))
+			case kind == "FOREIGN KEY" || kind == "CHECK":
+				lock := ShareRowExclusiveLock
+				if kind == "CHECK" {
+					lock = AccessExclusiveLock
+				}
+				raise(lock, DangerRisk)
+				a.Findings = append(a.Findings, fmt.Sprintf("%s %s scans the whole table under a %s lock", kind, name, lock))
+				if name != "" {
+					suggest(fmt.Sprintf("ALTER TABLE %s %s NOT VALID;", table, action),
+						fmt.Sprintf("ALTER TABLE %s VALIDATE CONSTRAINT %s;", table, name))
+				} else {
+					suggest("Name the constraint, add it NOT VALID, then VALIDATE CONSTRAINT in a separate transaction")
+				}
+			default:
+				raise(AccessExclusiveLock, DangerRisk)
+				a.Findings = append(a.Findings, fmt.Sprintf("%s %s builds its index while blocking reads and writes", kind, name))
+				if name != "" && kind != "EXCLUDE" {
+					suggest(fmt.Sprintf("CREATE UNIQUE INDEX CONCURRENTLY %s ON %s %s; -- outside a transaction", name, table, strings.TrimSpace(m[3])),
+						fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s %s USING INDEX %s;", table, name, kind, name))
+				}
+			}
+		case addColumnAction.MatchString(action) && !strings.HasPrefix(strings.ToUpper(action), "ADD CONSTRAINT"):
+			m := addColumnAction.FindStringSubmatch(action)
+			column, definition := m[1], m[2]
+			raise(AccessExclusiveLock, SafeRisk)
+			switch {
+			case serialType.MatchString(definition) || volatileDefault.MatchString(definition) || storedGenerated.MatchString(definition):
+				a.Rewrite = true
+				raise(AccessExclusiveLock, DangerRisk)
+				a.Findings = append(a.Findings, fmt.Sprintf("column %s is filled row by row, rewriting the table", column))
+				if d := splitDefault.FindStringSubmatch(definition); d != nil {
+					suggest(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s;", table, column, d[1]),
+						fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET DEFAULT %s;", table, column, d[2]),
+						"-- backfill existing rows in batches")
+				} else {
+					suggest("Add the column without the default, set the default, then backfill existing rows in batches")
+				}
+			case notNull.MatchString(definition) && !defaultClause.MatchString(definition):
+				a.Breaking = true
+				raise(AccessExclusiveLock, DangerRisk)
+				a.Findings = append(a.Findings, fmt.Sprintf("column %s is NOT NULL without a default: fails on a non-empty table and breaks inserts of deployed code", column))
+				suggest(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s;", table, column, strings.TrimSpace(notNull.ReplaceAllString(definition, ""))),
+					"-- backfill in batches, deploy code writing the column, then set NOT NULL through a validated CHECK")
+			default:
+				a.Findings = append(a.Findings, fmt.Sprintf("column %s is added without a rewrite (defaults are metadata-only since PostgreSQL 11)", column))
+			}
+			if inlineIndex.MatchString(definition) {
+				raise(AccessExclusiveLock, CautionRisk)
+				a.Findings = append(a.Findings, fmt.Sprintf("column %s builds an index while blocking the table", column))
+			}
+		case dropColumnAction.MatchString(action) && !dropConstraint.MatchString(action):
+			column := dropColumnAction.FindStringSubmatch(action)[1]
+			a.Breaking = true
+			raise(AccessExclusiveLock, DangerRisk)
+			a.Findings = append(a.Findings, fmt.Sprintf("deployed code reading or writing column %s fails once it is dropped", column))
+			suggest(fmt.Sprintf("Stop reading and writing %s.%s in the application and deploy first, then drop the column", table, column))
+		case dropConstraint.MatchString(action), validate.MatchString(action), renameConstraint.MatchString(action):
+			lock := AccessExclusiveLock
+			if validate.MatchString(action) {
+				lock = ShareUpdateExclusiveLock
+				a.Findings = append(a.Findings, "validates existing rows without blocking writes")
+			}
+			raise(lock, SafeRisk)
+		case renameTable.MatchString(action):
+			a.Breaking = true
+			raise(AccessExclusiveLock, DangerRisk)
+			a.Findings = append(a.Findings, fmt.Sprintf("code using table %s fails after the rename", table))
+			suggest("Rename the table and create a view with the old name until the application is deployed")
+		case renameColumn.MatchString(action):
+			m := renameColumn.FindStringSubmatch(action)
+			a.Breaking = true
+			raise(AccessExclusiveLock, DangerRisk)
+			a.Findings = append(a.Findings, fmt.Sprintf("code using column %s fails after the rename", m[1]))
+			suggest(fmt.Sprintf("Add column %s, write to both columns, backfill in batches, switch reads to %s, then drop %s", m[2], m[2], m[1]))
+		case tableRewrite.MatchString(action):
+			a.Rewrite = true
+			raise(AccessExclusiveLock, DangerRisk)
+			a.Findings = append(a.Findings, "rewrites the table while blocking reads and writes")
+		case alterColumnAction.MatchString(action):
+			m := alterColumnAction.FindStringSubmatch(action)
+			column, change := m[1], m[2]
+			switch {
+			case setType.MatchString(change):
+				t := setType.FindStringSubmatch(change)
+				if textType.MatchString(t[1]) && t[2] == "" {
+					raise(AccessExclusiveLock, CautionRisk)
+					a.Findings = append(a.Findings, fmt.Sprintf("column %s avoids a rewrite only when the old type is binary compatible (e.g. varchar to text or a longer varchar)", column))
+					break
+				}
+				a.Rewrite = true
+				raise(AccessExclusiveLock, DangerRisk)
+				a.Findings = append(a.Findings, fmt.Sprintf("column %s changes type, rewriting the table and its indexes", column))
+				suggest(fmt.Sprintf("Add a new column of type %s, write to both, backfill in batches, switch the application, then drop %s", t[1], column))
+			case setNotNull.MatchString(change):
+				a.Breaking = true
+				raise(AccessExclusiveLock, DangerRisk)
+				a.Findings = append(a.Findings, fmt.Sprintf("column %s is checked for NULLs in a full scan, and inserts of deployed code omitting it fail", column))
+				check := quoteIdent(strings.Trim(table, `"`) + "_" + strings.Trim(column, `"`) + "_not_null")
+				suggest(fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s CHECK (%s IS NOT NULL) NOT VALID;", table, check, column),
+					fmt.Sprintf("ALTER TABLE %s VALIDATE CONSTRAINT %s;", table, check),
+					fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET NOT NULL; -- uses the validated check, PostgreSQL 12 and later", table, column),
+					fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s;", table, check))
+			case lightColumnAlter.MatchString(change):
+				raise(AccessExclusiveLock, SafeRisk)
+			default:
+				raise(AccessExclusiveLock, CautionRisk)
+				a.Findings = append(a.Findings, fmt.Sprintf("column %s change not recognised, review it by hand", column))
+			}
+		default:
+			raise(AccessExclusiveLock, CautionRisk)
+			a.Findings = append(a.Findings, "ALTER TABLE action not recognised, review it by hand")
+		}
+	}
+	a.Suggestion = strings.Join(suggestions, "\n")
+}
+
+// splitTopLevel splits the actions of an ALTER TABLE statement on commas
+// outside parentheses and quotes.
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			parts = append(parts, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	return append(parts, strings.TrimSpace(s[start:]))
+}
+
+// Text returns the analysis as a human readable report.
+func (m *MigrationAnalysis) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Migration safety: %s\n", m.Risk)
+	for _, s := range m.Statements {
+		location := fmt.Sprintf("line %d", s.Line)
+		if s.File != "" {
+			location = fmt.Sprintf("%s:%d", s.File, s.Line)
+		}
+		summary, _, cut := strings.Cut(s.SQL, "\n")
+		if cut || len(summary) > 80 {
+			summary = strings.TrimSpace(summary[:min(len(summary), 77)]) + "..."
+		}
+		fmt.Fprintf(&b, "\n[%s] %s: %s\n", s.Risk, location, summary)
+		fmt.Fprintf(&b, "    lock: %s, rewrite: %s, breaking: %s\n", s.Lock, yesNo(s.Rewrite), yesNo(s.Breaking))
+		for _, finding := range s.Findings {
+			fmt.Fprintf(&b, "    - %s\n", finding)
+		}
+		if s.Suggestion != "" {
+			b.WriteString("    safer:\n")
+			for _, line := range strings.Split(s.Suggestion, "\n") {
+				fmt.Fprintf(&b, "      %s\n", line)
+			}
+		}
+	}
+	for _, note := range m.Notes {
+		fmt.Fprintf(&b, "\nNote: %s\n", note)
+	}
+	return b.String()
+}
+
+// JSON returns the analysis as indented JSON.
+func (m *MigrationAnalysis) JSON() ([]byte, error) {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode migration analysis: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,138 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeMigrationAddValue(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		risk   Risk
+		inTx   bool
+	}{
+		{"outside a transaction", "ALTER TYPE mood ADD VALUE 'ok';\n", SafeRisk, false},
+		{"inside a transaction", "BEGIN;\nALTER TYPE mood ADD VALUE 'ok' AFTER 'sad';\nCOMMIT;\n", CautionRisk, true},
+		{"after the transaction", "BEGIN;\nCOMMIT;\nALTER TYPE mood ADD VALUE 'ok';\n", SafeRisk, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analysis := AnalyzeMigration([]File{{Name: "up.sql", Content: []byte(tt.script)}})
+			if len(analysis.Statements) != 1 {
+				t.Fatalf("got %d statements, want 1", len(analysis.Statements))
+			}
+			a := analysis.Statements[0]
+			if a.Risk != tt.risk {
+				t.Errorf("risk = %s, want %s", a.Risk, tt.risk)
+			}
+			findings := strings.Join(a.Findings, "\n")
+			if !strings.Contains(findings, "cannot run inside a transaction block before PostgreSQL 12") {
+				t.Errorf("findings %q don't explain ADD VALUE", a.Findings)
+			}
+			if got := strings.Contains(findings, "runs inside a transaction block"); got != tt.inTx {
+				t.Errorf("transaction block finding = %v, want %v: %q", got, tt.inTx, a.Findings)
+			}
+		})
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := `-- add the slug; with a comment
+CREATE FUNCTION slugify(text) RETURNS text AS $body$ SELECT lower($1); $body$ LANGUAGE sql;
+/* block; comment */ UPDATE posts SET title = 'a;b';
+
+INSERT INTO "odd;name" VALUES (1)`
+	want := []SQLStatement{
+		{Line: 2, SQL: "CREATE FUNCTION slugify(text) RETURNS text AS $body$ SELECT lower($1); $body$ LANGUAGE sql"},
+		{Line: 3, SQL: "UPDATE posts SET title = 'a;b'"},
+		{Line: 5, SQL: `INSERT INTO "odd;name" VALUES (1)`},
+	}
+	got := SplitStatements(script)
+	if len(got) != len(want) {
+		t.Fatalf("got %d statements, want %d: %q", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("statement %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestAnalyzeStatement(t *testing.T) {
+	tests := []struct {
+		sql      string
+		lock     LockLevel
+		risk     Risk
+		rewrite  bool
+		breaking bool
+	}{
+		{"CREATE INDEX posts_title_idx ON posts (title)", ShareLock, CautionRisk, false, false},
+		{"CREATE INDEX CONCURRENTLY posts_title_idx ON posts (title)", ShareUpdateExclusiveLock, SafeRisk, false, false},
+		{"CREATE TABLE comments (id bigint, post_id bigint REFERENCES posts(id))", ShareRowExclusiveLock, SafeRisk, false, false},
+		{"DROP TABLE comments", AccessExclusiveLock, DangerRisk, false, true},
+		{"ALTER TABLE posts ADD COLUMN note text", AccessExclusiveLock, SafeRisk, false, false},
+		{"ALTER TABLE posts ADD COLUMN token uuid DEFAULT gen_random_uuid()", AccessExclusiveLock, DangerRisk, true, false},
+		{"ALTER TABLE posts ADD COLUMN note text NOT NULL", AccessExclusiveLock, DangerRisk, false, true},
+		{"ALTER TABLE posts DROP COLUMN rating", AccessExclusiveLock, DangerRisk, false, true},
+		{"ALTER TABLE posts ALTER COLUMN rating TYPE bigint USING rating::bigint", AccessExclusiveLock, DangerRisk, true, false},
+		{"ALTER TABLE posts ALTER COLUMN title TYPE text", AccessExclusiveLock, CautionRisk, false, false},
+		{"ALTER TABLE posts ALTER COLUMN title SET NOT NULL", AccessExclusiveLock, DangerRisk, false, true},
+		{"ALTER TABLE posts ALTER COLUMN title DROP NOT NULL", AccessExclusiveLock, SafeRisk, false, false},
+		{"ALTER TABLE posts ADD CONSTRAINT posts_author_fkey FOREIGN KEY (author_id) REFERENCES users(id)", ShareRowExclusiveLock, DangerRisk, false, false},
+		{"ALTER TABLE posts ADD CONSTRAINT posts_author_fkey FOREIGN KEY (author_id) REFERENCES users(id) NOT VALID", ShareRowExclusiveLock, SafeRisk, false, false},
+		{"ALTER TABLE posts VALIDATE CONSTRAINT posts_author_fkey", ShareUpdateExclusiveLock, SafeRisk, false, false},
+		{"ALTER TABLE posts RENAME COLUMN title TO headline", AccessExclusiveLock, DangerRisk, false, true},
+		{"UPDATE posts SET rating = 0", RowExclusiveLock, CautionRisk, false, false},
+		{"VACUUM FULL posts", AccessExclusiveLock, DangerRisk, true, false},
+		{"SELECT 1", NoLock, CautionRisk, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			a := analyzeStatement(tt.sql)
+			if a.Lock != tt.lock || a.Risk != tt.risk || a.Rewrite != tt.rewrite || a.Breaking != tt.breaking {
+				t.Errorf("got lock %s, risk %s, rewrite %v, breaking %v; want %s, %s, %v, %v",
+					a.Lock, a.Risk, a.Rewrite, a.Breaking, tt.lock, tt.risk, tt.rewrite, tt.breaking)
+			}
+		})
+	}
+}
+
+func TestAnalyzeMigrationReport(t *testing.T) {
+	script := `BEGIN;
+CREATE INDEX posts_title_idx ON posts (title);
+ALTER TABLE posts ALTER COLUMN title SET NOT NULL, ADD COLUMN note text;
+ALTER TABLE posts ADD CONSTRAINT posts_rating_check CHECK (rating > 0);
+COMMIT;
+`
+	analysis := AnalyzeMigration([]File{{Name: "up.sql", Content: []byte(script)}})
+	if analysis.Risk != DangerRisk {
+		t.Errorf("risk = %s, want %s", analysis.Risk, DangerRisk)
+	}
+	golden(t, "analyze/report.txt", []byte(analysis.Text()))
+	data, err := analysis.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden(t, "analyze/report.json", data)
+}
+
+func TestAnalyzeMigrationGoose(t *testing.T) {
+	plan := PlanMigration(blogSchema(), blogSchemaWith(addEnumValues("post_status", "archived"), dropColumn("users", "bio")))
+	files, err := MigrationFiles("goose", t.TempDir(), "archive", plan, migrationTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	analysis := AnalyzeMigration(files)
+	var statements []string
+	for _, s := range analysis.Statements {
+		statements = append(statements, s.SQL)
+		if strings.HasPrefix(s.SQL, "ALTER TYPE") && s.Risk != SafeRisk {
+			t.Errorf("enum value added outside a transaction is %s: %q", s.Risk, s.Findings)
+		}
+	}
+	want := []string{"ALTER TYPE post_status ADD VALUE 'archived' AFTER 'published'", "ALTER TABLE users DROP COLUMN bio"}
+	if strings.Join(statements, "\n") != strings.Join(want, "\n") {
+		t.Errorf("statements = %q, want the up section %q", statements, want)
+	}
+}
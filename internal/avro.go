@@ -0,0 +1,167 @@
+package internal
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+)
+
+// avroRecord is an Avro record schema of a table's rows.
+type avroRecord struct {
+	Type      string      `json:"type"`
+	Name      string      `json:"name"`
+	Namespace string      `json:"namespace,omitempty"`
+	Doc       string      `json:"doc,omitempty"`
+	Fields    []avroField `json:"fields"`
+}
+
+type avroField struct {
+	Name string `json:"name"`
+	Doc  string `json:"doc,omitempty"`
+	Type any    `json:"type"`
+	// Default is raw JSON so a null default is written too.
+	Default json.RawMessage `json:"default,omitempty"`
+}
+
+// avroType is an Avro complex type, or a primitive one with a logical type.
+type avroType struct {
+	Type        string   `json:"type"`
+	LogicalType string   `json:"logicalType,omitempty"`
+	Precision   int64    `json:"precision,omitempty"`
+	Scale       int64    `json:"scale,omitempty"`
+	Name        string   `json:"name,omitempty"`
+	Symbols     []string `json:"symbols,omitempty"`
+	Items       any      `json:"items,omitempty"`
+}
+
+// avroTypes maps udt names to Avro primitive types, or to logical types on
+// top of them.
+var avroTypes = map[string]any{
+	"int2": "int", "int4": "int", "int8": "long", "float4": "float", "float8": "double",
+	"bool": "boolean", "bytea": "bytes",
+	"uuid":        avroType{Type: "string", LogicalType: "uuid"},
+	"date":        avroType{Type: "int", LogicalType: "date"},
+	"time":        avroType{Type: "long", LogicalType: "time-micros"},
+	"timestamp":   avroType{Type: "long", LogicalType: "local-timestamp-micros"},
+	"timestamptz": avroType{Type: "long", LogicalType: "timestamp-micros"},
+}
+
+var avroName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+type avroWriter struct {
+	schema *Schema
+	// defined are the enums already defined in the current file; later
+	// uses refer to them by name.
+	defined map[string]bool
+}
+
+// AvroSchemaFiles renders an Avro record schema of each table's rows, one
+// <table>.avsc file per table, for the topics of CDC and event pipelines.
+// Nullable columns are unions with null defaulting to null; numeric is a
+// decimal of its precision and scale, and uuid, date, time and timestamps
+// use their logical types. Enum types whose values are all valid symbols
+// become Avro enums, the others strings.
+func AvroSchemaFiles(schema *Schema, namespace string) ([]File, error) {
+	var files []File
+	for i := range schema.Tables {
+		t := &schema.Tables[i]
+		w := &avroWriter{schema: schema, defined: make(map[string]bool)}
+		record := avroRecord{Type: "record", Name: className(t.Name), Namespace: namespace, Doc: oneLine(t.Comment)}
+		used := make(map[string]bool)
+		for _, col := range t.Columns {
+			name := col.ColumnName
+			if !avroName.MatchString(name) {
+				name = snakeCase(name)
+				if name == "" || name[0] >= '0' && name[0] <= '9' {
+					name = "f_" + name
+				}
+			}
+			for used[name] {
+				name += "_"
+			}
+			used[name] = true
+			field := avroField{Name: name, Doc: oneLine(col.Comment), Type: w.fieldType(col)}
+			if col.Nullable() {
+				field.Type = []any{"null", field.Type}
+				field.Default = json.RawMessage("null")
+			} else {
+				field.Default = w.defaultValue(col, field.Type)
+			}
+			record.Fields = append(record.Fields, field)
+		}
+		data, err := marshalJSONSchema(record)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, File{Name: t.Name + ".avsc", Content: data})
+	}
+	return files, nil
+}
+
+// fieldType returns the Avro type of a column's values.
+func (w *avroWriter) fieldType(col Column) any {
+	if col.IsArray() {
+		return avroType{Type: "array", Items: w.fieldType(elementColumn(col))}
+	}
+	if e := w.schema.Enum(col.UDTName); e != nil {
+		return w.enumType(e)
+	}
+	if col.UDTName == "numeric" {
+		precision, scale, ok := columnModifiers(col)
+		if !ok {
+			// Decimals need a precision; unconstrained numerics are kept
+			// as their text.
+			return "string"
+		}
+		return avroType{Type: "bytes", LogicalType: "decimal", Precision: precision, Scale: scale}
+	}
+	if typ, ok := avroTypes[col.UDTName]; ok {
+		return typ
+	}
+	return "string"
+}
+
+// enumType returns the Avro enum of an enum type, defined on its first use
+// in a file, or string when its values can't be Avro symbols.
+func (w *avroWriter) enumType(e *Enum) any {
+	name := pascalCase(e.Name)
+	if !avroName.MatchString(name) {
+		return "string"
+	}
+	for _, v := range e.Values {
+		if !avroName.MatchString(v) {
+			return "string"
+		}
+	}
+	if w.defined[name] {
+		return name
+	}
+	w.defined[name] = true
+	return avroType{Type: "enum", Name: name, Symbols: e.Values}
+}
+
+// defaultValue returns the default of a column as the JSON default of its
+// field of type fieldType, or nil when the database computes it.
+func (w *avroWriter) defaultValue(col Column, fieldType any) json.RawMessage {
+	kind, value := parseDefault(col.Default)
+	typ, _ := fieldType.(string)
+	if enum, ok := fieldType.(avroType); ok && enum.Type == "enum" || w.schema.Enum(col.UDTName) != nil && typ != "string" {
+		typ = "enum"
+	}
+	switch {
+	case kind == BoolDefault && typ == "boolean":
+		return json.RawMessage(value)
+	case kind == NumberDefault && (typ == "int" || typ == "long"):
+		if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return json.RawMessage(value)
+		}
+	case kind == NumberDefault && (typ == "float" || typ == "double"):
+		if _, err := strconv.ParseFloat(value, 64); err == nil {
+			return json.RawMessage(value)
+		}
+	case kind == StringDefault && (typ == "enum" || typ == "string" && col.UDTName != "json" && col.UDTName != "jsonb"):
+		data, _ := json.Marshal(value)
+		return data
+	}
+	return nil
+}
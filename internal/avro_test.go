@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestAvroSchemaFiles(t *testing.T) {
+	files, err := AvroSchemaFiles(blogSchema(), "blog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"users.avsc", "posts.avsc", "tags.avsc", "post_tags.avsc"}; !slices.Equal(fileNames(files), want) {
+		t.Fatalf("files = %q, want %q", fileNames(files), want)
+	}
+	goldenFiles(t, "avro", files)
+}
+
+func TestAvroFields(t *testing.T) {
+	numeric := func(name, fullType string) Column {
+		return Column{ColumnName: name, DataType: "numeric", UDTName: "numeric", FullType: fullType, IsNullable: "NO"}
+	}
+	flag := column("2fa", "boolean", false)
+	flag.Default = "false"
+	status := column("status", "post_status", false)
+	status.Default = "'draft'::post_status"
+	schema := blogSchemaWith(
+		func(s *Schema) { s.Enums = append(s.Enums, Enum{Name: "mood", Values: []string{"ok", "so-so"}}) },
+		addTable(Table{Name: "accounts", Columns: []Column{
+			status,
+			column("previous_status", "post_status", true),
+			numeric("amount", "numeric(10,2)"),
+			numeric("total", "numeric"),
+			flag,
+			column("mood", "mood", false),
+		}}),
+	)
+	files, err := AvroSchemaFiles(schema, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var record struct {
+		Fields []json.RawMessage `json:"fields"`
+	}
+	if err := json.Unmarshal(files[len(files)-1].Content, &record); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`{"name":"status","type":{"type":"enum","name":"PostStatus","symbols":["draft","published"]},"default":"draft"}`,
+		`{"name":"previous_status","type":["null","PostStatus"],"default":null}`,
+		`{"name":"amount","type":{"type":"bytes","logicalType":"decimal","precision":10,"scale":2}}`,
+		`{"name":"total","type":"string"}`,
+		`{"name":"f_2fa","type":"boolean","default":false}`,
+		`{"name":"mood","type":"string"}`,
+	}
+	if len(record.Fields) != len(want) {
+		t.Fatalf("got %d fields, want %d", len(record.Fields), len(want))
+	}
+	for i, field := range record.Fields {
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, field); err != nil {
+			t.Fatal(err)
+		}
+		if compact.String() != want[i] {
+			t.Errorf("field %d = %s, want %s", i, compact.String(), want[i])
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package internal
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// DriftSeverity grades how far a live database strayed from its snapshot.
+// Additive drift only adds to the schema, destructive drift removes or
+// changes something code may rely on.
+type DriftSeverity string
+
+const (
+	NoDrift          DriftSeverity = "none"
+	AdditiveDrift    DriftSeverity = "additive"
+	DestructiveDrift DriftSeverity = "destructive"
+)
+
+// Exit codes of the check command, so CI can tell additive drift, which
+// usually only needs a new snapshot, from destructive drift.
+const (
+	ExitAdditiveDrift    = 2
+	ExitDestructiveDrift = 3
+)
+
+// Drift is a single difference between the snapshot and the live database.
+type Drift struct {
+	// Object names the table, type, view or function, e.g. "table users".
+	Object   string        `json:"object"`
+	Change   ChangeKind    `json:"change"`
+	Severity DriftSeverity `json:"severity"`
+	Detail   string        `json:"detail"`
+}
+
+// DriftReport is the result of checking a database against a snapshot.
+type DriftReport struct {
+	Severity DriftSeverity `json:"severity"`
+	Drifts   []Drift       `json:"drifts"`
+	// Objects lists every object of the snapshot and the database, so
+	// reports can show what was checked and passed.
+	Objects []string `json:"-"`
+}
+
+// CheckDrift compares the live schema with the expected snapshot.
+func CheckDrift(expected, actual *Schema) *DriftReport {
+	diff := DiffSchemas(expected, actual)
+	report := &DriftReport{Severity: NoDrift, Drifts: []Drift{}}
+	add := func(object string, kind ChangeKind, detail string, additive bool) {
+		severity := DestructiveDrift
+		if kind == Added || additive {
+			severity = AdditiveDrift
+		}
+		report.Drifts = append(report.Drifts, Drift{Object: object, Change: kind, Severity: severity, Detail: detail})
+		if severity == DestructiveDrift || report.Severity == NoDrift {
+			report.Severity = severity
+		}
+	}
+
+	for _, e := range diff.Enums {
+		detail := fmt.Sprintf("type %s %s", e.Name, e.Kind)
+		if e.Kind == Changed {
+			detail = fmt.Sprintf("values %s -> %s", strings.Join(e.From.Values, ", "), strings.Join(e.To.Values, ", "))
+		}
+		add("type "+e.Name, e.Kind, detail, e.Kind == Changed && len(e.RemovedValues) == 0 && len(e.AddedValues) > 0)
+	}
+	for _, f := range diff.Functions {
+		add("function "+f.Name, f.Kind, fmt.Sprintf("function %s %s", f.Name, f.Kind), false)
+	}
+	for _, t := range diff.Tables {
+		object := "table " + t.Name
+		if t.Kind != Changed {
+			add(object, t.Kind, fmt.Sprintf("table %s %s", t.Name, t.Kind), false)
+			continue
+		}
+		if t.CommentChanged {
+			add(object, Changed, fmt.Sprintf("comment %q -> %q", t.From.Comment, t.To.Comment), true)
+		}
+		for _, c := range t.Columns {
+			switch c.Kind {
+			case Added:
+				add(object, c.Kind, "column "+renderColumn(*c.To)+" added", false)
+			case Removed:
+				add(object, c.Kind, "column "+c.Name+" removed", false)
+			default:
+				add(object, c.Kind, "column "+c.Name+": "+describeColumnChange(c), slices.Equal(c.Fields, []string{"comment"}))
+			}
+		}
+		if pk := t.PrimaryKey; pk != nil {
+			add(object, pk.Kind, fmt.Sprintf("primary key %s %s", describeChange(pk.From, pk.To, func(p PrimaryKey) string {
+				return "(" + strings.Join(p.Columns, ", ") + ")"
+			}), pk.Kind), false)
+		}
+		for _, u := range t.Uniques {
+			add(object, u.Kind, fmt.Sprintf("unique %s %s", u.Name, u.Kind), false)
+		}
+		for _, fk := range t.ForeignKeys {
+			add(object, fk.Kind, fmt.Sprintf("foreign key %s %s: %s", fk.Name, fk.Kind,
+				describeChange(fk.From, fk.To, renderForeignKeyTarget)), false)
+		}
+		for _, c := range t.Checks {
+			add(object, c.Kind, fmt.Sprintf("check %s %s", c.Name, c.Kind), false)
+		}
+		for _, idx := range t.Indexes {
+			add(object, idx.Kind, fmt.Sprintf("index %s %s", idx.Name, idx.Kind), false)
+		}
+	}
+	for _, v := range diff.Views {
+		add("view "+v.Name, v.Kind, fmt.Sprintf("view %s %s", v.Name, v.Kind), false)
+	}
+
+	seen := make(map[string]bool)
+	for _, s := range []*Schema{expected, actual} {
+		for _, e := range s.Enums {
+			seen["type "+e.Name] = true
+		}
+		for _, f := range s.Functions {
+			seen["function "+f.Name] = true
+		}
+		for _, t := range s.Tables {
+			seen["table "+t.Name] = true
+		}
+		for _, v := range s.Views {
+			seen["view "+v.Name] = true
+		}
+	}
+	for object := range seen {
+		report.Objects = append(report.Objects, object)
+	}
+	slices.Sort(report.Objects)
+	return report
+}
+
+// ExitCode returns the exit code reporting the severity of the drift.
+func (r *DriftReport) ExitCode() int {
+	switch r.Severity {
+	case AdditiveDrift:
+		return ExitAdditiveDrift
+	case DestructiveDrift:
+		return ExitDestructiveDrift
+	}
+	return 0
+}
+
+// Text returns the drift as one line per difference.
+func (r *DriftReport) Text() string {
+	if len(r.Drifts) == 0 {
+		return "No drift\n"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Schema drift: %s\n", r.Severity)
+	for _, d := range r.Drifts {
+		fmt.Fprintf(&b, "  [%s] %s: %s\n", d.Severity, d.Object, d.Detail)
+	}
+	return b.String()
+}
+
+// JSON returns the report as indented JSON.
+func (r *DriftReport) JSON() ([]byte, error) {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode drift report: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// JUnit returns the report as a JUnit XML document with one test case per
+// schema object, failing for every object that drifted.
+func (r *DriftReport) JUnit() ([]byte, error) {
+	suite := junitTestSuite{Name: "schema drift", Tests: len(r.Objects)}
+	for _, object := range r.Objects {
+		tc := junitTestCase{Name: object, ClassName: "schema." + strings.SplitN(object, " ", 2)[0]}
+		var details []string
+		severity := NoDrift
+		for _, d := range r.Drifts {
+			if d.Object != object {
+				continue
+			}
+			details = append(details, fmt.Sprintf("[%s] %s", d.Severity, d.Detail))
+			if d.Severity == DestructiveDrift || severity == NoDrift {
+				severity = d.Severity
+			}
+		}
+		if len(details) > 0 {
+			tc.Failure = &junitFailure{
+				Message: fmt.Sprintf("%s drift on %s", severity, object),
+				Type:    string(severity),
+				Text:    strings.Join(details, "\n"),
+			}
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	data, err := xml.MarshalIndent(junitTestSuites{Suites: []junitTestSuite{suite}}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode junit report: %w", err)
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
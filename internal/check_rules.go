@@ -0,0 +1,303 @@
+package internal
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// bound is a limit on the values of a numeric column, a number literal.
+type bound struct {
+	value     string
+	exclusive bool
+}
+
+// literal is a value a CHECK constraint allows, a number or a string.
+type literal struct {
+	value  string
+	string bool
+}
+
+// fieldRules are the constraints on a column's values that validators can
+// check before the database does: the length of its type and the parts of
+// its CHECK constraints they can express.
+type fieldRules struct {
+	minLength, maxLength int64
+	min, max             *bound
+	// values lists the only values allowed, from an IN list.
+	values []literal
+}
+
+var (
+	checkIdentifier = regexp.MustCompile(`^(?:[A-Za-z_][\w$]*|"(?:[^"]|"")+")$`)
+	checkLength     = regexp.MustCompile(`(?i)^(?:length|char_length|character_length)\((.*)\)$`)
+	checkAny        = regexp.MustCompile(`(?is)^ANY\s*\((.*)\)$`)
+	checkArray      = regexp.MustCompile(`(?is)^ARRAY\[(.*)\]$`)
+	checkIn         = regexp.MustCompile(`(?is)^(.*?)\s+IN\s*\((.*)\)$`)
+)
+
+// flippedOperators turn a comparison around, for literals on the left.
+var flippedOperators = map[string]string{">": "<", ">=": "<=", "<": ">", "<=": ">=", "=": "=", "<>": "<>", "!=": "!="}
+
+// columnRules returns the rules of a column. A CHECK constraint is split
+// into the conditions it ANDs together, and each condition comparing the
+// column, or its length, with literals is kept; the others are left to the
+// database.
+func columnRules(t *Table, col Column) fieldRules {
+	var rules fieldRules
+	if col.IsArray() {
+		return rules
+	}
+	if col.UDTName == "varchar" || col.UDTName == "bpchar" {
+		if length, _, ok := columnModifiers(col); ok {
+			rules.maxLength = length
+		}
+	}
+	for _, c := range t.Checks {
+		for _, cond := range splitConjunction(c.Expression) {
+			rules.add(col.ColumnName, cond)
+		}
+	}
+	return rules
+}
+
+// add narrows the rules by a condition, if it's about the column.
+func (r *fieldRules) add(column, cond string) {
+	if m := checkIn.FindStringSubmatch(cond); m != nil {
+		if name, ok := checkColumn(m[1]); ok && name == column {
+			r.allow(splitTopLevel(m[2]))
+		}
+		return
+	}
+	left, op, right, ok := splitComparison(cond)
+	if !ok {
+		return
+	}
+	if _, isColumn := checkColumn(left); !isColumn && checkLength.FindStringSubmatch(stripCasts(left)) == nil {
+		left, right, op = right, left, flippedOperators[op]
+	}
+	left = stripCasts(left)
+
+	// length(column) compared with a number.
+	if m := checkLength.FindStringSubmatch(left); m != nil {
+		name, ok := checkColumn(m[1])
+		value, isNumber := checkLiteral(right)
+		n, err := strconv.ParseInt(value.value, 10, 64)
+		if !ok || name != column || !isNumber || value.string || err != nil {
+			return
+		}
+		switch op {
+		case ">":
+			r.atLeast(n + 1)
+		case ">=":
+			r.atLeast(n)
+		case "<":
+			r.atMost(n - 1)
+		case "<=":
+			r.atMost(n)
+		case "=":
+			r.atLeast(n)
+			r.atMost(n)
+		}
+		return
+	}
+
+	name, ok := checkColumn(left)
+	if !ok || name != column {
+		return
+	}
+	if op == "=" {
+		if m := checkAny.FindStringSubmatch(strings.TrimSpace(right)); m != nil {
+			if array := checkArray.FindStringSubmatch(stripCasts(m[1])); array != nil {
+				r.allow(splitTopLevel(array[1]))
+			}
+			return
+		}
+	}
+	value, ok := checkLiteral(right)
+	if !ok {
+		return
+	}
+	switch {
+	case op == "=":
+		r.allow([]string{right})
+	case value.string && (op == "<>" || op == "!=") && value.value == "":
+		r.atLeast(1)
+	case value.string:
+	case op == ">" || op == ">=":
+		r.above(bound{value: value.value, exclusive: op == ">"})
+	case op == "<" || op == "<=":
+		r.below(bound{value: value.value, exclusive: op == "<"})
+	}
+}
+
+func (r *fieldRules) atLeast(n int64) {
+	r.minLength = max(r.minLength, n)
+}
+
+func (r *fieldRules) atMost(n int64) {
+	if r.maxLength == 0 || n < r.maxLength {
+		r.maxLength = max(n, 0)
+	}
+}
+
+// above raises the lower bound, below lowers the upper one, keeping the
+// tighter of two bounds.
+func (r *fieldRules) above(b bound) {
+	if r.min == nil || compareNumbers(b.value, r.min.value) > 0 || compareNumbers(b.value, r.min.value) == 0 && b.exclusive {
+		r.min = &b
+	}
+}
+
+func (r *fieldRules) below(b bound) {
+	if r.max == nil || compareNumbers(b.value, r.max.value) < 0 || compareNumbers(b.value, r.max.value) == 0 && b.exclusive {
+		r.max = &b
+	}
+}
+
+// allow restricts the column to a list of literals. Lists with anything
+// else in them are ignored.
+func (r *fieldRules) allow(operands []string) {
+	var values []literal
+	for _, operand := range operands {
+		value, ok := checkLiteral(operand)
+		if !ok {
+			return
+		}
+		values = append(values, value)
+	}
+	if r.values == nil {
+		r.values = values
+		return
+	}
+	// Two lists allow the values in both.
+	var both []literal
+	for _, v := range r.values {
+		for _, w := range values {
+			if v == w {
+				both = append(both, v)
+				break
+			}
+		}
+	}
+	r.values = both
+}
+
+func compareNumbers(a, b string) int {
+	x, _ := strconv.ParseFloat(a, 64)
+	y, _ := strconv.ParseFloat(b, 64)
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+// splitConjunction splits a CHECK expression into the conditions it ANDs
+// together.
+func splitConjunction(expr string) []string {
+	expr = stripParens(expr)
+	parts := splitTopLevelWord(expr, "AND")
+	if len(parts) == 1 {
+		return parts
+	}
+	var conds []string
+	for _, part := range parts {
+		conds = append(conds, splitConjunction(part)...)
+	}
+	return conds
+}
+
+// splitComparison splits a condition at its top level comparison operator.
+func splitComparison(cond string) (string, string, string, bool) {
+	depth, quoted := 0, false
+	for i := 0; i < len(cond); i++ {
+		switch c := cond[i]; {
+		case c == '\'':
+			quoted = !quoted
+		case quoted:
+		case c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']':
+			depth--
+		case depth == 0 && strings.ContainsRune("<>=!", rune(c)):
+			op := string(c)
+			if i+1 < len(cond) && strings.ContainsRune("<>=", rune(cond[i+1])) {
+				op += string(cond[i+1])
+			}
+			if _, ok := flippedOperators[op]; !ok {
+				return "", "", "", false
+			}
+			return strings.TrimSpace(cond[:i]), op, strings.TrimSpace(cond[i+len(op):]), true
+		}
+	}
+	return "", "", "", false
+}
+
+// splitTopLevelWord splits s at a keyword outside quotes and brackets.
+func splitTopLevelWord(s, word string) []string {
+	var parts []string
+	depth, quoted, start := 0, false, 0
+	sep := " " + word + " "
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\'':
+			quoted = !quoted
+		case quoted:
+		case c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']':
+			depth--
+		case depth == 0 && i+len(sep) <= len(s) && strings.EqualFold(s[i:i+len(sep)], sep):
+			parts = append(parts, strings.TrimSpace(s[start:i]))
+			start = i + len(sep)
+			i += len(sep) - 1
+		}
+	}
+	return append(parts, strings.TrimSpace(s[start:]))
+}
+
+// stripCasts removes the parentheses and casts around an operand, as in
+// ((email)::text) or ('0'::numeric).
+func stripCasts(s string) string {
+	for {
+		stripped := stripParens(s)
+		if cast := trailingCast.ReplaceAllString(stripped, ""); strings.Count(cast, "'")%2 == 0 {
+			stripped = strings.TrimSpace(cast)
+		}
+		if stripped == s {
+			return s
+		}
+		s = stripped
+	}
+}
+
+// checkColumn returns the column an operand names.
+func checkColumn(operand string) (string, bool) {
+	operand = stripCasts(operand)
+	if !checkIdentifier.MatchString(operand) || strings.EqualFold(operand, "NULL") {
+		return "", false
+	}
+	if strings.HasPrefix(operand, `"`) {
+		return strings.ReplaceAll(operand[1:len(operand)-1], `""`, `"`), true
+	}
+	return strings.ToLower(operand), true
+}
+
+// checkLiteral returns the number or string literal of an operand.
+func checkLiteral(operand string) (literal, bool) {
+	kind, value := parseDefault(stripCasts(operand))
+	switch kind {
+	case NumberDefault:
+		return literal{value: value}, true
+	case StringDefault:
+		// Numbers are often written as strings cast to numeric.
+		if numberLiteral.MatchString(value) && strings.Contains(operand, "::numeric") {
+			return literal{value: value}, true
+		}
+		return literal{value: value, string: true}, true
+	}
+	return literal{}, false
+}
@@ -0,0 +1,81 @@
+package internal
+
+import "testing"
+
+func TestCheckDrift(t *testing.T) {
+	tests := []struct {
+		name     string
+		change   func(s *Schema)
+		severity DriftSeverity
+		exitCode int
+	}{
+		{
+			name:     "no drift",
+			change:   func(s *Schema) {},
+			severity: NoDrift,
+		},
+		{
+			name:     "table added",
+			change:   addTable(commentsTable()),
+			severity: AdditiveDrift,
+			exitCode: ExitAdditiveDrift,
+		},
+		{
+			name:     "enum value added",
+			change:   addEnumValues("post_status", "archived"),
+			severity: AdditiveDrift,
+			exitCode: ExitAdditiveDrift,
+		},
+		{
+			name:     "column comment changed",
+			change:   alterColumn("users", "bio", func(c *Column) { c.Comment = "About the author" }),
+			severity: AdditiveDrift,
+			exitCode: ExitAdditiveDrift,
+		},
+		{
+			name:     "column removed",
+			change:   dropColumn("users", "bio"),
+			severity: DestructiveDrift,
+			exitCode: ExitDestructiveDrift,
+		},
+		{
+			name: "additive and destructive",
+			change: func(s *Schema) {
+				addTable(commentsTable())(s)
+				alterColumn("posts", "rating", func(c *Column) { c.IsNullable = "NO" })(s)
+			},
+			severity: DestructiveDrift,
+			exitCode: ExitDestructiveDrift,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := CheckDrift(blogSchema(), blogSchemaWith(tt.change))
+			if report.Severity != tt.severity {
+				t.Errorf("severity = %s, want %s: %+v", report.Severity, tt.severity, report.Drifts)
+			}
+			if got := report.ExitCode(); got != tt.exitCode {
+				t.Errorf("ExitCode() = %d, want %d", got, tt.exitCode)
+			}
+		})
+	}
+}
+
+func TestDriftReports(t *testing.T) {
+	report := CheckDrift(blogSchema(), blogSchemaWith(
+		addTable(commentsTable()),
+		dropColumn("users", "bio"),
+		addEnumValues("post_status", "archived"),
+	))
+	golden(t, "check/drift.txt", []byte(report.Text()))
+	data, err := report.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden(t, "check/drift.json", data)
+	data, err = report.JUnit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden(t, "check/drift.xml", data)
+}
@@ -0,0 +1,594 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Dialect describes how the Postgres schema model is rendered as DDL for
+// another database engine. The shared writer below handles the statement
+// layout, a dialect only answers the questions that differ between engines.
+type Dialect interface {
+	Name() string
+	QuoteIdent(name string) string
+	// ColumnType maps the column to a type of the dialect. enum is set when
+	// the column uses a Postgres enum type. Warnings describe what got lost.
+	ColumnType(col Column, enum *Enum) (string, []string)
+	// AutoIncrement returns the clause that makes a serial or identity
+	// column generate its values.
+	AutoIncrement(col Column) string
+	BooleanLiteral(v bool) string
+	// ReferentialAction maps an ON DELETE/ON UPDATE action, "" drops it.
+	ReferentialAction(action string) string
+	// UUIDFunction returns the expression generating a random uuid, or ""
+	// when the dialect has none.
+	UUIDFunction() string
+	// InlineEnumCheck reports whether enum columns need a CHECK constraint
+	// listing the allowed values.
+	InlineEnumCheck() bool
+	// InlineForeignKeys reports whether cyclic foreign keys stay inside
+	// CREATE TABLE because the dialect can't add them afterwards.
+	InlineForeignKeys() bool
+	PartialIndexes() bool
+	// KeyColumnType returns the type a key or indexed column of dataType
+	// takes instead, or "" when dataType can be indexed as it is.
+	KeyColumnType(dataType string) string
+	// ColumnDefault renders the DEFAULT value of a column of dataType from
+	// a translated default.
+	ColumnDefault(dataType, def string) string
+	// SingleCascadePath reports whether the dialect rejects foreign keys
+	// whose cascading actions make a cycle or a second path between two
+	// tables.
+	SingleCascadePath() bool
+	// Function returns the name of the dialect function doing what the
+	// named Postgres function does in a CHECK or index predicate, or ""
+	// when there is none.
+	Function(name string) string
+	ColumnComment(comment string) string
+	TableOptions(t Table) string
+}
+
+var dialects = map[string]Dialect{
+	"mysql":  mysqlDialect{},
+	"sqlite": sqliteDialect{},
+	"mssql":  mssqlDialect{},
+}
+
+// SupportedDialects lists the values accepted by WriteDDL.
+var SupportedDialects = []string{"postgres", "mysql", "sqlite", "mssql"}
+
+// DDLStatement is the DDL creating one schema object.
+type DDLStatement struct {
+	Kind ObjectKind
+	Name string
+	SQL  string
+}
+
+// RenderDDL renders the schema as DDL statements of the named dialect, in
+// creation order. The returned warnings list the constructs that could not
+// be translated faithfully.
+func RenderDDL(schema *Schema, dialectName string) ([]DDLStatement, []string, error) {
+	if dialectName == "" || dialectName == "postgres" {
+		return postgresStatements(schema), nil, nil
+	}
+	d, ok := dialects[dialectName]
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported dialect %s", dialectName)
+	}
+	dw := &ddlWriter{dialect: d, schema: schema}
+	statements := dw.statements()
+	return statements, dw.warnings, nil
+}
+
+// WriteDDL writes the schema as DDL of the named dialect to w.
+func WriteDDL(w io.Writer, schema *Schema, dialectName string) ([]string, error) {
+	statements, warnings, err := RenderDDL(schema, dialectName)
+	if err != nil {
+		return nil, err
+	}
+	if (dialectName == "" || dialectName == "postgres") && len(schema.Functions) > 0 {
+		if _, err := io.WriteString(w, disableFunctionBodyChecks+"\n"); err != nil {
+			return warnings, err
+		}
+	}
+	for _, stmt := range statements {
+		if _, err := fmt.Fprintln(w, stmt.SQL); err != nil {
+			return warnings, err
+		}
+	}
+	return warnings, nil
+}
+
+// objectDirs names the --split directory of each object kind.
+var objectDirs = map[ObjectKind]string{
+	KindEnum:       "types",
+	KindFunction:   "functions",
+	KindTable:      "tables",
+	KindView:       "views",
+	KindForeignKey: "constraints",
+}
+
+// DDLFiles turns DDL statements into output files. Without split everything
+// goes into a single file called name. With split every object gets its own
+// file in a directory per kind, and order.txt lists the files in the order
+// they have to be replayed.
+func DDLFiles(statements []DDLStatement, split bool, name string) []File {
+	if !split {
+		var b strings.Builder
+		for _, stmt := range statements {
+			b.WriteString(stmt.SQL)
+			b.WriteString("\n")
+		}
+		return []File{{Name: name, Content: []byte(b.String())}}
+	}
+
+	var files []File
+	var order strings.Builder
+	seen := make(map[string]bool)
+	for _, stmt := range statements {
+		base := objectDirs[stmt.Kind] + "/" + strings.NewReplacer("/", "_", "\\", "_").Replace(stmt.Name)
+		path := base + ".sql"
+		// Overloaded functions share a name.
+		for i := 2; seen[path]; i++ {
+			path = fmt.Sprintf("%s_%d.sql", base, i)
+		}
+		seen[path] = true
+		files = append(files, File{Name: path, Content: []byte(stmt.SQL)})
+		fmt.Fprintln(&order, path)
+	}
+	return append(files, File{Name: "order.txt", Content: []byte(order.String())})
+}
+
+type ddlWriter struct {
+	dialect  Dialect
+	schema   *Schema
+	warnings []string
+	// noCascade holds the foreign keys, by table and constraint name,
+	// whose cascading actions are dropped.
+	noCascade map[string]bool
+}
+
+func (dw *ddlWriter) warn(format string, args ...any) {
+	dw.warnings = append(dw.warnings, fmt.Sprintf(format, args...))
+}
+
+func (dw *ddlWriter) statements() []DDLStatement {
+	order := OrderSchema(dw.schema)
+	if dw.dialect.InlineForeignKeys() {
+		order.DeferredForeignKeys = nil
+	}
+	if dw.dialect.SingleCascadePath() {
+		dw.limitCascades()
+	}
+	var statements []DDLStatement
+	for _, obj := range order.Objects {
+		var sql string
+		switch obj.Kind {
+		case KindEnum:
+			// Enums are folded into the columns that use them.
+			continue
+		case KindFunction:
+			f := dw.schema.Functions[obj.Index]
+			dw.warn("function %s(%s) skipped: function bodies can't be translated to %s", f.Name, f.Arguments, dw.dialect.Name())
+			continue
+		case KindView:
+			sql = dw.view(dw.schema.Views[obj.Index])
+		default:
+			sql = dw.table(dw.schema.Tables[obj.Index], order)
+		}
+		statements = append(statements, DDLStatement{Kind: obj.Kind, Name: dw.schema.objectName(obj), SQL: sql})
+	}
+	for _, fk := range order.DeferredForeignKeys {
+		statements = append(statements, DDLStatement{
+			Kind: KindForeignKey,
+			Name: fk.SourceTable + "." + fk.ConstraintName,
+			SQL: fmt.Sprintf("ALTER TABLE %s ADD %s;\n",
+				dw.dialect.QuoteIdent(fk.SourceTable), dw.foreignKey(fk)),
+		})
+	}
+	return statements
+}
+
+func (dw *ddlWriter) view(v View) string {
+	dw.warn("view %s copied as a comment: Postgres view definitions are not translated to %s", v.Name, dw.dialect.Name())
+	var b strings.Builder
+	fmt.Fprintf(&b, "-- CREATE VIEW %s AS\n", dw.dialect.QuoteIdent(v.Name))
+	for _, line := range strings.Split(strings.TrimSpace(v.Definition), "\n") {
+		fmt.Fprintf(&b, "-- %s\n", line)
+	}
+	return b.String()
+}
+
+func (dw *ddlWriter) table(t Table, order *CreationOrder) string {
+	d := dw.dialect
+	var lines []string
+	inlinePK := false
+	for _, col := range t.Columns {
+		line, pk := dw.column(t, col)
+		inlinePK = inlinePK || pk
+		lines = append(lines, "    "+line)
+	}
+	if t.PrimaryKey != nil && !inlinePK {
+		lines = append(lines, fmt.Sprintf("    CONSTRAINT %s PRIMARY KEY (%s)",
+			d.QuoteIdent(t.PrimaryKey.ConstraintName), dw.quoteIdents(t.PrimaryKey.Columns)))
+	}
+	for _, u := range t.Uniques {
+		lines = append(lines, fmt.Sprintf("    CONSTRAINT %s UNIQUE (%s)",
+			d.QuoteIdent(u.ConstraintName), dw.quoteIdents(u.Columns)))
+	}
+	for _, fk := range t.ForeignKeys {
+		if !order.IsDeferred(fk) {
+			lines = append(lines, "    "+dw.foreignKey(fk))
+		}
+	}
+	var skipped []string
+	for _, c := range t.Checks {
+		expr, err := dw.translateExpression(c.Expression)
+		if err != nil {
+			warning := fmt.Sprintf("check constraint %s on %s skipped: %v", c.ConstraintName, t.Name, err)
+			dw.warn("%s", warning)
+			skipped = append(skipped, "-- WARN: "+warning+"\n")
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("    CONSTRAINT %s %s", d.QuoteIdent(c.ConstraintName), checkClause(expr)))
+	}
+	if d.InlineEnumCheck() {
+		for _, col := range t.Columns {
+			if e := dw.schema.Enum(col.UDTName); e != nil && !col.IsArray() {
+				values := make([]string, len(e.Values))
+				for i, v := range e.Values {
+					values[i] = quoteLiteral(v)
+				}
+				lines = append(lines, fmt.Sprintf("    CHECK (%s IN (%s))", d.QuoteIdent(col.ColumnName), strings.Join(values, ", ")))
+			}
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.Join(skipped, ""))
+	fmt.Fprintf(&b, "CREATE TABLE %s (\n%s\n)%s;\n", d.QuoteIdent(t.Name), strings.Join(lines, ",\n"), d.TableOptions(t))
+	for _, idx := range t.Indexes {
+		if stmt := dw.index(t, idx); stmt != "" {
+			b.WriteString(stmt)
+		}
+	}
+	return b.String()
+}
+
+// column renders a column definition. The second result reports whether
+// the column carries the primary key inline, which SQLite needs for
+// AUTOINCREMENT.
+func (dw *ddlWriter) column(t Table, col Column) (string, bool) {
+	d := dw.dialect
+	dataType, warnings := d.ColumnType(col, dw.schema.Enum(col.ElementType()))
+	for _, w := range warnings {
+		dw.warn("%s.%s: %s", t.Name, col.ColumnName, w)
+	}
+	if dw.keyColumn(t, col.ColumnName) {
+		if keyType := d.KeyColumnType(dataType); keyType != "" {
+			dw.warn("%s.%s: %s is a key or indexed column, stored as %s", t.Name, col.ColumnName, col.FullType, keyType)
+			dataType = keyType
+		}
+	}
+	parts := []string{d.QuoteIdent(col.ColumnName), dataType}
+	inlinePK := false
+	if col.IsGenerated() {
+		clause := d.AutoIncrement(col)
+		if strings.Contains(clause, "PRIMARY KEY") {
+			if t.PrimaryKey == nil || len(t.PrimaryKey.Columns) != 1 || t.PrimaryKey.Columns[0] != col.ColumnName {
+				dw.warn("%s.%s: auto increment dropped, %s only supports it on a single column primary key", t.Name, col.ColumnName, d.Name())
+				clause = ""
+			} else {
+				inlinePK = true
+			}
+		}
+		if clause != "" {
+			parts = append(parts, clause)
+		}
+	} else if col.Default != "" {
+		if def, ok := dw.translateDefault(col.Default); ok {
+			parts = append(parts, "DEFAULT "+d.ColumnDefault(dataType, def))
+		} else {
+			dw.warn("%s.%s: default %s dropped", t.Name, col.ColumnName, col.Default)
+		}
+	}
+	if !inlinePK {
+		if col.Nullable() {
+			parts = append(parts, "NULL")
+		} else {
+			parts = append(parts, "NOT NULL")
+		}
+	}
+	if col.Comment != "" {
+		if comment := d.ColumnComment(col.Comment); comment != "" {
+			parts = append(parts, comment)
+		}
+	}
+	return strings.Join(parts, " "), inlinePK
+}
+
+// keyColumn reports whether a column is part of a key, an index or a
+// foreign key, on either side.
+func (dw *ddlWriter) keyColumn(t Table, column string) bool {
+	var keys [][]string
+	if t.PrimaryKey != nil {
+		keys = append(keys, t.PrimaryKey.Columns)
+	}
+	for _, u := range t.Uniques {
+		keys = append(keys, u.Columns)
+	}
+	for _, idx := range t.Indexes {
+		keys = append(keys, idx.Columns)
+	}
+	for _, fk := range t.ForeignKeys {
+		keys = append(keys, fk.SourceColumns)
+	}
+	for _, other := range dw.schema.Tables {
+		for _, fk := range other.ForeignKeys {
+			if fk.TargetTable == t.Name {
+				keys = append(keys, fk.TargetColumns)
+			}
+		}
+	}
+	for _, columns := range keys {
+		if slices.Contains(columns, column) {
+			return true
+		}
+	}
+	return false
+}
+
+// cascades reports whether a foreign key changes or deletes the rows
+// referencing a row that is updated or deleted.
+func cascades(fk ForeignKey) bool {
+	for _, action := range []string{fk.OnDelete, fk.OnUpdate} {
+		if action == "CASCADE" || action == "SET NULL" || action == "SET DEFAULT" {
+			return true
+		}
+	}
+	return false
+}
+
+// limitCascades drops the cascading actions of the foreign keys that would
+// make a cycle, or a second path from one table to another, out of the
+// cascading foreign keys before them.
+func (dw *ddlWriter) limitCascades() {
+	dw.noCascade = make(map[string]bool)
+	// edges maps each table to the tables its changes cascade to.
+	edges := make(map[string][]string)
+	reachable := func(from string) map[string]bool {
+		seen := map[string]bool{from: true}
+		queue := []string{from}
+		for len(queue) > 0 {
+			table := queue[0]
+			queue = queue[1:]
+			for _, next := range edges[table] {
+				if !seen[next] {
+					seen[next] = true
+					queue = append(queue, next)
+				}
+			}
+		}
+		return seen
+	}
+	for _, t := range dw.schema.Tables {
+		for _, fk := range t.ForeignKeys {
+			if !cascades(fk) {
+				continue
+			}
+			// The new path runs from the referenced table, and every table
+			// cascading to it, to the referencing table and every table it
+			// cascades to. It conflicts when any of those pairs is already
+			// connected.
+			targets := reachable(fk.SourceTable)
+			conflict := false
+			for _, other := range dw.schema.Tables {
+				if reachable(other.Name)[fk.TargetTable] {
+					for target := range targets {
+						if reachable(other.Name)[target] {
+							conflict = true
+						}
+					}
+				}
+			}
+			if conflict {
+				dw.noCascade[fk.SourceTable+"."+fk.ConstraintName] = true
+				dw.warn("foreign key %s on %s: referential actions replaced by NO ACTION, %s rejects cycles and multiple cascade paths",
+					fk.ConstraintName, fk.SourceTable, dw.dialect.Name())
+				continue
+			}
+			edges[fk.TargetTable] = append(edges[fk.TargetTable], fk.SourceTable)
+		}
+	}
+}
+
+func (dw *ddlWriter) foreignKey(fk ForeignKey) string {
+	d := dw.dialect
+	clause := fmt.Sprintf("CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
+		d.QuoteIdent(fk.ConstraintName), dw.quoteIdents(fk.SourceColumns),
+		d.QuoteIdent(fk.TargetTable), dw.quoteIdents(fk.TargetColumns))
+	if dw.noCascade[fk.SourceTable+"."+fk.ConstraintName] {
+		return clause
+	}
+	for _, action := range []struct{ event, action string }{{"DELETE", fk.OnDelete}, {"UPDATE", fk.OnUpdate}} {
+		if action.action == "" || action.action == "NO ACTION" {
+			continue
+		}
+		if mapped := d.ReferentialAction(action.action); mapped != "" {
+			clause += " ON " + action.event + " " + mapped
+		}
+	}
+	return clause
+}
+
+func (dw *ddlWriter) index(t Table, idx Index) string {
+	d := dw.dialect
+	if idx.Method != "btree" && idx.Method != "" {
+		dw.warn("index %s skipped: %s indexes are Postgres specific", idx.Name, idx.Method)
+		return ""
+	}
+	for _, col := range idx.Columns {
+		if t.Column(col) == nil {
+			dw.warn("index %s skipped: expression %s can't be translated", idx.Name, col)
+			return ""
+		}
+	}
+	unique := ""
+	if idx.Unique {
+		unique = "UNIQUE "
+	}
+	where := ""
+	if idx.Where != "" {
+		expr, err := dw.translateExpression(idx.Where)
+		if !d.PartialIndexes() || err != nil {
+			dw.warn("index %s: partial predicate %s dropped", idx.Name, idx.Where)
+		} else {
+			where = " WHERE " + expr
+		}
+	}
+	return fmt.Sprintf("CREATE %sINDEX %s ON %s (%s)%s;\n",
+		unique, d.QuoteIdent(idx.Name), d.QuoteIdent(t.Name), dw.quoteIdents(idx.Columns), where)
+}
+
+func (dw *ddlWriter) quoteIdents(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = dw.dialect.QuoteIdent(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+var (
+	numericLiteral = regexp.MustCompile(`^-?\d+(\.\d+)?$`)
+	stringLiteral  = regexp.MustCompile(`^'((?:[^']|'')*)'$`)
+	// postgresCast matches "::type" suffixes, including the multi word
+	// type names, modifiers and array brackets.
+	postgresCast = regexp.MustCompile(`::(?:character varying|timestamp with(?:out)? time zone|time with(?:out)? time zone|double precision|bit varying|"?[a-zA-Z_][a-zA-Z0-9_]*"?)(?:\(\d+(?:,\s*\d+)?\))?(?:\[\])*`)
+	// anyArray matches "= ANY (ARRAY[...])", whose array Postgres wraps in
+	// another pair of parentheses when it had a cast.
+	anyArray = regexp.MustCompile(`\s*=\s*ANY\s*\((?:\(ARRAY\[(.*?)\]\)|ARRAY\[(.*?)\])\)`)
+	// postgresOnly matches operators and syntax that survive cast removal
+	// but mean nothing, or something else, in other dialects.
+	postgresOnly = regexp.MustCompile(`::|~|@>|<@|\|\||\bILIKE\b|\bSIMILAR TO\b|\bARRAY\b|\bANY\b`)
+)
+
+// translateDefault translates the common Postgres column defaults: literals,
+// booleans, the current timestamp and uuid generators.
+func (dw *ddlWriter) translateDefault(expr string) (string, bool) {
+	d := dw.dialect
+	expr = strings.TrimSpace(postgresCast.ReplaceAllString(expr, ""))
+	expr = stripParens(expr)
+	switch strings.ToLower(expr) {
+	case "true":
+		return d.BooleanLiteral(true), true
+	case "false":
+		return d.BooleanLiteral(false), true
+	case "now()", "current_timestamp", "localtimestamp", "transaction_timestamp()", "statement_timestamp()", "clock_timestamp()":
+		return "CURRENT_TIMESTAMP", true
+	case "current_date":
+		return "CURRENT_DATE", true
+	case "gen_random_uuid()", "uuid_generate_v4()":
+		if fn := d.UUIDFunction(); fn != "" {
+			return fn, true
+		}
+		return "", false
+	case "null":
+		return "NULL", true
+	}
+	if numericLiteral.MatchString(expr) || stringLiteral.MatchString(expr) {
+		return expr, true
+	}
+	return "", false
+}
+
+// expressionKeywords are the SQL words of translated expressions that are
+// neither identifiers nor functions.
+var expressionKeywords = map[string]bool{
+	"and": true, "or": true, "not": true, "in": true, "is": true, "null": true,
+	"like": true, "between": true, "escape": true, "distinct": true, "from": true,
+	"case": true, "when": true, "then": true, "else": true, "end": true,
+}
+
+// portableFunctions are the functions named the same in every dialect.
+var portableFunctions = map[string]string{
+	"lower":    "LOWER",
+	"upper":    "UPPER",
+	"abs":      "ABS",
+	"coalesce": "COALESCE",
+	"nullif":   "NULLIF",
+}
+
+// translateExpression rewrites a Postgres check or index predicate into the
+// dialect: casts are dropped, ANY (ARRAY[...]) becomes IN, functions are
+// mapped and identifiers quoted. It fails on Postgres specific syntax and
+// on functions the dialect has no equivalent of.
+func (dw *ddlWriter) translateExpression(expr string) (string, error) {
+	d := dw.dialect
+	expr = postgresCast.ReplaceAllString(expr, "")
+	expr = anyArray.ReplaceAllString(expr, " IN ($1$2)")
+	if postgresOnly.MatchString(expr) {
+		return "", fmt.Errorf("%s can't be translated", strings.TrimSpace(expr))
+	}
+	var b strings.Builder
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == '\'' || c == '"':
+			end := i + 1
+			for end < len(expr) {
+				if expr[end] == c {
+					if end+1 < len(expr) && expr[end+1] == c {
+						end += 2
+						continue
+					}
+					break
+				}
+				end++
+			}
+			token := expr[i:min(end+1, len(expr))]
+			if c == '"' {
+				name := strings.ReplaceAll(strings.Trim(token, `"`), `""`, `"`)
+				token = d.QuoteIdent(name)
+			}
+			b.WriteString(token)
+			i = end + 1
+		case c >= '0' && c <= '9':
+			end := i
+			for end < len(expr) && (expr[end] >= '0' && expr[end] <= '9' || expr[end] == '.') {
+				end++
+			}
+			b.WriteString(expr[i:end])
+			i = end
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			end := i
+			for end < len(expr) && isIdentChar(expr[end]) {
+				end++
+			}
+			word := expr[i:end]
+			lower := strings.ToLower(word)
+			next := strings.TrimLeft(expr[end:], " ")
+			switch {
+			case expressionKeywords[lower]:
+				b.WriteString(word)
+			case lower == "true" || lower == "false":
+				b.WriteString(d.BooleanLiteral(lower == "true"))
+			case strings.HasPrefix(next, "("):
+				fn := d.Function(lower)
+				if fn == "" {
+					return "", fmt.Errorf("function %s has no %s equivalent", word, d.Name())
+				}
+				b.WriteString(fn)
+			default:
+				b.WriteString(d.QuoteIdent(word))
+			}
+			i = end
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,140 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+type mssqlDialect struct{}
+
+func (mssqlDialect) Name() string { return "mssql" }
+
+func (mssqlDialect) QuoteIdent(name string) string {
+	return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
+}
+
+func (mssqlDialect) ColumnType(col Column, enum *Enum) (string, []string) {
+	if col.IsArray() {
+		return "NVARCHAR(MAX)", []string{fmt.Sprintf("array type %s stored as JSON text", col.FullType)}
+	}
+	if enum != nil {
+		longest := 1
+		for _, v := range enum.Values {
+			longest = max(longest, len(v))
+		}
+		return fmt.Sprintf("NVARCHAR(%d)", longest), nil
+	}
+	switch col.UDTName {
+	case "int2":
+		return "SMALLINT", nil
+	case "int4":
+		return "INT", nil
+	case "int8":
+		return "BIGINT", nil
+	case "float4":
+		return "REAL", nil
+	case "float8":
+		return "FLOAT", nil
+	case "numeric":
+		if col.NumericPrecision > 0 {
+			return fmt.Sprintf("DECIMAL(%d,%d)", min(col.NumericPrecision, 38), col.NumericScale), nil
+		}
+		return "DECIMAL(38,10)", []string{"unconstrained numeric mapped to DECIMAL(38,10)"}
+	case "money":
+		return "MONEY", nil
+	case "bool":
+		return "BIT", nil
+	case "varchar":
+		if col.CharMaxLength > 0 && col.CharMaxLength <= 4000 {
+			return fmt.Sprintf("NVARCHAR(%d)", col.CharMaxLength), nil
+		}
+		return "NVARCHAR(MAX)", nil
+	case "bpchar":
+		return fmt.Sprintf("NCHAR(%d)", max(col.CharMaxLength, 1)), nil
+	case "text", "citext", "tsvector", "json", "jsonb":
+		return "NVARCHAR(MAX)", nil
+	case "xml":
+		return "XML", nil
+	case "bytea":
+		return "VARBINARY(MAX)", nil
+	case "date":
+		return "DATE", nil
+	case "time":
+		return "TIME", nil
+	case "timetz":
+		return "TIME", []string{"time zone of timetz dropped"}
+	case "timestamp":
+		return "DATETIME2", nil
+	case "timestamptz":
+		return "DATETIMEOFFSET", nil
+	case "interval":
+		return "NVARCHAR(64)", []string{"interval stored as text"}
+	case "uuid":
+		return "UNIQUEIDENTIFIER", nil
+	case "inet", "cidr":
+		return "NVARCHAR(45)", nil
+	case "macaddr":
+		return "NVARCHAR(17)", nil
+	}
+	return "NVARCHAR(MAX)", []string{fmt.Sprintf("unknown type %s stored as NVARCHAR(MAX)", col.FullType)}
+}
+
+func (mssqlDialect) AutoIncrement(col Column) string { return "IDENTITY(1,1)" }
+
+func (mssqlDialect) BooleanLiteral(v bool) string {
+	if v {
+		return "1"
+	}
+	return "0"
+}
+
+// ReferentialAction drops RESTRICT, SQL Server doesn't know it and its
+// default NO ACTION behaves the same for non-deferred constraints.
+func (mssqlDialect) ReferentialAction(action string) string {
+	if action == "RESTRICT" {
+		return ""
+	}
+	return action
+}
+
+func (mssqlDialect) UUIDFunction() string { return "NEWID()" }
+
+// KeyColumnType bounds the MAX types, which SQL Server can't use in keys or
+// indexes, to the 900 byte limit of an index key.
+func (mssqlDialect) KeyColumnType(dataType string) string {
+	switch dataType {
+	case "NVARCHAR(MAX)":
+		return "NVARCHAR(450)"
+	case "VARBINARY(MAX)":
+		return "VARBINARY(900)"
+	}
+	return ""
+}
+
+func (mssqlDialect) ColumnDefault(dataType, def string) string { return def }
+
+func (mssqlDialect) SingleCascadePath() bool { return true }
+
+func (mssqlDialect) Function(name string) string {
+	switch name {
+	case "length", "char_length", "character_length":
+		return "LEN"
+	case "btrim", "trim":
+		return "TRIM"
+	case "floor":
+		return "FLOOR"
+	case "ceil", "ceiling":
+		return "CEILING"
+	}
+	return portableFunctions[name]
+}
+
+func (mssqlDialect) InlineEnumCheck() bool { return true }
+
+func (mssqlDialect) InlineForeignKeys() bool { return false }
+
+func (mssqlDialect) PartialIndexes() bool { return true }
+
+func (mssqlDialect) ColumnComment(comment string) string { return "" }
+
+func (mssqlDialect) TableOptions(t Table) string { return "" }
@@ -0,0 +1,155 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string { return "mysql" }
+
+func (mysqlDialect) QuoteIdent(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func (mysqlDialect) ColumnType(col Column, enum *Enum) (string, []string) {
+	if col.IsArray() {
+		return "JSON", []string{fmt.Sprintf("array type %s stored as JSON", col.FullType)}
+	}
+	if enum != nil {
+		values := make([]string, len(enum.Values))
+		for i, v := range enum.Values {
+			values[i] = quoteLiteral(v)
+		}
+		return "ENUM(" + strings.Join(values, ", ") + ")", nil
+	}
+	switch col.UDTName {
+	case "int2":
+		return "SMALLINT", nil
+	case "int4":
+		return "INT", nil
+	case "int8":
+		return "BIGINT", nil
+	case "float4":
+		return "FLOAT", nil
+	case "float8":
+		return "DOUBLE", nil
+	case "numeric":
+		if col.NumericPrecision > 0 {
+			return fmt.Sprintf("DECIMAL(%d,%d)", col.NumericPrecision, col.NumericScale), nil
+		}
+		return "DECIMAL(65,30)", []string{"unconstrained numeric mapped to DECIMAL(65,30)"}
+	case "money":
+		return "DECIMAL(19,4)", nil
+	case "bool":
+		return "BOOLEAN", nil
+	case "varchar":
+		if col.CharMaxLength > 0 {
+			return fmt.Sprintf("VARCHAR(%d)", col.CharMaxLength), nil
+		}
+		return "TEXT", nil
+	case "bpchar":
+		return fmt.Sprintf("CHAR(%d)", max(col.CharMaxLength, 1)), nil
+	case "text", "citext", "xml", "tsvector":
+		return "LONGTEXT", nil
+	case "bytea":
+		return "LONGBLOB", nil
+	case "date":
+		return "DATE", nil
+	case "time":
+		return "TIME(6)", nil
+	case "timetz":
+		return "TIME(6)", []string{"time zone of timetz dropped"}
+	case "timestamp":
+		return "DATETIME(6)", nil
+	case "timestamptz":
+		return "DATETIME(6)", []string{"timestamptz stored as DATETIME(6), values must be written in UTC"}
+	case "interval":
+		return "VARCHAR(64)", []string{"interval stored as text"}
+	case "uuid":
+		return "CHAR(36)", nil
+	case "json", "jsonb":
+		return "JSON", nil
+	case "inet", "cidr":
+		return "VARCHAR(45)", nil
+	case "macaddr":
+		return "VARCHAR(17)", nil
+	}
+	return "LONGTEXT", []string{fmt.Sprintf("unknown type %s stored as LONGTEXT", col.FullType)}
+}
+
+func (mysqlDialect) AutoIncrement(col Column) string { return "AUTO_INCREMENT" }
+
+func (mysqlDialect) BooleanLiteral(v bool) string {
+	if v {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+func (mysqlDialect) ReferentialAction(action string) string { return action }
+
+func (mysqlDialect) UUIDFunction() string { return "(UUID())" }
+
+// KeyColumnType bounds the text and blob types, which MySQL can only index
+// with a prefix length.
+func (mysqlDialect) KeyColumnType(dataType string) string {
+	switch dataType {
+	case "TEXT", "LONGTEXT":
+		return "VARCHAR(255)"
+	case "LONGBLOB":
+		return "VARBINARY(255)"
+	}
+	return ""
+}
+
+// ColumnDefault writes literal defaults of text, blob and JSON columns as
+// expressions, the only defaults MySQL 8.0.13 and later accept for them.
+func (mysqlDialect) ColumnDefault(dataType, def string) string {
+	switch dataType {
+	case "TEXT", "LONGTEXT", "LONGBLOB", "JSON":
+		if def != "NULL" && !strings.HasPrefix(def, "(") {
+			return "(" + def + ")"
+		}
+	}
+	return def
+}
+
+func (mysqlDialect) SingleCascadePath() bool { return false }
+
+// Function maps length to CHAR_LENGTH, Postgres counts characters where
+// MySQL's LENGTH counts bytes.
+func (mysqlDialect) Function(name string) string {
+	switch name {
+	case "length", "char_length", "character_length":
+		return "CHAR_LENGTH"
+	case "btrim", "trim":
+		return "TRIM"
+	case "floor":
+		return "FLOOR"
+	case "ceil", "ceiling":
+		return "CEIL"
+	case "round":
+		return "ROUND"
+	}
+	return portableFunctions[name]
+}
+
+func (mysqlDialect) InlineEnumCheck() bool { return false }
+
+func (mysqlDialect) InlineForeignKeys() bool { return false }
+
+func (mysqlDialect) PartialIndexes() bool { return false }
+
+func (mysqlDialect) ColumnComment(comment string) string {
+	return "COMMENT " + quoteLiteral(comment)
+}
+
+func (mysqlDialect) TableOptions(t Table) string {
+	options := " ENGINE=InnoDB DEFAULT CHARSET=utf8mb4"
+	if t.Comment != "" {
+		options += " COMMENT=" + quoteLiteral(t.Comment)
+	}
+	return options
+}
@@ -0,0 +1,104 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string { return "sqlite" }
+
+func (sqliteDialect) QuoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// ColumnType keeps readable declared types where SQLite derives the
+// intended affinity from them, ORMs reading the schema back rely on that.
+func (sqliteDialect) ColumnType(col Column, enum *Enum) (string, []string) {
+	if col.IsArray() {
+		return "TEXT", []string{fmt.Sprintf("array type %s stored as JSON text", col.FullType)}
+	}
+	if enum != nil {
+		return "TEXT", nil
+	}
+	switch col.UDTName {
+	case "int2", "int4", "int8":
+		// AUTOINCREMENT only works on a column declared exactly INTEGER.
+		return "INTEGER", nil
+	case "float4", "float8":
+		return "REAL", nil
+	case "numeric", "money":
+		if col.NumericPrecision > 0 {
+			return fmt.Sprintf("NUMERIC(%d,%d)", col.NumericPrecision, col.NumericScale), nil
+		}
+		return "NUMERIC", nil
+	case "bool":
+		return "BOOLEAN", nil
+	case "varchar":
+		if col.CharMaxLength > 0 {
+			return fmt.Sprintf("VARCHAR(%d)", col.CharMaxLength), nil
+		}
+		return "TEXT", nil
+	case "bpchar":
+		return fmt.Sprintf("CHAR(%d)", max(col.CharMaxLength, 1)), nil
+	case "text", "citext", "xml", "uuid", "json", "jsonb", "inet", "cidr", "macaddr", "tsvector":
+		return "TEXT", nil
+	case "bytea":
+		return "BLOB", nil
+	case "date":
+		return "DATE", nil
+	case "time", "timetz":
+		return "TIME", nil
+	case "timestamp", "timestamptz":
+		return "TIMESTAMP", nil
+	case "interval":
+		return "TEXT", []string{"interval stored as text"}
+	}
+	return "TEXT", []string{fmt.Sprintf("unknown type %s stored as TEXT", col.FullType)}
+}
+
+// AutoIncrement only applies to an INTEGER PRIMARY KEY column, the writer
+// moves the primary key inline when it sees the clause.
+func (sqliteDialect) AutoIncrement(col Column) string { return "PRIMARY KEY AUTOINCREMENT" }
+
+func (sqliteDialect) BooleanLiteral(v bool) string {
+	if v {
+		return "1"
+	}
+	return "0"
+}
+
+func (sqliteDialect) ReferentialAction(action string) string { return action }
+
+func (sqliteDialect) UUIDFunction() string { return "" }
+
+func (sqliteDialect) KeyColumnType(dataType string) string { return "" }
+
+func (sqliteDialect) ColumnDefault(dataType, def string) string { return def }
+
+func (sqliteDialect) SingleCascadePath() bool { return false }
+
+func (sqliteDialect) Function(name string) string {
+	switch name {
+	case "length", "char_length", "character_length":
+		return "LENGTH"
+	case "btrim", "trim":
+		return "TRIM"
+	case "round":
+		return "ROUND"
+	}
+	return portableFunctions[name]
+}
+
+func (sqliteDialect) InlineEnumCheck() bool { return true }
+
+// InlineForeignKeys is true because SQLite resolves references lazily and
+// has no ALTER TABLE ... ADD CONSTRAINT.
+func (sqliteDialect) InlineForeignKeys() bool { return true }
+
+func (sqliteDialect) PartialIndexes() bool { return true }
+
+func (sqliteDialect) ColumnComment(comment string) string { return "" }
+
+func (sqliteDialect) TableOptions(t Table) string { return "" }
@@ -0,0 +1,172 @@
+package internal
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTranslateDefault(t *testing.T) {
+	tests := []struct {
+		dialect string
+		expr    string
+		want    string
+		ok      bool
+	}{
+		{"mysql", "now()", "CURRENT_TIMESTAMP", true},
+		{"mysql", "(0)", "0", true},
+		{"mysql", "'x'::text", "'x'", true},
+		{"mysql", "('x'::text)", "'x'", true},
+		{"mysql", "'(x)'::text", "'(x)'", true},
+		{"mysql", "(-1.5)", "-1.5", true},
+		{"mysql", "true", "TRUE", true},
+		{"mysql", "gen_random_uuid()", "(UUID())", true},
+		{"mssql", "gen_random_uuid()", "NEWID()", true},
+		{"mssql", "false", "0", true},
+		{"sqlite", "gen_random_uuid()", "", false},
+		{"sqlite", "nextval('users_id_seq'::regclass)", "", false},
+	}
+	for _, tt := range tests {
+		dw := &ddlWriter{dialect: dialects[tt.dialect], schema: &Schema{}}
+		got, ok := dw.translateDefault(tt.expr)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("%s: translateDefault(%q) = %q, %v, want %q, %v", tt.dialect, tt.expr, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestTranslateExpression(t *testing.T) {
+	tests := []struct {
+		dialect string
+		expr    string
+		want    string
+		err     string
+	}{
+		{"mysql", "(type = ANY (ARRAY['post'::text, 'page'::text]))", "CHECK (`type` IN ('post', 'page'))", ""},
+		{"mssql", "((type)::text = ANY ((ARRAY['a'::character varying, 'b'::character varying])::text[]))", "CHECK (([type]) IN ('a', 'b'))", ""},
+		{"sqlite", "((rating >= 1) AND (rating <= 5))", `CHECK (("rating" >= 1) AND ("rating" <= 5))`, ""},
+		{"mssql", "(length((title)::text) > 0)", "CHECK (LEN(([title])) > 0)", ""},
+		{"mysql", "(length((title)::text) > 0)", "CHECK (CHAR_LENGTH((`title`)) > 0)", ""},
+		{"mssql", `(lower("Name") <> 'it''s'::text)`, "CHECK (LOWER([Name]) <> 'it''s')", ""},
+		{"sqlite", "(active = true)", `CHECK ("active" = 1)`, ""},
+		{"mysql", "(price IS NOT NULL)", "CHECK (`price` IS NOT NULL)", ""},
+		{"mssql", "valid_balance(balance)", "", "function valid_balance has no mssql equivalent"},
+		{"mysql", "(email ~* '^.+@.+$'::text)", "", "can't be translated"},
+		{"sqlite", "(tags @> ARRAY['x'::text])", "", "can't be translated"},
+	}
+	for _, tt := range tests {
+		dw := &ddlWriter{dialect: dialects[tt.dialect], schema: &Schema{}}
+		expr, err := dw.translateExpression(tt.expr)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: translateExpression(%q) error = %v, want %q", tt.dialect, tt.expr, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: translateExpression(%q): %v", tt.dialect, tt.expr, err)
+			continue
+		}
+		if got := checkClause(expr); got != tt.want {
+			t.Errorf("%s: checkClause(translateExpression(%q)) = %q, want %q", tt.dialect, tt.expr, got, tt.want)
+		}
+		if strings.Count(expr, "(") != strings.Count(expr, ")") {
+			t.Errorf("%s: translateExpression(%q) = %q has unbalanced parentheses", tt.dialect, tt.expr, expr)
+		}
+	}
+}
+
+func TestWriteDDL(t *testing.T) {
+	for _, dialect := range []string{"mysql", "sqlite", "mssql"} {
+		t.Run(dialect, func(t *testing.T) {
+			ddl, _ := renderDDL(t, dialect)
+			golden(t, "ddl/"+dialect+".sql", []byte(ddl))
+		})
+	}
+}
+
+func TestUnmappedFunctionCheckSkipped(t *testing.T) {
+	schema := blogSchemaWith(alterTable("users", func(t *Table) {
+		t.Checks = append(t.Checks, CheckConstraint{ConstraintName: "users_bio_check", Expression: "valid_bio(bio)"})
+	}))
+	var b strings.Builder
+	warnings, err := WriteDDL(&b, schema, "mysql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "-- WARN: check constraint users_bio_check on users skipped: function valid_bio has no mysql equivalent\nCREATE TABLE `users`"
+	if !strings.Contains(b.String(), want) {
+		t.Errorf("DDL doesn't warn about the skipped check before the table:\n%s", b.String())
+	}
+	if !slices.ContainsFunc(warnings, func(w string) bool { return strings.Contains(w, "users_bio_check") }) {
+		t.Errorf("warnings %q don't mention users_bio_check", warnings)
+	}
+}
+
+func renderDDL(t *testing.T, dialect string) (string, []string) {
+	t.Helper()
+	var b strings.Builder
+	warnings, err := WriteDDL(&b, blogSchema(), dialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b.String(), warnings
+}
+
+func TestMySQLKeyColumns(t *testing.T) {
+	ddl, _ := renderDDL(t, "mysql")
+	for _, want := range []string{
+		"`email` VARCHAR(255) NOT NULL",
+		"`name` VARCHAR(100) NULL",
+		"`bio` LONGTEXT DEFAULT ('') NOT NULL",
+		"`type` LONGTEXT DEFAULT ('post') NOT NULL",
+		"`created_at` DATETIME(6) DEFAULT CURRENT_TIMESTAMP NOT NULL",
+		"CONSTRAINT `posts_type_check` CHECK (`type` IN ('post', 'page'))",
+		"CONSTRAINT `posts_title_check` CHECK (CHAR_LENGTH((`title`)) > 0)",
+	} {
+		if !strings.Contains(ddl, want) {
+			t.Errorf("mysql DDL is missing %q:\n%s", want, ddl)
+		}
+	}
+}
+
+func TestMSSQLKeyColumnsAndCascades(t *testing.T) {
+	ddl, warnings := renderDDL(t, "mssql")
+	for _, want := range []string{
+		"[email] NVARCHAR(450) NOT NULL",
+		"[bio] NVARCHAR(MAX) DEFAULT '' NOT NULL",
+		"CONSTRAINT [posts_author_id_fkey] FOREIGN KEY ([author_id]) REFERENCES [users] ([id]) ON DELETE CASCADE",
+		"CONSTRAINT [posts_editor_id_fkey] FOREIGN KEY ([editor_id]) REFERENCES [users] ([id]),\n",
+		"CONSTRAINT [post_tags_post_id_fkey] FOREIGN KEY ([post_id]) REFERENCES [posts] ([id]) ON DELETE CASCADE",
+		"CONSTRAINT [post_tags_tag_id_fkey] FOREIGN KEY ([tag_id]) REFERENCES [tags] ([id]) ON DELETE CASCADE",
+	} {
+		if !strings.Contains(ddl, want) {
+			t.Errorf("mssql DDL is missing %q:\n%s", want, ddl)
+		}
+	}
+	downgraded := 0
+	for _, w := range warnings {
+		if strings.Contains(w, "replaced by NO ACTION") {
+			downgraded++
+			if !strings.Contains(w, "posts_editor_id_fkey") {
+				t.Errorf("unexpected downgrade: %s", w)
+			}
+		}
+	}
+	if downgraded != 1 {
+		t.Errorf("got %d downgraded foreign keys, want 1: %q", downgraded, warnings)
+	}
+}
+
+func TestLimitCascadesCycle(t *testing.T) {
+	schema := &Schema{Tables: []Table{
+		{Name: "employees", ForeignKeys: []ForeignKey{
+			{ConstraintName: "employees_manager_fkey", SourceTable: "employees", SourceColumns: []string{"manager_id"}, TargetTable: "employees", TargetColumns: []string{"id"}, OnDelete: "SET NULL"},
+		}},
+	}}
+	dw := &ddlWriter{dialect: dialects["mssql"], schema: schema}
+	dw.limitCascades()
+	if !dw.noCascade["employees.employees_manager_fkey"] {
+		t.Errorf("self-referencing SET NULL kept, want NO ACTION")
+	}
+}
@@ -0,0 +1,349 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type ChangeKind string
+
+const (
+	Added   ChangeKind = "added"
+	Removed ChangeKind = "removed"
+	Changed ChangeKind = "changed"
+)
+
+// Change records how one named object differs between two schemas. From is
+// nil for added objects and To is nil for removed ones.
+type Change[T any] struct {
+	Name string     `json:"name"`
+	Kind ChangeKind `json:"change"`
+	From *T         `json:"from,omitempty"`
+	To   *T         `json:"to,omitempty"`
+}
+
+type EnumChange struct {
+	Change[Enum]
+	AddedValues   []string `json:"added_values,omitempty"`
+	RemovedValues []string `json:"removed_values,omitempty"`
+}
+
+type ColumnChange struct {
+	Change[Column]
+	// Fields lists what differs on a changed column: "type", "nullable",
+	// "default", "identity" or "comment".
+	Fields []string `json:"fields,omitempty"`
+}
+
+type TableChange struct {
+	Change[Table]
+	CommentChanged bool                       `json:"comment_changed,omitempty"`
+	Columns        []ColumnChange             `json:"columns,omitempty"`
+	PrimaryKey     *Change[PrimaryKey]        `json:"primary_key,omitempty"`
+	Uniques        []Change[UniqueConstraint] `json:"uniques,omitempty"`
+	ForeignKeys    []Change[ForeignKey]       `json:"foreign_keys,omitempty"`
+	Checks         []Change[CheckConstraint]  `json:"checks,omitempty"`
+	Indexes        []Change[Index]            `json:"indexes,omitempty"`
+}
+
+// SchemaDiff lists every difference between two schema models.
+type SchemaDiff struct {
+	Enums     []EnumChange       `json:"enums,omitempty"`
+	Functions []Change[Function] `json:"functions,omitempty"`
+	Tables    []TableChange      `json:"tables,omitempty"`
+	Views     []Change[View]     `json:"views,omitempty"`
+}
+
+// Empty reports whether both schemas are the same.
+func (d *SchemaDiff) Empty() bool {
+	return len(d.Enums) == 0 && len(d.Functions) == 0 && len(d.Tables) == 0 && len(d.Views) == 0
+}
+
+// DiffSchemas compares two schema models, from being the old state and to
+// the new one. Objects are matched by name.
+func DiffSchemas(from, to *Schema) *SchemaDiff {
+	diff := &SchemaDiff{}
+
+	for _, c := range diffByName(from.Enums, to.Enums, func(e Enum) string { return e.Name },
+		func(a, b Enum) bool { return slices.Equal(a.Values, b.Values) }) {
+		ec := EnumChange{Change: c}
+		if c.Kind == Changed {
+			ec.AddedValues = missingFrom(c.To.Values, c.From.Values)
+			ec.RemovedValues = missingFrom(c.From.Values, c.To.Values)
+		}
+		diff.Enums = append(diff.Enums, ec)
+	}
+
+	diff.Functions = diffByName(from.Functions, to.Functions,
+		func(f Function) string { return f.Name + "(" + f.Arguments + ")" },
+		func(a, b Function) bool { return normalizeSQL(a.Definition) == normalizeSQL(b.Definition) })
+
+	for _, c := range diffByName(from.Tables, to.Tables, func(t Table) string { return t.Name }, nil) {
+		if c.Kind != Changed {
+			diff.Tables = append(diff.Tables, TableChange{Change: c})
+			continue
+		}
+		if tc := diffTable(c.From, c.To); tc != nil {
+			diff.Tables = append(diff.Tables, *tc)
+		}
+	}
+
+	diff.Views = diffByName(from.Views, to.Views, func(v View) string { return v.Name },
+		func(a, b View) bool {
+			return a.Materialized == b.Materialized && normalizeSQL(a.Definition) == normalizeSQL(b.Definition)
+		})
+	return diff
+}
+
+func diffTable(from, to *Table) *TableChange {
+	tc := &TableChange{Change: Change[Table]{Name: to.Name, Kind: Changed, From: from, To: to}}
+	tc.CommentChanged = from.Comment != to.Comment
+
+	for _, c := range diffByName(from.Columns, to.Columns, func(c Column) string { return c.ColumnName }, nil) {
+		cc := ColumnChange{Change: c}
+		if c.Kind == Changed {
+			cc.Fields = columnFieldChanges(*c.From, *c.To)
+			if len(cc.Fields) == 0 {
+				continue
+			}
+		}
+		tc.Columns = append(tc.Columns, cc)
+	}
+
+	switch {
+	case from.PrimaryKey == nil && to.PrimaryKey != nil:
+		tc.PrimaryKey = &Change[PrimaryKey]{Name: to.PrimaryKey.ConstraintName, Kind: Added, To: to.PrimaryKey}
+	case from.PrimaryKey != nil && to.PrimaryKey == nil:
+		tc.PrimaryKey = &Change[PrimaryKey]{Name: from.PrimaryKey.ConstraintName, Kind: Removed, From: from.PrimaryKey}
+	case from.PrimaryKey != nil && !slices.Equal(from.PrimaryKey.Columns, to.PrimaryKey.Columns):
+		tc.PrimaryKey = &Change[PrimaryKey]{Name: to.PrimaryKey.ConstraintName, Kind: Changed, From: from.PrimaryKey, To: to.PrimaryKey}
+	}
+
+	tc.Uniques = diffByName(from.Uniques, to.Uniques,
+		func(u UniqueConstraint) string { return u.ConstraintName },
+		func(a, b UniqueConstraint) bool { return slices.Equal(a.Columns, b.Columns) })
+	tc.ForeignKeys = diffByName(from.ForeignKeys, to.ForeignKeys,
+		func(fk ForeignKey) string { return fk.ConstraintName },
+		func(a, b ForeignKey) bool {
+			return a.TargetTable == b.TargetTable && a.OnDelete == b.OnDelete && a.OnUpdate == b.OnUpdate &&
+				slices.Equal(a.SourceColumns, b.SourceColumns) && slices.Equal(a.TargetColumns, b.TargetColumns)
+		})
+	tc.Checks = diffByName(from.Checks, to.Checks,
+		func(c CheckConstraint) string { return c.ConstraintName },
+		func(a, b CheckConstraint) bool { return normalizeSQL(a.Expression) == normalizeSQL(b.Expression) })
+	tc.Indexes = diffByName(from.Indexes, to.Indexes,
+		func(idx Index) string { return idx.Name },
+		func(a, b Index) bool {
+			return a.Unique == b.Unique && a.Method == b.Method && a.Where == b.Where && slices.Equal(a.Columns, b.Columns)
+		})
+
+	if !tc.CommentChanged && len(tc.Columns) == 0 && tc.PrimaryKey == nil && len(tc.Uniques) == 0 &&
+		len(tc.ForeignKeys) == 0 && len(tc.Checks) == 0 && len(tc.Indexes) == 0 {
+		return nil
+	}
+	return tc
+}
+
+func columnFieldChanges(from, to Column) []string {
+	var fields []string
+	if from.FullType != to.FullType {
+		fields = append(fields, "type")
+	}
+	if from.IsNullable != to.IsNullable {
+		fields = append(fields, "nullable")
+	}
+	if from.Default != to.Default {
+		fields = append(fields, "default")
+	}
+	if from.IsIdentity != to.IsIdentity || from.IdentityGeneration != to.IdentityGeneration {
+		fields = append(fields, "identity")
+	}
+	if from.Comment != to.Comment {
+		fields = append(fields, "comment")
+	}
+	return fields
+}
+
+// diffByName matches the objects of two lists by name. Objects present on
+// both sides are reported as changed when equal returns false; a nil equal
+// reports every such pair, leaving the comparison to the caller. Removed
+// objects come first, then the rest in the order of the new list.
+func diffByName[T any](from, to []T, name func(T) string, equal func(a, b T) bool) []Change[T] {
+	var changes []Change[T]
+	toByName := make(map[string]*T, len(to))
+	for i := range to {
+		toByName[name(to[i])] = &to[i]
+	}
+	fromByName := make(map[string]*T, len(from))
+	for i := range from {
+		n := name(from[i])
+		fromByName[n] = &from[i]
+		if toByName[n] == nil {
+			changes = append(changes, Change[T]{Name: n, Kind: Removed, From: &from[i]})
+		}
+	}
+	for i := range to {
+		n := name(to[i])
+		old := fromByName[n]
+		switch {
+		case old == nil:
+			changes = append(changes, Change[T]{Name: n, Kind: Added, To: &to[i]})
+		case equal == nil || !equal(*old, to[i]):
+			changes = append(changes, Change[T]{Name: n, Kind: Changed, From: old, To: &to[i]})
+		}
+	}
+	return changes
+}
+
+// missingFrom returns the values of a that are not in b.
+func missingFrom(a, b []string) []string {
+	var out []string
+	for _, v := range a {
+		if !slices.Contains(b, v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// normalizeSQL collapses whitespace so formatting differences between
+// server versions don't show up as changes.
+func normalizeSQL(s string) string {
+	return strings.TrimSuffix(strings.Join(strings.Fields(s), " "), ";")
+}
+
+// JSON returns the diff as indented JSON.
+func (d *SchemaDiff) JSON() ([]byte, error) {
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode diff: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+var changeMarks = map[ChangeKind]string{Added: "+", Removed: "-", Changed: "~"}
+
+// Report returns a human readable summary of the diff, one line per change.
+// Added objects are marked with +, removed ones with - and changed ones with ~.
+func (d *SchemaDiff) Report() string {
+	if d.Empty() {
+		return "No differences\n"
+	}
+	var b strings.Builder
+	for _, e := range d.Enums {
+		fmt.Fprintf(&b, "%s type %s", changeMarks[e.Kind], e.Name)
+		if e.Kind == Changed {
+			if len(e.AddedValues) > 0 {
+				fmt.Fprintf(&b, ", values added: %s", strings.Join(e.AddedValues, ", "))
+			}
+			if len(e.RemovedValues) > 0 {
+				fmt.Fprintf(&b, ", values removed: %s", strings.Join(e.RemovedValues, ", "))
+			}
+			if len(e.AddedValues) == 0 && len(e.RemovedValues) == 0 {
+				b.WriteString(", values reordered")
+			}
+		}
+		b.WriteString("\n")
+	}
+	for _, f := range d.Functions {
+		fmt.Fprintf(&b, "%s function %s\n", changeMarks[f.Kind], f.Name)
+	}
+	for _, t := range d.Tables {
+		fmt.Fprintf(&b, "%s table %s\n", changeMarks[t.Kind], t.Name)
+		if t.Kind != Changed {
+			continue
+		}
+		if t.CommentChanged {
+			fmt.Fprintf(&b, "    ~ comment %q -> %q\n", t.From.Comment, t.To.Comment)
+		}
+		for _, c := range t.Columns {
+			switch c.Kind {
+			case Added:
+				fmt.Fprintf(&b, "    + column %s\n", renderColumn(*c.To))
+			case Removed:
+				fmt.Fprintf(&b, "    - column %s\n", c.Name)
+			default:
+				fmt.Fprintf(&b, "    ~ column %s: %s\n", c.Name, describeColumnChange(c))
+			}
+		}
+		if pk := t.PrimaryKey; pk != nil {
+			fmt.Fprintf(&b, "    %s primary key %s\n", changeMarks[pk.Kind], describeChange(pk.From, pk.To, func(p PrimaryKey) string {
+				return "(" + strings.Join(p.Columns, ", ") + ")"
+			}))
+		}
+		for _, u := range t.Uniques {
+			fmt.Fprintf(&b, "    %s unique %s %s\n", changeMarks[u.Kind], u.Name, describeChange(u.From, u.To, func(u UniqueConstraint) string {
+				return "(" + strings.Join(u.Columns, ", ") + ")"
+			}))
+		}
+		for _, fk := range t.ForeignKeys {
+			fmt.Fprintf(&b, "    %s foreign key %s %s\n", changeMarks[fk.Kind], fk.Name, describeChange(fk.From, fk.To, renderForeignKeyTarget))
+		}
+		for _, c := range t.Checks {
+			fmt.Fprintf(&b, "    %s check %s %s\n", changeMarks[c.Kind], c.Name, describeChange(c.From, c.To, func(c CheckConstraint) string {
+				return c.Expression
+			}))
+		}
+		for _, idx := range t.Indexes {
+			fmt.Fprintf(&b, "    %s index %s %s\n", changeMarks[idx.Kind], idx.Name, describeChange(idx.From, idx.To, func(idx Index) string {
+				return idx.Definition
+			}))
+		}
+	}
+	for _, v := range d.Views {
+		fmt.Fprintf(&b, "%s view %s\n", changeMarks[v.Kind], v.Name)
+	}
+	return b.String()
+}
+
+// describeChange renders the new value of an added object, the old value
+// of a removed one and both of a changed one.
+func describeChange[T any](from, to *T, render func(T) string) string {
+	switch {
+	case from == nil:
+		return render(*to)
+	case to == nil:
+		return render(*from)
+	default:
+		return render(*from) + " -> " + render(*to)
+	}
+}
+
+func describeColumnChange(c ColumnChange) string {
+	var parts []string
+	for _, field := range c.Fields {
+		switch field {
+		case "type":
+			parts = append(parts, fmt.Sprintf("type %s -> %s", c.From.FullType, c.To.FullType))
+		case "nullable":
+			parts = append(parts, fmt.Sprintf("nullable %s -> %s", c.From.IsNullable, c.To.IsNullable))
+		case "default":
+			parts = append(parts, fmt.Sprintf("default %s -> %s", displayValue(c.From.Default), displayValue(c.To.Default)))
+		case "identity":
+			parts = append(parts, fmt.Sprintf("identity %s -> %s", displayValue(c.From.IdentityGeneration), displayValue(c.To.IdentityGeneration)))
+		case "comment":
+			parts = append(parts, fmt.Sprintf("comment %q -> %q", c.From.Comment, c.To.Comment))
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+func renderForeignKeyTarget(fk ForeignKey) string {
+	s := fmt.Sprintf("(%s) REFERENCES %s(%s)", strings.Join(fk.SourceColumns, ", "), fk.TargetTable, strings.Join(fk.TargetColumns, ", "))
+	if fk.OnDelete != "" && fk.OnDelete != "NO ACTION" {
+		s += " ON DELETE " + fk.OnDelete
+	}
+	if fk.OnUpdate != "" && fk.OnUpdate != "NO ACTION" {
+		s += " ON UPDATE " + fk.OnUpdate
+	}
+	return s
+}
+
+func displayValue(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
@@ -0,0 +1,137 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// diffSummary lists a diff's changes as "kind object" lines, with the
+// changed fields of columns.
+func diffSummary(d *SchemaDiff) []string {
+	var lines []string
+	for _, e := range d.Enums {
+		lines = append(lines, string(e.Kind)+" enum "+e.Name)
+	}
+	for _, t := range d.Tables {
+		lines = append(lines, string(t.Kind)+" table "+t.Name)
+		for _, c := range t.Columns {
+			line := string(c.Kind) + " column " + t.Name + "." + c.Name
+			for _, field := range c.Fields {
+				line += " " + field
+			}
+			lines = append(lines, line)
+		}
+		for _, c := range t.Checks {
+			lines = append(lines, string(c.Kind)+" check "+c.Name)
+		}
+		for _, fk := range t.ForeignKeys {
+			lines = append(lines, string(fk.Kind)+" foreign key "+fk.Name)
+		}
+		for _, idx := range t.Indexes {
+			lines = append(lines, string(idx.Kind)+" index "+idx.Name)
+		}
+	}
+	return lines
+}
+
+func TestDiffSchemas(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(s *Schema)
+		want   []string
+	}{
+		{
+			name:   "identical",
+			change: func(s *Schema) {},
+		},
+		{
+			name: "check reformatted only",
+			change: alterTable("posts", func(t *Table) {
+				t.Checks[1].Expression = "((rating >= 1)  AND\n(rating <= 5))"
+			}),
+		},
+		{
+			name: "column type and nullability",
+			change: alterColumn("posts", "rating", func(c *Column) {
+				c.FullType, c.IsNullable = "bigint", "NO"
+			}),
+			want: []string{"changed table posts", "changed column posts.rating type nullable"},
+		},
+		{
+			name: "column and table added",
+			change: func(s *Schema) {
+				addColumn("users", column("avatar", "text", true))(s)
+				addTable(commentsTable())(s)
+			},
+			want: []string{"changed table users", "added column users.avatar", "added table comments"},
+		},
+		{
+			name:   "column removed",
+			change: dropColumn("users", "bio"),
+			want:   []string{"changed table users", "removed column users.bio"},
+		},
+		{
+			name: "foreign key action changed",
+			change: alterTable("posts", func(t *Table) {
+				t.ForeignKeys[0].OnDelete = "RESTRICT"
+			}),
+			want: []string{"changed table posts", "changed foreign key posts_author_id_fkey"},
+		},
+		{
+			name:   "table removed",
+			change: dropTable("post_tags"),
+			want:   []string{"removed table post_tags"},
+		},
+		{
+			name:   "enum value added",
+			change: addEnumValues("post_status", "archived"),
+			want:   []string{"changed enum post_status"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := DiffSchemas(blogSchema(), blogSchemaWith(tt.change))
+			if got := diffSummary(diff); !slices.Equal(got, tt.want) {
+				t.Errorf("diff = %q, want %q", got, tt.want)
+			}
+			if diff.Empty() != (len(tt.want) == 0) {
+				t.Errorf("Empty() = %v", diff.Empty())
+			}
+		})
+	}
+}
+
+func TestDiffReport(t *testing.T) {
+	diff := DiffSchemas(blogSchema(), blogSchemaWith(
+		addColumn("users", column("avatar", "text", true)),
+		alterColumn("posts", "rating", func(c *Column) { c.DataType, c.UDTName, c.FullType = "bigint", "int8", "bigint" }),
+		dropTable("post_tags"),
+		addEnumValues("post_status", "archived"),
+	))
+	golden(t, "diff/report.txt", []byte(diff.Report()))
+	data, err := diff.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden(t, "diff/report.json", data)
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	data, err := MarshalSnapshot(blogSchema())
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "schema.json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	schema, err := LoadSchema(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := DiffSchemas(blogSchema(), schema); !diff.Empty() {
+		t.Errorf("snapshot differs from the schema: %q", diffSummary(diff))
+	}
+}
@@ -0,0 +1,301 @@
+package internal
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// GenerateOptions tune the code generators.
+type GenerateOptions struct {
+	// Package is the package, module or namespace of the generated code.
+	Package string
+	// Managed sets Django's Meta.managed, letting Django migrations create
+	// and alter the tables.
+	Managed bool
+}
+
+// Generator renders the schema as source files of a language or framework.
+// Unlike the LLM backed transform, generators are offline and deterministic:
+// the same schema always gives the same files.
+type Generator func(schema *Schema, opts GenerateOptions) ([]File, error)
+
+// generators maps a language to its frameworks, defaultFrameworks names
+// the one used when none is given.
+var generators = map[string]map[string]Generator{
+	"go":     {"gorm": generateGORM, "ent": generateEnt},
+	"py":     {"django": generateDjango, "sqlalchemy": generateSQLAlchemy, "pydantic": generatePydantic},
+	"ts":     {"typeorm": generateTypeORM, "drizzle": generateDrizzle, "kysely": generateKysely, "zod": generateZod},
+	"rs":     {"diesel": generateDiesel, "seaorm": generateSeaORM, "sqlx": generateSQLx},
+	"prisma": {"prisma": generatePrisma},
+	"java":   {"jpa": generateJPA, "spring-jdbc": generateSpringJDBC},
+	"kt":     {"exposed": generateExposed},
+	"cs":     {"efcore": generateEFCore},
+}
+
+var defaultFrameworks = map[string]string{
+	"go":     "gorm",
+	"py":     "django",
+	"ts":     "typeorm",
+	"rs":     "diesel",
+	"prisma": "prisma",
+	"java":   "jpa",
+	"kt":     "exposed",
+	"cs":     "efcore",
+}
+
+// GeneratorFor returns the generator of a language and framework. An empty
+// framework picks the language's default. ok is false when no offline
+// generator exists.
+func GeneratorFor(lang, framework string) (Generator, bool) {
+	if framework == "" {
+		framework = defaultFrameworks[lang]
+	}
+	generate, ok := generators[lang][framework]
+	return generate, ok
+}
+
+// Frameworks lists the frameworks with an offline generator for a language.
+func Frameworks(lang string) []string {
+	var frameworks []string
+	for framework := range generators[lang] {
+		frameworks = append(frameworks, framework)
+	}
+	sort.Strings(frameworks)
+	return frameworks
+}
+
+// GenerateModels introspects the database and writes the models of the
+// offline generator for lang and framework. An empty tableName generates
+// every table.
+func GenerateModels(db *sql.DB, lang, framework, tableName string, opts GenerateOptions, out Output) error {
+	generate, ok := GeneratorFor(lang, framework)
+	if !ok {
+		return fmt.Errorf("no generator for %s %s", lang, framework)
+	}
+	schema, err := IntrospectPostgres(db, DefaultSchema)
+	if err != nil {
+		return err
+	}
+	if tableName != "" {
+		if schema.Table(tableName) == nil {
+			return fmt.Errorf("table %s not found", tableName)
+		}
+		schema = schema.Filter(tableName)
+	}
+	files, err := generate(schema, opts)
+	if err != nil {
+		return err
+	}
+	return out.Write(files)
+}
+
+// Relation is one side of a foreign key as seen from a table: the owning
+// side holds the foreign key columns, the inverse side is the referenced
+// table listing the rows pointing at it.
+type Relation struct {
+	ForeignKey ForeignKey
+	// Name is the snake_case name of the relation field, e.g. "author" for
+	// posts.author_id or "posts" on the referenced users table.
+	Name string
+	// Table declares the relation field, Other is the related table.
+	Table string
+	Other string
+	// Owner is set on the side holding the foreign key columns.
+	Owner bool
+	// Many is set on the inverse side unless the foreign key columns are
+	// unique, which makes it a one-to-one relation.
+	Many bool
+}
+
+// Relations returns the relations of a table: one for each of its foreign
+// keys and one for each foreign key of another table referencing it.
+// Names avoid the table's column names and each other.
+func (s *Schema) Relations(tableName string) []Relation {
+	t := s.Table(tableName)
+	if t == nil {
+		return nil
+	}
+	taken := make(map[string]bool)
+	for _, c := range t.Columns {
+		taken[c.ColumnName] = true
+	}
+	name := func(base string) string {
+		for taken[base] {
+			base += "_ref"
+		}
+		taken[base] = true
+		return base
+	}
+
+	var relations []Relation
+	for _, fk := range t.ForeignKeys {
+		// Models generated for a single table have no referenced tables.
+		if s.Table(fk.TargetTable) == nil {
+			continue
+		}
+		relations = append(relations, Relation{
+			ForeignKey: fk,
+			Name:       name(ownerRelationName(fk)),
+			Table:      t.Name,
+			Other:      fk.TargetTable,
+			Owner:      true,
+		})
+	}
+	for _, source := range s.Tables {
+		for _, fk := range source.ForeignKeys {
+			if fk.TargetTable != t.Name {
+				continue
+			}
+			one := isUniqueColumns(&source, fk.SourceColumns)
+			base := plural(singular(source.Name))
+			if one {
+				base = singular(source.Name)
+			}
+			// Several foreign keys between the same tables, or one to
+			// itself, are told apart by the owning side's name.
+			parallel := 0
+			for _, other := range source.ForeignKeys {
+				if other.TargetTable == t.Name {
+					parallel++
+				}
+			}
+			if parallel > 1 || source.Name == t.Name {
+				base = ownerRelationName(fk) + "_" + base
+			}
+			relations = append(relations, Relation{
+				ForeignKey: fk,
+				Name:       name(base),
+				Table:      t.Name,
+				Other:      source.Name,
+				Many:       !one,
+			})
+		}
+	}
+	return relations
+}
+
+// ownerRelationName derives the relation name from the foreign key column,
+// "author_id" gives "author", or uses the referenced table.
+func ownerRelationName(fk ForeignKey) string {
+	if len(fk.SourceColumns) == 1 {
+		column := fk.SourceColumns[0]
+		for _, suffix := range []string{"_id", "_uuid", "_key", "_fk"} {
+			if base, ok := strings.CutSuffix(column, suffix); ok && base != "" {
+				return base
+			}
+		}
+		if base, ok := strings.CutSuffix(column, "Id"); ok && base != "" {
+			return snakeCase(base)
+		}
+	}
+	return singular(fk.TargetTable)
+}
+
+// isUniqueColumns reports whether the columns are the primary key or a
+// unique constraint of the table.
+func isUniqueColumns(t *Table, columns []string) bool {
+	if t.PrimaryKey != nil && slices.Equal(sortedCopy(t.PrimaryKey.Columns), sortedCopy(columns)) {
+		return true
+	}
+	for _, u := range t.Uniques {
+		if slices.Equal(sortedCopy(u.Columns), sortedCopy(columns)) {
+			return true
+		}
+	}
+	for _, idx := range t.Indexes {
+		if idx.Unique && idx.Where == "" && slices.Equal(sortedCopy(idx.Columns), sortedCopy(columns)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAmbiguous reports whether a foreign key references its own table or
+// is one of several joining the same two tables, in either direction, so
+// relations have to say which foreign key they use.
+func (s *Schema) isAmbiguous(fk ForeignKey) bool {
+	if fk.SourceTable == fk.TargetTable {
+		return true
+	}
+	joins := 0
+	for _, pair := range [][2]string{{fk.SourceTable, fk.TargetTable}, {fk.TargetTable, fk.SourceTable}} {
+		t := s.Table(pair[0])
+		if t == nil {
+			continue
+		}
+		for _, other := range t.ForeignKeys {
+			if other.TargetTable == pair[1] {
+				joins++
+			}
+		}
+	}
+	return joins > 1
+}
+
+func sortedCopy(values []string) []string {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	return sorted
+}
+
+// DefaultKind classifies a column default for the generators.
+type DefaultKind int
+
+const (
+	NoDefault DefaultKind = iota
+	StringDefault
+	NumberDefault
+	BoolDefault
+	// NowDefault is the current time or date, e.g. now() or CURRENT_DATE.
+	NowDefault
+	// UUIDDefault is a random UUID, e.g. gen_random_uuid().
+	UUIDDefault
+	// ExpressionDefault is any other SQL expression, kept as written.
+	ExpressionDefault
+)
+
+var (
+	trailingCast  = regexp.MustCompile(`::[a-zA-Z_][\w ."]*(\[\])?(\(\d+(,\s*\d+)?\))?$`)
+	numberLiteral = regexp.MustCompile(`^-?\d+(\.\d+)?([eE][-+]?\d+)?$`)
+	nowDefaults   = regexp.MustCompile(`(?i)^(now\(\)|current_timestamp(\(\d\))?|current_date|localtimestamp(\(\d\))?|statement_timestamp\(\)|transaction_timestamp\(\)|clock_timestamp\(\))$`)
+	uuidDefaults  = regexp.MustCompile(`(?i)^(gen_random_uuid|uuid_generate_v4)\(\)$`)
+)
+
+// parseDefault classifies a column default and returns its literal value:
+// the unquoted string, the number or "true"/"false". Casts the database
+// adds, like 'active'::status, are dropped.
+func parseDefault(def string) (DefaultKind, string) {
+	value := strings.TrimSpace(def)
+	if value == "" || strings.EqualFold(value, "NULL") {
+		return NoDefault, ""
+	}
+	for {
+		stripped := trailingCast.ReplaceAllString(value, "")
+		if stripped == value || strings.Count(stripped, "'")%2 != 0 {
+			break
+		}
+		value = strings.TrimSpace(stripped)
+	}
+	for strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")") && numberLiteral.MatchString(value[1:len(value)-1]) {
+		value = value[1 : len(value)-1]
+	}
+	switch {
+	case strings.EqualFold(value, "NULL"):
+		return NoDefault, ""
+	case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' && !strings.Contains(strings.ReplaceAll(value[1:len(value)-1], "''", ""), "'"):
+		return StringDefault, strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+	case numberLiteral.MatchString(value):
+		return NumberDefault, value
+	case strings.EqualFold(value, "true"), strings.EqualFold(value, "false"):
+		return BoolDefault, strings.ToLower(value)
+	case nowDefaults.MatchString(value):
+		return NowDefault, value
+	case uuidDefaults.MatchString(value):
+		return UUIDDefault, value
+	}
+	return ExpressionDefault, strings.TrimSpace(def)
+}
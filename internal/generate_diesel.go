@@ -0,0 +1,365 @@
+package internal
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var rustKeywords = map[string]bool{
+	"as": true, "async": true, "await": true, "break": true, "const": true, "continue": true,
+	"crate": true, "dyn": true, "else": true, "enum": true, "extern": true, "false": true,
+	"fn": true, "for": true, "if": true, "impl": true, "in": true, "let": true, "loop": true,
+	"match": true, "mod": true, "move": true, "mut": true, "pub": true, "ref": true,
+	"return": true, "self": true, "Self": true, "static": true, "struct": true, "super": true,
+	"trait": true, "true": true, "type": true, "unsafe": true, "use": true, "where": true,
+	"while": true, "abstract": true, "become": true, "box": true, "do": true, "final": true,
+	"macro": true, "override": true, "priv": true, "typeof": true, "unsized": true,
+	"virtual": true, "yield": true, "try": true, "gen": true,
+}
+
+// dieselTypes maps udt names to Diesel's SQL types and the Rust types they
+// load into.
+var dieselTypes = map[string][2]string{
+	"int2":        {"Int2", "i16"},
+	"int4":        {"Int4", "i32"},
+	"int8":        {"Int8", "i64"},
+	"float4":      {"Float4", "f32"},
+	"float8":      {"Float8", "f64"},
+	"numeric":     {"Numeric", "bigdecimal::BigDecimal"},
+	"bool":        {"Bool", "bool"},
+	"varchar":     {"Varchar", "String"},
+	"bpchar":      {"Bpchar", "String"},
+	"text":        {"Text", "String"},
+	"citext":      {"Citext", "String"},
+	"uuid":        {"Uuid", "uuid::Uuid"},
+	"json":        {"Json", "serde_json::Value"},
+	"jsonb":       {"Jsonb", "serde_json::Value"},
+	"date":        {"Date", "chrono::NaiveDate"},
+	"timestamp":   {"Timestamp", "chrono::NaiveDateTime"},
+	"timestamptz": {"Timestamptz", "chrono::DateTime<chrono::Utc>"},
+	"time":        {"Time", "chrono::NaiveTime"},
+	"interval":    {"Interval", "diesel::pg::data_types::PgInterval"},
+	"bytea":       {"Bytea", "Vec<u8>"},
+	"inet":        {"Inet", "ipnetwork::IpNetwork"},
+	"cidr":        {"Cidr", "ipnetwork::IpNetwork"},
+	"macaddr":     {"MacAddr", "[u8; 6]"},
+	"money":       {"Money", "diesel::pg::data_types::PgMoney"},
+	"oid":         {"Oid", "u32"},
+}
+
+// rustIdent turns a database name into a snake_case Rust identifier.
+func rustIdent(name string) string {
+	ident := snakeCase(name)
+	if ident == "" || ident[0] >= '0' && ident[0] <= '9' {
+		ident = "_" + ident
+	}
+	if rustKeywords[ident] {
+		ident += "_"
+	}
+	return ident
+}
+
+type dieselWriter struct {
+	schema *Schema
+	// tables maps the database names of the tables with a primary key to
+	// their identifiers in table!, columns each table's columns to theirs.
+	tables  map[string]string
+	columns map[string]map[string]string
+	// sqlTypes maps enum and other custom types to their SqlType struct in
+	// schema::sql_types, enums the enum types to their Rust enums.
+	sqlTypes map[string]string
+	enums    map[string]string
+}
+
+// generateDiesel renders the schema as a Diesel schema.rs, the way diesel
+// print-schema does, and a models.rs with a Queryable struct and an
+// Insertable New struct per table. Enums load into Rust enums deriving
+// diesel_derive_enum's DbEnum.
+func generateDiesel(schema *Schema, opts GenerateOptions) ([]File, error) {
+	w := &dieselWriter{
+		schema:   schema,
+		tables:   make(map[string]string),
+		columns:  make(map[string]map[string]string),
+		sqlTypes: make(map[string]string),
+		enums:    make(map[string]string),
+	}
+	structs := make(map[string]bool)
+	for _, t := range schema.Tables {
+		structs[className(t.Name)] = true
+	}
+	for _, e := range schema.Enums {
+		name := className(e.Name)
+		for structs[name] {
+			name += "Enum"
+		}
+		w.enums[e.Name] = name
+	}
+	var skipped []string
+	for _, t := range schema.Tables {
+		// Diesel needs a primary key to declare a table.
+		if t.PrimaryKey == nil {
+			skipped = append(skipped, t.Name)
+			continue
+		}
+		w.tables[t.Name] = rustIdent(t.Name)
+		w.columns[t.Name] = make(map[string]string)
+		taken := make(map[string]bool)
+		for _, col := range t.Columns {
+			name := rustIdent(col.ColumnName)
+			for taken[name] {
+				name += "_"
+			}
+			taken[name] = true
+			w.columns[t.Name][col.ColumnName] = name
+		}
+		for _, col := range t.Columns {
+			udt := col.ElementType()
+			if _, ok := dieselTypes[udt]; !ok {
+				w.sqlTypes[udt] = pascalCase(udt)
+			}
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("// Generated by schema transform. Do not edit.\n")
+	if len(w.sqlTypes) > 0 {
+		b.WriteString("\npub mod sql_types {\n")
+		for i, udt := range w.sortedSQLTypes() {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			b.WriteString("    #[derive(diesel::query_builder::QueryId, Clone, diesel::sql_types::SqlType)]\n")
+			fmt.Fprintf(&b, "    #[diesel(postgres_type(name = %q))]\n", udt)
+			fmt.Fprintf(&b, "    pub struct %s;\n", w.sqlTypes[udt])
+		}
+		b.WriteString("}\n")
+	}
+	var names []string
+	for _, t := range schema.Tables {
+		if _, ok := w.tables[t.Name]; ok {
+			b.WriteString("\n")
+			b.WriteString(w.table(&t))
+			names = append(names, w.tables[t.Name])
+		}
+	}
+
+	var joins []string
+	for _, t := range schema.Tables {
+		for _, fk := range t.ForeignKeys {
+			// joinable! needs a single column pointing at the primary key,
+			// and only one such foreign key between the tables.
+			target := schema.Table(fk.TargetTable)
+			if w.tables[t.Name] == "" || target == nil || w.tables[target.Name] == "" || target.Name == t.Name ||
+				len(fk.SourceColumns) != 1 || !slices.Equal(target.PrimaryKey.Columns, fk.TargetColumns) || schema.isAmbiguous(fk) {
+				continue
+			}
+			joins = append(joins, fmt.Sprintf("diesel::joinable!(%s -> %s (%s));\n", w.tables[t.Name], w.tables[target.Name], w.columns[t.Name][fk.SourceColumns[0]]))
+		}
+	}
+	slices.Sort(joins)
+	if len(joins) > 0 {
+		b.WriteString("\n" + strings.Join(joins, ""))
+	}
+	if len(names) > 1 {
+		slices.Sort(names)
+		fmt.Fprintf(&b, "\ndiesel::allow_tables_to_appear_in_same_query!(\n    %s,\n);\n", strings.Join(names, ",\n    "))
+	}
+	if len(skipped) > 0 {
+		fmt.Fprintf(&b, "\n// Tables without a primary key, which Diesel can't declare: %s\n", strings.Join(skipped, ", "))
+	}
+
+	return []File{
+		{Name: "schema.rs", Content: []byte(b.String())},
+		{Name: "models.rs", Content: []byte(w.models())},
+	}, nil
+}
+
+func (w *dieselWriter) sortedSQLTypes() []string {
+	var types []string
+	for udt := range w.sqlTypes {
+		types = append(types, udt)
+	}
+	slices.Sort(types)
+	return types
+}
+
+// sqlType returns the Diesel SQL type of a column.
+func (w *dieselWriter) sqlType(col Column) string {
+	udt := col.ElementType()
+	typ := w.sqlTypes[udt]
+	if types, ok := dieselTypes[udt]; ok {
+		typ = types[0]
+	}
+	if col.IsArray() {
+		typ = "Array<Nullable<" + typ + ">>"
+	}
+	if col.Nullable() {
+		typ = "Nullable<" + typ + ">"
+	}
+	return typ
+}
+
+func (w *dieselWriter) table(t *Table) string {
+	var b strings.Builder
+	b.WriteString("diesel::table! {\n    use diesel::sql_types::*;\n")
+	var custom []string
+	for _, col := range t.Columns {
+		if name, ok := w.sqlTypes[col.ElementType()]; ok && !slices.Contains(custom, name) {
+			custom = append(custom, name)
+		}
+	}
+	slices.Sort(custom)
+	for _, name := range custom {
+		fmt.Fprintf(&b, "    use super::sql_types::%s;\n", name)
+	}
+	b.WriteString("\n")
+	if t.Comment != "" {
+		fmt.Fprintf(&b, "    /// %s\n", oneLine(t.Comment))
+	}
+	name := w.tables[t.Name]
+	if name != t.Name {
+		fmt.Fprintf(&b, "    #[sql_name = %q]\n", t.Name)
+	}
+	var keys []string
+	for _, c := range t.PrimaryKey.Columns {
+		keys = append(keys, w.columns[t.Name][c])
+	}
+	fmt.Fprintf(&b, "    %s (%s) {\n", name, strings.Join(keys, ", "))
+	for _, col := range t.Columns {
+		if col.Comment != "" {
+			fmt.Fprintf(&b, "        /// %s\n", oneLine(col.Comment))
+		}
+		if col.CharMaxLength > 0 {
+			fmt.Fprintf(&b, "        #[max_length = %d]\n", col.CharMaxLength)
+		}
+		column := w.columns[t.Name][col.ColumnName]
+		if column != col.ColumnName {
+			fmt.Fprintf(&b, "        #[sql_name = %q]\n", col.ColumnName)
+		}
+		fmt.Fprintf(&b, "        %s -> %s,\n", column, w.sqlType(col))
+	}
+	b.WriteString("    }\n}\n")
+	return b.String()
+}
+
+// rustType returns the Rust type a column loads into.
+func (w *dieselWriter) rustType(col Column) string {
+	udt := col.ElementType()
+	typ := "String"
+	if types, ok := dieselTypes[udt]; ok {
+		typ = types[1]
+	} else if enum, ok := w.enums[udt]; ok {
+		typ = enum
+	}
+	if col.IsArray() {
+		typ = "Vec<Option<" + typ + ">>"
+	}
+	if col.Nullable() {
+		typ = "Option<" + typ + ">"
+	}
+	return typ
+}
+
+// models renders the Rust enums and the structs of each table. Columns of
+// custom types other than enums have no Rust type to load into and are
+// left out.
+func (w *dieselWriter) models() string {
+	var b strings.Builder
+	b.WriteString("// Generated by schema transform. Do not edit.\n")
+	b.WriteString("use diesel::prelude::*;\n")
+	var tables []string
+	for _, name := range w.tables {
+		tables = append(tables, name)
+	}
+	slices.Sort(tables)
+	if len(tables) > 0 {
+		fmt.Fprintf(&b, "\nuse crate::schema::{%s};\n", strings.Join(tables, ", "))
+	}
+
+	for _, udt := range w.sortedSQLTypes() {
+		e := w.schema.Enum(udt)
+		if e == nil {
+			continue
+		}
+		b.WriteString("\n#[derive(Debug, Clone, Copy, PartialEq, Eq, diesel_derive_enum::DbEnum)]\n")
+		fmt.Fprintf(&b, "#[ExistingTypePath = \"crate::schema::sql_types::%s\"]\n", w.sqlTypes[udt])
+		fmt.Fprintf(&b, "pub enum %s {\n", w.enums[e.Name])
+		for _, v := range e.Values {
+			variant := pascalCase(v)
+			if variant == "" || variant[0] >= '0' && variant[0] <= '9' {
+				variant = "V" + variant
+			}
+			fmt.Fprintf(&b, "    #[db_rename = %q]\n    %s,\n", v, variant)
+		}
+		b.WriteString("}\n")
+	}
+
+	for _, t := range w.schema.Tables {
+		table, ok := w.tables[t.Name]
+		if !ok {
+			continue
+		}
+		class := className(t.Name)
+		loadable := func(col Column) bool {
+			_, known := dieselTypes[col.ElementType()]
+			return known || w.schema.Enum(col.ElementType()) != nil
+		}
+
+		// A struct can belong to each parent through one foreign key only.
+		var belongsTo []string
+		parents := make(map[string]bool)
+		for _, fk := range t.ForeignKeys {
+			target := w.schema.Table(fk.TargetTable)
+			if target == nil || w.tables[target.Name] == "" || len(fk.SourceColumns) != 1 || !slices.Equal(target.PrimaryKey.Columns, fk.TargetColumns) || parents[target.Name] {
+				continue
+			}
+			parents[target.Name] = true
+			belongsTo = append(belongsTo, fmt.Sprintf("#[diesel(belongs_to(%s, foreign_key = %s))]\n", className(target.Name), w.columns[t.Name][fk.SourceColumns[0]]))
+		}
+		derives := "Debug, Clone, PartialEq, Queryable, Selectable, Identifiable"
+		if len(belongsTo) > 0 {
+			derives += ", Associations"
+		}
+		var keys []string
+		for _, c := range t.PrimaryKey.Columns {
+			keys = append(keys, w.columns[t.Name][c])
+		}
+
+		fmt.Fprintf(&b, "\n#[derive(%s)]\n", derives)
+		fmt.Fprintf(&b, "#[diesel(table_name = %s)]\n", table)
+		fmt.Fprintf(&b, "#[diesel(primary_key(%s))]\n", strings.Join(keys, ", "))
+		b.WriteString(strings.Join(belongsTo, ""))
+		b.WriteString("#[diesel(check_for_backend(diesel::pg::Pg))]\n")
+		fmt.Fprintf(&b, "pub struct %s {\n", class)
+		for _, col := range t.Columns {
+			if loadable(col) {
+				fmt.Fprintf(&b, "    pub %s: %s,\n", w.columns[t.Name][col.ColumnName], w.rustType(col))
+			}
+		}
+		b.WriteString("}\n")
+
+		// Columns the database fills are left out of inserts, columns with
+		// a default become optional so None inserts DEFAULT.
+		var fields []string
+		for _, col := range t.Columns {
+			if !loadable(col) || col.IsGenerated() {
+				continue
+			}
+			typ := w.rustType(col)
+			if col.Default != "" && !col.Nullable() {
+				typ = "Option<" + typ + ">"
+			}
+			fields = append(fields, fmt.Sprintf("    pub %s: %s,\n", w.columns[t.Name][col.ColumnName], typ))
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		b.WriteString("\n#[derive(Debug, Clone, Insertable)]\n")
+		fmt.Fprintf(&b, "#[diesel(table_name = %s)]\n", table)
+		fmt.Fprintf(&b, "pub struct New%s {\n", class)
+		b.WriteString(strings.Join(fields, ""))
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,7 @@
+package internal
+
+import "testing"
+
+func TestGenerateDiesel(t *testing.T) {
+	generateGolden(t, generateDiesel, "diesel", "schema.rs", "models.rs")
+}
@@ -0,0 +1,507 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var pythonKeywords = map[string]bool{
+	"False": true, "None": true, "True": true, "and": true, "as": true, "assert": true,
+	"async": true, "await": true, "break": true, "class": true, "continue": true,
+	"def": true, "del": true, "elif": true, "else": true, "except": true, "finally": true,
+	"for": true, "from": true, "global": true, "if": true, "import": true, "in": true,
+	"is": true, "lambda": true, "nonlocal": true, "not": true, "or": true, "pass": true,
+	"raise": true, "return": true, "try": true, "while": true, "with": true, "yield": true,
+}
+
+// pythonIdent turns a database name into a snake_case Python identifier.
+func pythonIdent(name string) string {
+	ident := snakeCase(name)
+	switch {
+	case ident == "":
+		ident = "field"
+	case ident[0] >= '0' && ident[0] <= '9':
+		ident = "f_" + ident
+	}
+	if pythonKeywords[ident] {
+		ident += "_field"
+	}
+	return ident
+}
+
+// pythonConstant turns an enum value into an upper case Python constant.
+func pythonConstant(value string) string {
+	ident := strings.ToUpper(snakeCase(value))
+	if ident == "" || ident[0] >= '0' && ident[0] <= '9' {
+		ident = "V_" + ident
+	}
+	return ident
+}
+
+var typeModifiers = regexp.MustCompile(`\((\d+)(?:,\s*(\d+))?\)`)
+
+// columnModifiers returns the length, or precision and scale, of a column
+// type, read from the formatted type for array elements.
+func columnModifiers(col Column) (int64, int64, bool) {
+	if col.CharMaxLength > 0 {
+		return col.CharMaxLength, 0, true
+	}
+	if col.NumericPrecision > 0 && col.UDTName == "numeric" {
+		return col.NumericPrecision, col.NumericScale, true
+	}
+	m := typeModifiers.FindStringSubmatch(col.FullType)
+	if m == nil {
+		return 0, 0, false
+	}
+	first, _ := strconv.ParseInt(m[1], 10, 64)
+	second, _ := strconv.ParseInt(m[2], 10, 64)
+	return first, second, true
+}
+
+// elementColumn describes the element of an array column as a column of
+// its own, so array fields can reuse the scalar mapping.
+func elementColumn(col Column) Column {
+	return Column{
+		ColumnName: col.ColumnName,
+		UDTName:    col.ElementType(),
+		FullType:   strings.TrimSuffix(col.FullType, "[]"),
+		IsNullable: "NO",
+	}
+}
+
+// djangoOnDelete maps referential actions to Django's on_delete handlers.
+// NO ACTION leaves the check to the database, like inspectdb does.
+var djangoOnDelete = map[string]string{
+	"CASCADE":     "models.CASCADE",
+	"SET NULL":    "models.SET_NULL",
+	"SET DEFAULT": "models.SET_DEFAULT",
+	"RESTRICT":    "models.RESTRICT",
+	"NO ACTION":   "models.DO_NOTHING",
+	"":            "models.DO_NOTHING",
+}
+
+var djangoIndexClasses = map[string]string{
+	"gin":    "GinIndex",
+	"gist":   "GistIndex",
+	"brin":   "BrinIndex",
+	"hash":   "HashIndex",
+	"spgist": "SpGistIndex",
+}
+
+type djangoWriter struct {
+	schema *Schema
+	opts   GenerateOptions
+	// enumClasses maps enum types to their TextChoices class.
+	enumClasses map[string]string
+	// The imports the models need.
+	uuid, arrays, now bool
+	indexClasses      map[string]bool
+}
+
+// generateDjango renders the schema as a Django models.py: one model per
+// table with Meta options for the table name, unique constraints, indexes
+// and constraints, and a TextChoices class per enum.
+func generateDjango(schema *Schema, opts GenerateOptions) ([]File, error) {
+	w := &djangoWriter{schema: schema, opts: opts, enumClasses: make(map[string]string), indexClasses: make(map[string]bool)}
+	models := make(map[string]bool)
+	for _, t := range schema.Tables {
+		models[className(t.Name)] = true
+	}
+
+	var body strings.Builder
+	for _, e := range schema.Enums {
+		class := pascalCase(e.Name)
+		if models[class] {
+			class += "Choices"
+		}
+		w.enumClasses[e.Name] = class
+		fmt.Fprintf(&body, "\n\nclass %s(models.TextChoices):\n", class)
+		for _, v := range e.Values {
+			label := strings.ReplaceAll(v, "_", " ")
+			if label != "" {
+				label = strings.ToUpper(label[:1]) + label[1:]
+			}
+			fmt.Fprintf(&body, "    %s = %s, %s\n", pythonConstant(v), pythonString(v), pythonString(label))
+		}
+	}
+	for i := range schema.Tables {
+		body.WriteString("\n\n")
+		body.WriteString(w.model(&schema.Tables[i]))
+	}
+
+	var b strings.Builder
+	b.WriteString("# Generated by schema transform. Do not edit.\n")
+	if w.uuid {
+		b.WriteString("import uuid\n\n")
+	}
+	if w.arrays {
+		b.WriteString("from django.contrib.postgres.fields import ArrayField\n")
+	}
+	if len(w.indexClasses) > 0 {
+		var classes []string
+		for class := range w.indexClasses {
+			classes = append(classes, class)
+		}
+		slices.Sort(classes)
+		fmt.Fprintf(&b, "from django.contrib.postgres.indexes import %s\n", strings.Join(classes, ", "))
+	}
+	b.WriteString("from django.db import models\n")
+	if w.now {
+		b.WriteString("from django.db.models.functions import Now\n")
+	}
+	b.WriteString(body.String())
+	return []File{{Name: "models.py", Content: []byte(b.String())}}, nil
+}
+
+func (w *djangoWriter) model(t *Table) string {
+	owners := make(map[string]Relation)
+	var notes []string
+	for _, rel := range w.schema.Relations(t.Name) {
+		if !rel.Owner {
+			continue
+		}
+		if len(rel.ForeignKey.SourceColumns) != 1 {
+			notes = append(notes, fmt.Sprintf("# Foreign key %s (%s) -> %s (%s) spans several columns, which Django doesn't support.",
+				rel.ForeignKey.ConstraintName, strings.Join(rel.ForeignKey.SourceColumns, ", "),
+				rel.ForeignKey.TargetTable, strings.Join(rel.ForeignKey.TargetColumns, ", ")))
+			continue
+		}
+		owners[rel.ForeignKey.SourceColumns[0]] = rel
+	}
+
+	// Field names are the column names, or the relation name for foreign
+	// keys, made unique.
+	fieldNames := make(map[string]string)
+	taken := map[string]bool{"pk": t.PrimaryKey != nil && len(t.PrimaryKey.Columns) > 1}
+	for _, col := range t.Columns {
+		name := pythonIdent(col.ColumnName)
+		if rel, ok := owners[col.ColumnName]; ok {
+			name = pythonIdent(rel.Name)
+		}
+		for taken[name] {
+			name += "_field"
+		}
+		taken[name] = true
+		fieldNames[col.ColumnName] = name
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "class %s(models.Model):\n", className(t.Name))
+	if t.PrimaryKey != nil && len(t.PrimaryKey.Columns) > 1 {
+		var fields []string
+		for _, c := range t.PrimaryKey.Columns {
+			fields = append(fields, pythonString(fieldNames[c]))
+		}
+		fmt.Fprintf(&b, "    pk = models.CompositePrimaryKey(%s)\n", strings.Join(fields, ", "))
+	}
+	for _, col := range t.Columns {
+		b.WriteString(w.field(t, col, fieldNames[col.ColumnName], owners))
+	}
+	for _, note := range notes {
+		b.WriteString("    " + note + "\n")
+	}
+
+	b.WriteString("\n    class Meta:\n")
+	fmt.Fprintf(&b, "        managed = %s\n", pythonBool(w.opts.Managed))
+	fmt.Fprintf(&b, "        db_table = %s\n", pythonString(t.Name))
+	if t.Comment != "" {
+		fmt.Fprintf(&b, "        db_table_comment = %s\n", pythonString(t.Comment))
+	}
+
+	fields := func(columns []string) ([]string, bool) {
+		var names []string
+		for _, c := range columns {
+			name, ok := fieldNames[c]
+			if !ok {
+				return nil, false
+			}
+			names = append(names, pythonString(name))
+		}
+		return names, true
+	}
+	var together []string
+	for _, u := range t.Uniques {
+		if len(u.Columns) > 1 {
+			if names, ok := fields(u.Columns); ok {
+				together = append(together, "("+strings.Join(names, ", ")+")")
+			}
+		}
+	}
+	if len(together) > 0 {
+		fmt.Fprintf(&b, "        unique_together = (%s,)\n", strings.Join(together, ", "))
+	}
+
+	var indexes, constraints, comments []string
+	for _, idx := range t.Indexes {
+		names, ok := fields(idx.Columns)
+		switch {
+		case !ok:
+			comments = append(comments, fmt.Sprintf("# Expression index %s: %s", idx.Name, idx.Definition))
+		case idx.Where != "":
+			comments = append(comments, fmt.Sprintf("# Partial index %s: %s", idx.Name, idx.Definition))
+		case idx.Unique && len(idx.Columns) == 1:
+			// Rendered as unique=True on the field.
+		case idx.Unique:
+			constraints = append(constraints, fmt.Sprintf("models.UniqueConstraint(fields=[%s], name=%s)", strings.Join(names, ", "), pythonString(idx.Name)))
+		case len(idx.Name) > 30:
+			comments = append(comments, fmt.Sprintf("# Index %s: Django limits index names to 30 characters: %s", idx.Name, idx.Definition))
+		default:
+			class := "models.Index"
+			if c, ok := djangoIndexClasses[idx.Method]; ok {
+				class = c
+				w.indexClasses[c] = true
+			}
+			indexes = append(indexes, fmt.Sprintf("%s(fields=[%s], name=%s)", class, strings.Join(names, ", "), pythonString(idx.Name)))
+		}
+	}
+	lookupName := func(column string) string {
+		if _, ok := owners[column]; ok {
+			// Lookups on the key value go through the attname.
+			return fieldNames[column] + "_id"
+		}
+		return fieldNames[column]
+	}
+	for _, c := range t.Checks {
+		if check, ok := djangoCheck(t, c.Expression, lookupName); ok {
+			constraints = append(constraints, fmt.Sprintf("models.CheckConstraint(check=%s, name=%s)", check, pythonString(c.ConstraintName)))
+		} else {
+			comments = append(comments, fmt.Sprintf("# CHECK %s: %s", c.ConstraintName, c.Expression))
+		}
+	}
+	for _, list := range []struct {
+		name  string
+		items []string
+	}{{"indexes", indexes}, {"constraints", constraints}} {
+		if len(list.items) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "        %s = [\n", list.name)
+		for _, item := range list.items {
+			fmt.Fprintf(&b, "            %s,\n", item)
+		}
+		b.WriteString("        ]\n")
+	}
+	for _, comment := range comments {
+		b.WriteString("        " + oneLine(comment) + "\n")
+	}
+	return b.String()
+}
+
+// djangoCheck translates a CHECK expression into the Q object of a
+// CheckConstraint. Every condition it ANDs together has to be a range, an
+// IN list or a non-empty string on a single column, as understood by
+// columnRules; ok is false otherwise.
+func djangoCheck(t *Table, expr string, lookupName func(column string) string) (string, bool) {
+	rules := make(map[string]*fieldRules)
+	var order []string
+	for _, cond := range splitConjunction(expr) {
+		found := false
+		for _, col := range t.Columns {
+			var r fieldRules
+			r.add(col.ColumnName, cond)
+			if r.minLength == 0 && r.maxLength == 0 && r.min == nil && r.max == nil && r.values == nil {
+				continue
+			}
+			if rules[col.ColumnName] == nil {
+				rules[col.ColumnName] = &fieldRules{}
+				order = append(order, col.ColumnName)
+			}
+			rules[col.ColumnName].add(col.ColumnName, cond)
+			found = true
+			break
+		}
+		if !found {
+			return "", false
+		}
+	}
+
+	var lookups, negated []string
+	for _, column := range order {
+		r, name := rules[column], lookupName(column)
+		// Lengths other than non-empty need database functions.
+		if r.maxLength > 0 || r.minLength > 1 {
+			return "", false
+		}
+		if r.minLength == 1 {
+			negated = append(negated, fmt.Sprintf("~models.Q(%s='')", name))
+		}
+		if r.values != nil {
+			values := make([]string, len(r.values))
+			for i, v := range r.values {
+				values[i] = v.value
+				if v.string {
+					values[i] = pythonString(v.value)
+				}
+			}
+			lookups = append(lookups, fmt.Sprintf("%s__in=[%s]", name, strings.Join(values, ", ")))
+		}
+		if r.min != nil {
+			op := "gte"
+			if r.min.exclusive {
+				op = "gt"
+			}
+			lookups = append(lookups, fmt.Sprintf("%s__%s=%s", name, op, r.min.value))
+		}
+		if r.max != nil {
+			op := "lte"
+			if r.max.exclusive {
+				op = "lt"
+			}
+			lookups = append(lookups, fmt.Sprintf("%s__%s=%s", name, op, r.max.value))
+		}
+	}
+	var terms []string
+	if len(lookups) > 0 {
+		terms = append(terms, "models.Q("+strings.Join(lookups, ", ")+")")
+	}
+	return strings.Join(append(terms, negated...), " & "), true
+}
+
+// field renders one model field. Foreign key columns become ForeignKey or
+// OneToOneField fields named after the relation.
+func (w *djangoWriter) field(t *Table, col Column, name string, owners map[string]Relation) string {
+	var class string
+	var args []string
+	primary := t.PrimaryKey != nil && len(t.PrimaryKey.Columns) == 1 && t.PrimaryKey.Columns[0] == col.ColumnName
+	column := col.ColumnName
+
+	if rel, ok := owners[col.ColumnName]; ok {
+		fk := rel.ForeignKey
+		target := w.schema.Table(fk.TargetTable)
+		class = "models.ForeignKey"
+		if isUniqueColumns(t, fk.SourceColumns) {
+			class = "models.OneToOneField"
+		}
+		model := pythonString(className(target.Name))
+		if target.Name == t.Name {
+			model = "'self'"
+		}
+		args = append(args, model, djangoOnDelete[fk.OnDelete])
+		if primary {
+			args = append(args, "primary_key=True")
+		}
+		if target.PrimaryKey == nil || !slices.Equal(target.PrimaryKey.Columns, fk.TargetColumns) {
+			args = append(args, "to_field="+pythonString(fk.TargetColumns[0]))
+		}
+		for _, inverse := range w.schema.Relations(target.Name) {
+			if !inverse.Owner && inverse.Other == t.Name && inverse.ForeignKey.ConstraintName == fk.ConstraintName {
+				args = append(args, "related_name="+pythonString(pythonIdent(inverse.Name)))
+			}
+		}
+		if name+"_id" != column {
+			args = append(args, "db_column="+pythonString(column))
+		}
+		column = ""
+	} else {
+		class, args = w.fieldType(col)
+		if primary {
+			switch {
+			case (col.IsSerial() || col.IsIdentity) && col.UDTName == "int2":
+				class, args = "models.SmallAutoField", nil
+			case (col.IsSerial() || col.IsIdentity) && col.UDTName == "int4":
+				class, args = "models.AutoField", nil
+			case (col.IsSerial() || col.IsIdentity) && col.UDTName == "int8":
+				class, args = "models.BigAutoField", nil
+			}
+			args = append(args, "primary_key=True")
+		} else if t.IsUnique(col.ColumnName) {
+			args = append(args, "unique=True")
+		}
+	}
+
+	comment := ""
+	if !col.IsSerial() {
+		switch kind, value := parseDefault(col.Default); kind {
+		case StringDefault:
+			args = append(args, "default="+pythonString(value))
+		case NumberDefault:
+			args = append(args, "default="+value)
+		case BoolDefault:
+			args = append(args, "default="+map[string]string{"true": "True", "false": "False"}[value])
+		case NowDefault:
+			args = append(args, "db_default=Now()")
+			w.now = true
+		case UUIDDefault:
+			args = append(args, "default=uuid.uuid4")
+			w.uuid = true
+		case ExpressionDefault:
+			comment = "  # database default: " + oneLine(col.Default)
+		}
+	}
+	if column != "" && name != column {
+		args = append(args, "db_column="+pythonString(column))
+	}
+	if col.Nullable() {
+		args = append(args, "blank=True", "null=True")
+	}
+	if col.Comment != "" {
+		args = append(args, "db_comment="+pythonString(col.Comment))
+	}
+	return fmt.Sprintf("    %s = %s(%s)%s\n", name, class, strings.Join(args, ", "), comment)
+}
+
+// fieldType maps a column type to a Django field class and its arguments.
+func (w *djangoWriter) fieldType(col Column) (string, []string) {
+	if col.IsArray() {
+		w.arrays = true
+		class, args := w.fieldType(elementColumn(col))
+		return "ArrayField", []string{class + "(" + strings.Join(args, ", ") + ")"}
+	}
+	if enum, ok := w.enumClasses[col.UDTName]; ok {
+		length := 1
+		for _, v := range w.schema.Enum(col.UDTName).Values {
+			length = max(length, len(v))
+		}
+		return "models.CharField", []string{fmt.Sprintf("max_length=%d", length), "choices=" + enum + ".choices"}
+	}
+	switch col.UDTName {
+	case "int2":
+		return "models.SmallIntegerField", nil
+	case "int4":
+		return "models.IntegerField", nil
+	case "int8":
+		return "models.BigIntegerField", nil
+	case "float4", "float8":
+		return "models.FloatField", nil
+	case "numeric":
+		if precision, scale, ok := columnModifiers(col); ok {
+			return "models.DecimalField", []string{fmt.Sprintf("max_digits=%d", precision), fmt.Sprintf("decimal_places=%d", scale)}
+		}
+		// Unconstrained numeric, the values Django needs are a guess.
+		return "models.DecimalField", []string{"max_digits=65535", "decimal_places=65535"}
+	case "bool":
+		return "models.BooleanField", nil
+	case "varchar", "bpchar":
+		if length, _, ok := columnModifiers(col); ok {
+			return "models.CharField", []string{fmt.Sprintf("max_length=%d", length)}
+		}
+		return "models.CharField", nil
+	case "uuid":
+		return "models.UUIDField", nil
+	case "json", "jsonb":
+		return "models.JSONField", nil
+	case "date":
+		return "models.DateField", nil
+	case "timestamp", "timestamptz":
+		return "models.DateTimeField", nil
+	case "time", "timetz":
+		return "models.TimeField", nil
+	case "interval":
+		return "models.DurationField", nil
+	case "bytea":
+		return "models.BinaryField", nil
+	case "inet":
+		return "models.GenericIPAddressField", nil
+	}
+	return "models.TextField", nil
+}
+
+func pythonBool(b bool) string {
+	if b {
+		return "True"
+	}
+	return "False"
+}
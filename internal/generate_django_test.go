@@ -0,0 +1,66 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDjangoCheck(t *testing.T) {
+	table := blogSchema().Table("posts")
+	lookupName := func(column string) string {
+		if column == "author_id" {
+			return "author_id"
+		}
+		return column
+	}
+	tests := []struct {
+		expr string
+		want string
+		ok   bool
+	}{
+		{"(type = ANY (ARRAY['post'::text, 'page'::text]))", "models.Q(type__in=['post', 'page'])", true},
+		{"((rating >= 1) AND (rating <= 5))", "models.Q(rating__gte=1, rating__lte=5)", true},
+		{"(rating > 0)", "models.Q(rating__gt=0)", true},
+		{"(length((title)::text) > 0)", "~models.Q(title='')", true},
+		{"((author_id > 0) AND ((title)::text <> ''::text))", "models.Q(author_id__gt=0) & ~models.Q(title='')", true},
+		{"(length((title)::text) <= 80)", "", false},
+		{"((rating > 0) OR (rating IS NULL))", "", false},
+		{"valid_title(title)", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := djangoCheck(table, tt.expr, lookupName)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("djangoCheck(%q) = %q, %v, want %q, %v", tt.expr, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestGenerateDjango(t *testing.T) {
+	schema := blogSchemaWith(alterTable("posts", func(t *Table) {
+		t.Checks = append(t.Checks, CheckConstraint{ConstraintName: "posts_title_valid", Expression: "valid_title(title)"})
+	}))
+	files, err := generateDjango(schema, GenerateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Name != "models.py" {
+		t.Fatalf("got %d files, want models.py", len(files))
+	}
+	golden(t, "django/models.py", files[0].Content)
+	models := string(files[0].Content)
+	for _, want := range []string{
+		"models.CheckConstraint(check=models.Q(type__in=['post', 'page']), name='posts_type_check'),",
+		"models.CheckConstraint(check=models.Q(rating__gte=1, rating__lte=5), name='posts_rating_check'),",
+		"models.CheckConstraint(check=~models.Q(title=''), name='posts_title_check'),",
+		"# CHECK posts_title_valid: valid_title(title)",
+	} {
+		if !strings.Contains(models, want) {
+			t.Errorf("models.py is missing %q:\n%s", want, models)
+		}
+	}
+
+	// Models are only importable from an installed app.
+	app := []File{{Name: "blog/__init__.py"}, {Name: "blog/models.py", Content: files[0].Content}}
+	compilePython(t, app, "import django; from django.conf import settings; "+
+		"settings.configure(INSTALLED_APPS=['blog']); django.setup(); import blog.models", "django")
+}
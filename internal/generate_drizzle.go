@@ -0,0 +1,419 @@
+package internal
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// drizzleReferentialActions maps referential actions to Drizzle's names.
+var drizzleReferentialActions = map[string]string{
+	"CASCADE":     "cascade",
+	"SET NULL":    "set null",
+	"SET DEFAULT": "set default",
+	"RESTRICT":    "restrict",
+	"NO ACTION":   "no action",
+}
+
+type drizzleWriter struct {
+	schema *Schema
+	// tables and enums map the database names to the exported constants,
+	// properties each table's columns to their keys.
+	tables     map[string]string
+	enums      map[string]string
+	properties map[string]map[string]string
+	// The builders imported from drizzle-orm/pg-core, and whether sql is.
+	imports map[string]bool
+	sql     bool
+	// customTypes maps the types without a builder to the customType
+	// declared for them.
+	customTypes map[string]string
+	taken       map[string]bool
+}
+
+// generateDrizzle renders the schema as Drizzle ORM definitions in
+// schema.ts: a pgEnum per enum type, a pgTable per table with its keys,
+// constraints and indexes, and relations() for both sides of each foreign
+// key.
+func generateDrizzle(schema *Schema, opts GenerateOptions) ([]File, error) {
+	w := &drizzleWriter{
+		schema:      schema,
+		tables:      make(map[string]string),
+		enums:       make(map[string]string),
+		properties:  make(map[string]map[string]string),
+		imports:     make(map[string]bool),
+		customTypes: make(map[string]string),
+		taken:       make(map[string]bool),
+	}
+	taken := w.taken
+	for _, t := range schema.Tables {
+		name := tsProperty(t.Name)
+		for taken[name] {
+			name += "_"
+		}
+		taken[name] = true
+		w.tables[t.Name] = name
+
+		properties := make(map[string]bool)
+		w.properties[t.Name] = make(map[string]string)
+		for _, col := range t.Columns {
+			property := tsProperty(col.ColumnName)
+			for properties[property] {
+				property += "_"
+			}
+			properties[property] = true
+			w.properties[t.Name][col.ColumnName] = property
+		}
+	}
+	for _, e := range schema.Enums {
+		name := tsProperty(e.Name)
+		for taken[name] {
+			name += "Enum"
+		}
+		taken[name] = true
+		w.enums[e.Name] = name
+	}
+
+	var body strings.Builder
+	for _, e := range schema.Enums {
+		w.imports["pgEnum"] = true
+		fmt.Fprintf(&body, "\nexport const %s = pgEnum(%s, [%s]);\n", w.enums[e.Name], strconv.Quote(e.Name), strings.Join(tsStrings(e.Values), ", "))
+	}
+	var relations strings.Builder
+	for i := range schema.Tables {
+		t := &schema.Tables[i]
+		body.WriteString("\n")
+		body.WriteString(w.table(t))
+		relations.WriteString(w.relations(t))
+	}
+	var custom strings.Builder
+	var types []string
+	for udt := range w.customTypes {
+		types = append(types, udt)
+	}
+	slices.Sort(types)
+	for _, udt := range types {
+		data := "string"
+		if udt == "bytea" {
+			data = "Buffer"
+		}
+		fmt.Fprintf(&custom, "\nconst %s = customType<{ data: %s }>({\n  dataType() {\n    return %s;\n  },\n});\n",
+			w.customTypes[udt], data, strconv.Quote(udt))
+	}
+
+	var b strings.Builder
+	b.WriteString("// Generated by schema transform. Do not edit.\n")
+	var orm []string
+	if relations.Len() > 0 {
+		orm = append(orm, "relations")
+	}
+	if w.sql {
+		orm = append(orm, "sql")
+	}
+	if len(orm) > 0 {
+		fmt.Fprintf(&b, "import { %s } from \"drizzle-orm\";\n", strings.Join(orm, ", "))
+	}
+	var builders []string
+	for name := range w.imports {
+		builders = append(builders, name)
+	}
+	slices.Sort(builders)
+	fmt.Fprintf(&b, "import { %s } from \"drizzle-orm/pg-core\";\n", strings.Join(builders, ", "))
+	b.WriteString(custom.String())
+	b.WriteString(body.String())
+	b.WriteString(relations.String())
+	return []File{{Name: "schema.ts", Content: []byte(b.String())}}, nil
+}
+
+func tsStrings(values []string) []string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = strconv.Quote(v)
+	}
+	return quoted
+}
+
+// sqlTemplate returns an SQL expression as a Drizzle sql template literal.
+func (w *drizzleWriter) sqlTemplate(expr string) string {
+	w.sql = true
+	return "sql`" + strings.NewReplacer("\\", "\\\\", "`", "\\`", "${", "\\${").Replace(expr) + "`"
+}
+
+func (w *drizzleWriter) table(t *Table) string {
+	w.imports["pgTable"] = true
+	properties := w.properties[t.Name]
+	columns := func(names []string) (string, bool) {
+		var list []string
+		for _, c := range names {
+			property, ok := properties[c]
+			if !ok {
+				return "", false
+			}
+			list = append(list, "table."+property)
+		}
+		return strings.Join(list, ", "), true
+	}
+
+	var extras []string
+	if t.PrimaryKey != nil && len(t.PrimaryKey.Columns) > 1 {
+		w.imports["primaryKey"] = true
+		list, _ := columns(t.PrimaryKey.Columns)
+		extras = append(extras, fmt.Sprintf("primaryKey({ name: %s, columns: [%s] })", strconv.Quote(t.PrimaryKey.ConstraintName), list))
+	}
+	// Foreign keys go here rather than on the columns, which keeps the
+	// tables free of circular type references.
+	for _, fk := range t.ForeignKeys {
+		target, ok := w.tables[fk.TargetTable]
+		if !ok {
+			continue
+		}
+		list, _ := columns(fk.SourceColumns)
+		// A table referencing itself goes through the callback parameter,
+		// its constant isn't defined yet.
+		if fk.TargetTable == t.Name {
+			target = "table"
+		}
+		var foreign []string
+		for _, c := range fk.TargetColumns {
+			foreign = append(foreign, target+"."+w.properties[fk.TargetTable][c])
+		}
+		w.imports["foreignKey"] = true
+		extra := fmt.Sprintf("foreignKey({ name: %s, columns: [%s], foreignColumns: [%s] })", strconv.Quote(fk.ConstraintName), list, strings.Join(foreign, ", "))
+		if action, ok := drizzleReferentialActions[fk.OnDelete]; ok && action != "no action" {
+			extra += ".onDelete(" + strconv.Quote(action) + ")"
+		}
+		if action, ok := drizzleReferentialActions[fk.OnUpdate]; ok && action != "no action" {
+			extra += ".onUpdate(" + strconv.Quote(action) + ")"
+		}
+		extras = append(extras, extra)
+	}
+	for _, u := range t.Uniques {
+		if list, ok := columns(u.Columns); ok {
+			w.imports["unique"] = true
+			extras = append(extras, fmt.Sprintf("unique(%s).on(%s)", strconv.Quote(u.ConstraintName), list))
+		}
+	}
+	for _, idx := range t.Indexes {
+		builder := "index"
+		if idx.Unique {
+			builder = "uniqueIndex"
+		}
+		w.imports[builder] = true
+		var on []string
+		for _, c := range idx.Columns {
+			if property, ok := properties[c]; ok {
+				on = append(on, "table."+property)
+			} else {
+				on = append(on, w.sqlTemplate(c))
+			}
+		}
+		extra := fmt.Sprintf("%s(%s)", builder, strconv.Quote(idx.Name))
+		if idx.Method != "" && idx.Method != "btree" {
+			extra += fmt.Sprintf(".using(%s, %s)", strconv.Quote(idx.Method), strings.Join(on, ", "))
+		} else {
+			extra += ".on(" + strings.Join(on, ", ") + ")"
+		}
+		if idx.Where != "" {
+			extra += ".where(" + w.sqlTemplate(idx.Where) + ")"
+		}
+		extras = append(extras, extra)
+	}
+	for _, c := range t.Checks {
+		w.imports["check"] = true
+		extras = append(extras, fmt.Sprintf("check(%s, %s)", strconv.Quote(c.ConstraintName), w.sqlTemplate(c.Expression)))
+	}
+
+	var b strings.Builder
+	if t.Comment != "" {
+		fmt.Fprintf(&b, "/** %s */\n", oneLine(t.Comment))
+	}
+	fmt.Fprintf(&b, "export const %s = pgTable(%s, {\n", w.tables[t.Name], strconv.Quote(t.Name))
+	for _, col := range t.Columns {
+		if col.Comment != "" {
+			fmt.Fprintf(&b, "  /** %s */\n", oneLine(col.Comment))
+		}
+		fmt.Fprintf(&b, "  %s: %s,\n", properties[col.ColumnName], w.column(t, col))
+	}
+	b.WriteString("}")
+	if len(extras) > 0 {
+		b.WriteString(", (table) => [\n")
+		for _, extra := range extras {
+			fmt.Fprintf(&b, "  %s,\n", extra)
+		}
+		b.WriteString("]")
+	}
+	b.WriteString(");\n")
+	return b.String()
+}
+
+// column renders the column builder chain of a column.
+func (w *drizzleWriter) column(t *Table, col Column) string {
+	name := strconv.Quote(col.ColumnName)
+	udt := col.ElementType()
+	builder, config := "", ""
+	if enum, ok := w.enums[udt]; ok {
+		builder = enum
+	} else {
+		size, scale, sized := columnModifiers(col)
+		serial := col.IsSerial() && !col.IsArray()
+		switch {
+		case udt == "int2" && serial:
+			builder = "smallserial"
+		case udt == "int4" && serial:
+			builder = "serial"
+		case udt == "int8" && serial:
+			builder, config = "bigserial", `{ mode: "number" }`
+		case udt == "int2":
+			builder = "smallint"
+		case udt == "int4":
+			builder = "integer"
+		case udt == "int8":
+			builder, config = "bigint", `{ mode: "number" }`
+		case udt == "float4":
+			builder = "real"
+		case udt == "float8":
+			builder = "doublePrecision"
+		case udt == "numeric":
+			builder = "numeric"
+			if sized {
+				config = fmt.Sprintf("{ precision: %d, scale: %d }", size, scale)
+			}
+		case udt == "bool":
+			builder = "boolean"
+		case udt == "varchar", udt == "bpchar":
+			builder = map[string]string{"varchar": "varchar", "bpchar": "char"}[udt]
+			if sized {
+				config = fmt.Sprintf("{ length: %d }", size)
+			}
+		case udt == "timestamp", udt == "timestamptz":
+			builder = "timestamp"
+			if udt == "timestamptz" {
+				config = "{ withTimezone: true }"
+			}
+		case udt == "time", udt == "timetz":
+			builder = "time"
+			if udt == "timetz" {
+				config = "{ withTimezone: true }"
+			}
+		case slices.Contains([]string{"uuid", "json", "jsonb", "date", "interval", "inet", "cidr", "macaddr", "text"}, udt):
+			builder = udt
+		default:
+			builder = w.customType(col)
+		}
+		if _, ok := w.customTypes[udt]; !ok {
+			w.imports[builder] = true
+		}
+	}
+
+	call := builder + "(" + name
+	if config != "" {
+		call += ", " + config
+	}
+	call += ")"
+	if col.IsArray() {
+		call += ".array()"
+	}
+	if t.PrimaryKey != nil && len(t.PrimaryKey.Columns) == 1 && t.PrimaryKey.Columns[0] == col.ColumnName {
+		call += ".primaryKey()"
+	} else if !col.Nullable() {
+		call += ".notNull()"
+	}
+	if col.IsIdentity {
+		if col.IdentityGeneration == "ALWAYS" {
+			call += ".generatedAlwaysAsIdentity()"
+		} else {
+			call += ".generatedByDefaultAsIdentity()"
+		}
+	}
+	if col.IsSerial() {
+		return call
+	}
+	kind, value := parseDefault(col.Default)
+	textual := w.enums[udt] != "" || slices.Contains([]string{"text", "varchar", "char"}, builder)
+	switch {
+	case col.IsArray() && kind != NoDefault:
+		call += ".default(" + w.sqlTemplate(col.Default) + ")"
+	case kind == StringDefault && textual:
+		call += ".default(" + strconv.Quote(value) + ")"
+	case kind == NumberDefault && builder == "numeric":
+		call += ".default(" + strconv.Quote(value) + ")"
+	case kind == NumberDefault, kind == BoolDefault:
+		call += ".default(" + value + ")"
+	case kind == NowDefault && currentTimestamps.MatchString(value):
+		call += ".defaultNow()"
+	case kind == UUIDDefault && strings.EqualFold(value, "gen_random_uuid()"):
+		call += ".defaultRandom()"
+	case kind != NoDefault:
+		call += ".default(" + w.sqlTemplate(col.Default) + ")"
+	}
+	return call
+}
+
+// customType returns the customType declared for a type Drizzle has no
+// builder for, like bytea or tsvector.
+func (w *drizzleWriter) customType(col Column) string {
+	udt := col.ElementType()
+	if name, ok := w.customTypes[udt]; ok {
+		return name
+	}
+	w.imports["customType"] = true
+	name := tsProperty(udt) + "Type"
+	for w.taken[name] {
+		name += "_"
+	}
+	w.taken[name] = true
+	w.customTypes[udt] = name
+	return name
+}
+
+// relations renders the relations() of a table, naming the relation after
+// the foreign key where it's ambiguous.
+func (w *drizzleWriter) relations(t *Table) string {
+	rels := w.schema.Relations(t.Name)
+	if len(rels) == 0 {
+		return ""
+	}
+	helpers := make(map[string]bool)
+	var fields []string
+	for _, rel := range rels {
+		fk := rel.ForeignKey
+		target := w.tables[rel.Other]
+		var options []string
+		if rel.Owner {
+			var source, references []string
+			for _, c := range fk.SourceColumns {
+				source = append(source, w.tables[t.Name]+"."+w.properties[t.Name][c])
+			}
+			for _, c := range fk.TargetColumns {
+				references = append(references, target+"."+w.properties[rel.Other][c])
+			}
+			options = append(options, "fields: ["+strings.Join(source, ", ")+"]", "references: ["+strings.Join(references, ", ")+"]")
+		}
+		if w.schema.isAmbiguous(fk) {
+			options = append(options, "relationName: "+strconv.Quote(fk.ConstraintName))
+		}
+		helper := "one"
+		if rel.Many {
+			helper = "many"
+		}
+		helpers[helper] = true
+		call := helper + "(" + target
+		if len(options) > 0 {
+			call += ", { " + strings.Join(options, ", ") + " }"
+		}
+		fields = append(fields, fmt.Sprintf("  %s: %s),\n", tsProperty(rel.Name), call))
+	}
+
+	var params []string
+	for _, helper := range []string{"one", "many"} {
+		if helpers[helper] {
+			params = append(params, helper)
+		}
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "\nexport const %sRelations = relations(%s, ({ %s }) => ({\n", w.tables[t.Name], w.tables[t.Name], strings.Join(params, ", "))
+	b.WriteString(strings.Join(fields, ""))
+	b.WriteString("}));\n")
+	return b.String()
+}
@@ -0,0 +1,7 @@
+package internal
+
+import "testing"
+
+func TestGenerateDrizzle(t *testing.T) {
+	generateGolden(t, generateDrizzle, "drizzle", "schema.ts")
+}
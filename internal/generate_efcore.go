@@ -0,0 +1,447 @@
+package internal
+
+import (
+	"fmt"
+	"html"
+	"slices"
+	"strings"
+)
+
+// csTypes maps udt names to the .NET types Npgsql reads them as.
+var csTypes = map[string]string{
+	"int2": "short", "int4": "int", "int8": "long", "float4": "float", "float8": "double",
+	"numeric": "decimal", "money": "decimal", "bool": "bool", "varchar": "string", "bpchar": "string",
+	"text": "string", "citext": "string", "uuid": "Guid", "json": "string", "jsonb": "string",
+	"date": "DateOnly", "timestamp": "DateTime", "timestamptz": "DateTime", "time": "TimeOnly",
+	"timetz": "DateTimeOffset", "interval": "TimeSpan", "bytea": "byte[]",
+	"inet": "System.Net.IPAddress", "macaddr": "System.Net.NetworkInformation.PhysicalAddress",
+}
+
+// csReferenceTypes are the mapped types that aren't value types.
+var csReferenceTypes = map[string]bool{
+	"string": true, "byte[]": true, "System.Net.IPAddress": true,
+	"System.Net.NetworkInformation.PhysicalAddress": true,
+}
+
+var efDeleteBehaviors = map[string]string{
+	"CASCADE":  "DeleteBehavior.Cascade",
+	"SET NULL": "DeleteBehavior.SetNull",
+	"RESTRICT": "DeleteBehavior.Restrict",
+}
+
+// csString returns s as a C# string literal.
+func csString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, `\u%04x`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// csSummary renders a comment as an XML doc summary.
+func csSummary(comment, indent string) string {
+	return fmt.Sprintf("%s/// <summary>\n%s/// %s\n%s/// </summary>\n", indent, indent, html.EscapeString(oneLine(comment)), indent)
+}
+
+type efcoreWriter struct {
+	schema    *Schema
+	namespace string
+	// classes maps tables to their entity classes, enums the enum types
+	// to their C# enums.
+	classes map[string]string
+	enums   map[string]string
+	// properties maps each table's columns to their properties, and
+	// navigations its relations by relationKey to their navigations.
+	properties  map[string]map[string]string
+	navigations map[string]map[string]string
+}
+
+// generateEFCore renders the schema as Entity Framework Core code for
+// Npgsql the way dotnet ef dbcontext scaffold does: an entity class per
+// table with navigations for both sides of its foreign keys, a C# enum per
+// enum type and AppDbContext.cs with a DbSet per entity and the keys,
+// indexes, relationships and column types configured in OnModelCreating.
+func generateEFCore(schema *Schema, opts GenerateOptions) ([]File, error) {
+	w := &efcoreWriter{
+		schema:      schema,
+		namespace:   opts.Package,
+		classes:     make(map[string]string),
+		enums:       make(map[string]string),
+		properties:  make(map[string]map[string]string),
+		navigations: make(map[string]map[string]string),
+	}
+	if w.namespace == "" {
+		w.namespace = "Models"
+	}
+	taken := map[string]bool{"AppDbContext": true}
+	for _, name := range []string{"Object", "String", "Guid", "DateTime", "DateOnly", "TimeOnly", "DateTimeOffset", "TimeSpan", "Math", "Console", "Task", "List", "ICollection", "DbContext", "DbSet", "ModelBuilder", "DeleteBehavior"} {
+		taken[name] = true
+	}
+	reserve := func(name, suffix string) string {
+		if name == "" || name[0] >= '0' && name[0] <= '9' {
+			name = "T" + name
+		}
+		for taken[name] {
+			name += suffix
+		}
+		taken[name] = true
+		return name
+	}
+	for _, t := range schema.Tables {
+		w.classes[t.Name] = reserve(className(t.Name), "Entity")
+	}
+	for _, e := range schema.Enums {
+		w.enums[e.Name] = reserve(pascalCase(e.Name), "Enum")
+	}
+	for _, t := range schema.Tables {
+		// Members can't be named like their class.
+		used := map[string]bool{w.classes[t.Name]: true}
+		name := func(base string) string {
+			name := pascalCase(base)
+			if name == "" || name[0] >= '0' && name[0] <= '9' {
+				name = "_" + name
+			}
+			for n := 1; used[name]; n++ {
+				name = fmt.Sprintf("%s%d", strings.TrimRight(name, "0123456789"), n)
+			}
+			used[name] = true
+			return name
+		}
+		w.properties[t.Name] = make(map[string]string)
+		for _, col := range t.Columns {
+			w.properties[t.Name][col.ColumnName] = name(col.ColumnName)
+		}
+		w.navigations[t.Name] = make(map[string]string)
+		for _, rel := range schema.Relations(t.Name) {
+			if w.related(rel) {
+				w.navigations[t.Name][relationKey(rel.Owner, rel.Other, rel.ForeignKey.ConstraintName)] = name(rel.Name)
+			}
+		}
+	}
+
+	var files []File
+	for _, e := range schema.Enums {
+		var b strings.Builder
+		b.WriteString("// Generated by schema transform. Do not edit.\nusing NpgsqlTypes;\n\n")
+		fmt.Fprintf(&b, "namespace %s;\n\npublic enum %s\n{\n", w.namespace, w.enums[e.Name])
+		used := make(map[string]bool)
+		for _, v := range e.Values {
+			member := pascalCase(v)
+			if member == "" || member[0] >= '0' && member[0] <= '9' {
+				member = "V" + member
+			}
+			for used[member] {
+				member += "_"
+			}
+			used[member] = true
+			fmt.Fprintf(&b, "    [PgName(%s)]\n    %s,\n", csString(v), member)
+		}
+		b.WriteString("}\n")
+		files = append(files, File{Name: w.enums[e.Name] + ".cs", Content: []byte(b.String())})
+	}
+	for i := range schema.Tables {
+		t := &schema.Tables[i]
+		files = append(files, File{Name: w.classes[t.Name] + ".cs", Content: []byte(w.entity(t))})
+	}
+	files = append(files, File{Name: "AppDbContext.cs", Content: []byte(w.context())})
+	return files, nil
+}
+
+// related reports whether both sides of a relation are entities with a
+// key, which keyless entities can't be navigated to or from.
+func (w *efcoreWriter) related(rel Relation) bool {
+	return w.schema.Table(rel.Table).PrimaryKey != nil && w.schema.Table(rel.Other).PrimaryKey != nil
+}
+
+// csType returns the C# type of a column, or false when Npgsql has no
+// mapping for it.
+func (w *efcoreWriter) csType(col Column) (string, bool) {
+	udt := col.ElementType()
+	typ, ok := csTypes[udt]
+	if enum, isEnum := w.enums[udt]; isEnum {
+		typ, ok = enum, true
+	}
+	if !ok {
+		return "", false
+	}
+	if col.IsArray() {
+		typ = "List<" + typ + ">"
+	}
+	return typ, true
+}
+
+func (w *efcoreWriter) entity(t *Table) string {
+	properties := w.properties[t.Name]
+	var b strings.Builder
+	b.WriteString("// Generated by schema transform. Do not edit.\n")
+	fmt.Fprintf(&b, "namespace %s;\n\n", w.namespace)
+	if t.Comment != "" {
+		b.WriteString(csSummary(t.Comment, ""))
+	}
+	fmt.Fprintf(&b, "public partial class %s\n{\n", w.classes[t.Name])
+	var members []string
+	for _, col := range t.Columns {
+		typ, ok := w.csType(col)
+		if !ok {
+			members = append(members, fmt.Sprintf("    // %s %s has no .NET type and is left out.\n", col.ColumnName, col.FullType))
+			continue
+		}
+		var m strings.Builder
+		if col.Comment != "" {
+			m.WriteString(csSummary(col.Comment, "    "))
+		}
+		reference := col.IsArray() || csReferenceTypes[typ]
+		switch {
+		case col.Nullable():
+			fmt.Fprintf(&m, "    public %s? %s { get; set; }\n", typ, properties[col.ColumnName])
+		case reference:
+			fmt.Fprintf(&m, "    public %s %s { get; set; } = null!;\n", typ, properties[col.ColumnName])
+		default:
+			fmt.Fprintf(&m, "    public %s %s { get; set; }\n", typ, properties[col.ColumnName])
+		}
+		members = append(members, m.String())
+	}
+	for _, rel := range w.schema.Relations(t.Name) {
+		if !w.related(rel) {
+			continue
+		}
+		name := w.navigations[t.Name][relationKey(rel.Owner, rel.Other, rel.ForeignKey.ConstraintName)]
+		other := w.classes[rel.Other]
+		switch {
+		case rel.Owner && !anyNullable(t, rel.ForeignKey.SourceColumns):
+			members = append(members, fmt.Sprintf("    public virtual %s %s { get; set; } = null!;\n", other, name))
+		case rel.Many:
+			members = append(members, fmt.Sprintf("    public virtual ICollection<%s> %s { get; set; } = new List<%s>();\n", other, name, other))
+		default:
+			members = append(members, fmt.Sprintf("    public virtual %s? %s { get; set; }\n", other, name))
+		}
+	}
+	b.WriteString(strings.Join(members, "\n"))
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// lambda renders the properties of a table as the lambda EF Core selects
+// keys and indexes with, or false when a column isn't mapped.
+func (w *efcoreWriter) lambda(t *Table, param string, columns []string) (string, bool) {
+	var names []string
+	for _, c := range columns {
+		col := t.Column(c)
+		if col == nil {
+			return "", false
+		}
+		if _, ok := w.csType(*col); !ok {
+			return "", false
+		}
+		names = append(names, param+"."+w.properties[t.Name][c])
+	}
+	if len(names) == 1 {
+		return param + " => " + names[0], true
+	}
+	return param + " => new { " + strings.Join(names, ", ") + " }", true
+}
+
+func (w *efcoreWriter) context() string {
+	var b strings.Builder
+	b.WriteString("// Generated by schema transform. Do not edit.\nusing Microsoft.EntityFrameworkCore;\n\n")
+	fmt.Fprintf(&b, "namespace %s;\n\n", w.namespace)
+	b.WriteString("public partial class AppDbContext : DbContext\n{\n")
+	b.WriteString("    public AppDbContext(DbContextOptions<AppDbContext> options)\n        : base(options)\n    {\n    }\n\n")
+	used := map[string]bool{"Database": true, "Model": true, "ChangeTracker": true, "ContextId": true}
+	for _, t := range w.schema.Tables {
+		name := pascalCase(plural(singular(t.Name)))
+		if name == "" || name[0] >= '0' && name[0] <= '9' {
+			name = "T" + name
+		}
+		for used[name] {
+			name += "Set"
+		}
+		used[name] = true
+		fmt.Fprintf(&b, "    public virtual DbSet<%s> %s { get; set; }\n\n", w.classes[t.Name], name)
+	}
+	b.WriteString("    protected override void OnModelCreating(ModelBuilder modelBuilder)\n    {\n")
+	if len(w.schema.Enums) > 0 {
+		b.WriteString("        // Npgsql reads and writes the enums once they're also mapped on the\n")
+		b.WriteString("        // data source, e.g. dataSourceBuilder.MapEnum<Mood>(\"mood\").\n")
+	}
+	for _, e := range w.schema.Enums {
+		fmt.Fprintf(&b, "        modelBuilder.HasPostgresEnum<%s>(name: %s);\n", w.enums[e.Name], csString(e.Name))
+	}
+	if len(w.schema.Enums) > 0 {
+		b.WriteString("\n")
+	}
+	for i := range w.schema.Tables {
+		b.WriteString(w.configuration(&w.schema.Tables[i]))
+	}
+	b.WriteString("        OnModelCreatingPartial(modelBuilder);\n    }\n\n")
+	b.WriteString("    partial void OnModelCreatingPartial(ModelBuilder modelBuilder);\n}\n")
+	return b.String()
+}
+
+// configuration renders the modelBuilder.Entity block of a table.
+func (w *efcoreWriter) configuration(t *Table) string {
+	class := w.classes[t.Name]
+	properties := w.properties[t.Name]
+	var lines []string
+	add := func(format string, args ...any) {
+		lines = append(lines, fmt.Sprintf(format, args...))
+	}
+
+	if t.PrimaryKey == nil {
+		add("entity.HasNoKey();")
+	} else if key, ok := w.lambda(t, "e", t.PrimaryKey.Columns); ok {
+		add("entity.HasKey(%s).HasName(%s);", key, csString(t.PrimaryKey.ConstraintName))
+	}
+	var table []string
+	if t.Comment != "" {
+		table = append(table, fmt.Sprintf("tb.HasComment(%s)", csString(t.Comment)))
+	}
+	for _, c := range t.Checks {
+		table = append(table, fmt.Sprintf("tb.HasCheckConstraint(%s, %s)", csString(c.ConstraintName), csString(c.Expression)))
+	}
+	switch len(table) {
+	case 0:
+		add("entity.ToTable(%s);", csString(t.Name))
+	case 1:
+		add("entity.ToTable(%s, tb => %s);", csString(t.Name), table[0])
+	default:
+		add("entity.ToTable(%s, tb =>\n            {\n                %s;\n            });", csString(t.Name), strings.Join(table, ";\n                "))
+	}
+
+	for _, u := range t.Uniques {
+		if columns, ok := w.lambda(t, "e", u.Columns); ok {
+			add("entity.HasIndex(%s, %s).IsUnique();", columns, csString(u.ConstraintName))
+		}
+	}
+	for _, idx := range t.Indexes {
+		columns, ok := w.lambda(t, "e", idx.Columns)
+		if !ok || len(idx.Columns) == 0 {
+			add("// Not mapped: %s", oneLine(idx.Definition))
+			continue
+		}
+		index := fmt.Sprintf("entity.HasIndex(%s, %s)", columns, csString(idx.Name))
+		if idx.Unique {
+			index += ".IsUnique()"
+		}
+		if idx.Method != "" && idx.Method != "btree" {
+			index += fmt.Sprintf(".HasMethod(%s)", csString(idx.Method))
+		}
+		if idx.Where != "" {
+			index += fmt.Sprintf(".HasFilter(%s)", csString(idx.Where))
+		}
+		add("%s;", index)
+	}
+
+	for _, col := range t.Columns {
+		if _, ok := w.csType(col); !ok {
+			continue
+		}
+		property := fmt.Sprintf("entity.Property(e => e.%s)", properties[col.ColumnName])
+		udt := col.ElementType()
+		size, scale, sized := columnModifiers(col)
+		_, isEnum := w.enums[udt]
+		switch {
+		case isEnum:
+		case col.IsArray():
+			property += fmt.Sprintf(".HasColumnType(%s)", csString(col.FullType))
+		case (udt == "varchar" || udt == "bpchar") && sized:
+			property += fmt.Sprintf(".HasMaxLength(%d)", size)
+			if udt == "bpchar" {
+				property += ".IsFixedLength()"
+			}
+		case udt == "numeric" && sized:
+			property += fmt.Sprintf(".HasPrecision(%d, %d)", size, scale)
+		case slices.Contains([]string{"varchar", "bpchar", "citext", "json", "jsonb", "timestamp", "timetz", "money", "macaddr"}, udt):
+			property += fmt.Sprintf(".HasColumnType(%s)", csString(col.FullType))
+		}
+		kind, _ := parseDefault(col.Default)
+		switch {
+		case col.IsIdentity && col.IdentityGeneration == "ALWAYS":
+			property += ".UseIdentityAlwaysColumn()"
+		case col.IsIdentity:
+			property += ".UseIdentityByDefaultColumn()"
+		case col.IsSerial():
+			property += ".UseSerialColumn()"
+		case kind != NoDefault:
+			property += fmt.Sprintf(".HasDefaultValueSql(%s)", csString(col.Default))
+		}
+		property += fmt.Sprintf(".HasColumnName(%s)", csString(col.ColumnName))
+		if col.Comment != "" {
+			property += fmt.Sprintf(".HasComment(%s)", csString(col.Comment))
+		}
+		add("%s;", property)
+	}
+
+	// Relationships are configured on the dependent side, as scaffolding
+	// does.
+	for _, rel := range w.schema.Relations(t.Name) {
+		if !rel.Owner || !w.related(rel) {
+			continue
+		}
+		fk := rel.ForeignKey
+		foreignKey, ok := w.lambda(t, "d", fk.SourceColumns)
+		if !ok {
+			continue
+		}
+		other := w.classes[rel.Other]
+		target := w.schema.Table(rel.Other)
+		navigation := w.navigations[t.Name][relationKey(true, rel.Other, fk.ConstraintName)]
+		inverse := w.navigations[rel.Other][relationKey(false, t.Name, fk.ConstraintName)]
+		oneToOne := isUniqueColumns(t, fk.SourceColumns)
+
+		relationship := fmt.Sprintf("entity.HasOne(d => d.%s)", navigation)
+		if oneToOne {
+			relationship += fmt.Sprintf(".WithOne(p => p.%s)", inverse)
+		} else {
+			relationship += fmt.Sprintf(".WithMany(p => p.%s)", inverse)
+		}
+		if !slices.Equal(fk.TargetColumns, target.PrimaryKey.Columns) {
+			if principal, ok := w.lambda(target, "p", fk.TargetColumns); ok {
+				if oneToOne {
+					relationship += fmt.Sprintf("\n                .HasPrincipalKey<%s>(%s)", other, principal)
+				} else {
+					relationship += fmt.Sprintf("\n                .HasPrincipalKey(%s)", principal)
+				}
+			}
+		}
+		if oneToOne {
+			relationship += fmt.Sprintf("\n                .HasForeignKey<%s>(%s)", class, foreignKey)
+		} else {
+			relationship += fmt.Sprintf("\n                .HasForeignKey(%s)", foreignKey)
+		}
+		if behavior, ok := efDeleteBehaviors[fk.OnDelete]; ok {
+			relationship += "\n                .OnDelete(" + behavior + ")"
+		}
+		relationship += fmt.Sprintf("\n                .HasConstraintName(%s);", csString(fk.ConstraintName))
+		add("%s", relationship)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "        modelBuilder.Entity<%s>(entity =>\n        {\n", class)
+	for i, line := range lines {
+		// A blank line sets the properties and relationships apart from
+		// the keys and table, as scaffolding does.
+		if i > 0 && strings.HasPrefix(line, "entity.Property") != strings.HasPrefix(lines[i-1], "entity.Property") {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "            %s\n", line)
+	}
+	b.WriteString("        });\n\n")
+	return b.String()
+}
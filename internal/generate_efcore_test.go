@@ -0,0 +1,8 @@
+package internal
+
+import "testing"
+
+func TestGenerateEFCore(t *testing.T) {
+	generateGolden(t, generateEFCore, "efcore",
+		"PostStatus.cs", "User.cs", "Post.cs", "Tag.cs", "PostTag.cs", "AppDbContext.cs")
+}
@@ -0,0 +1,532 @@
+package internal
+
+import (
+	"fmt"
+	"go/format"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// entReserved are the names of ent's generated code that schemas can't
+// take.
+var entReserved = map[string]bool{
+	"Client": true, "Config": true, "Hook": true, "Interceptor": true, "Mutation": true,
+	"Noder": true, "Policy": true, "Query": true, "Tx": true, "Value": true,
+}
+
+var entOnDelete = map[string]string{
+	"CASCADE":     "entsql.Cascade",
+	"SET NULL":    "entsql.SetNull",
+	"SET DEFAULT": "entsql.SetDefault",
+	"RESTRICT":    "entsql.Restrict",
+}
+
+var entEnumValue = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+type entWriter struct {
+	schema *Schema
+	// types maps the tables ent can map to their schemas: those with a
+	// single column primary key, and the join tables keyed by two foreign
+	// keys, which become edge schemas.
+	types map[string]string
+	joins map[string][2]ForeignKey
+	// fields maps each table's columns to their fields, edges its
+	// relations by relationKey, and the join tables it is joined through by
+	// name, to their edges.
+	fields  map[string]map[string]string
+	edges   map[string]map[string]string
+	through map[string]map[string]string
+}
+
+// entFile is the schema file of a table and the imports it needs.
+type entFile struct {
+	imports map[string]bool
+}
+
+func (f *entFile) use(pkg string) {
+	f.imports[pkg] = true
+}
+
+// generateEnt renders the schema as entgo.io schemas, one file per table in
+// the schema package, to run ent's codegen against an existing database:
+// fields with their types, Optional and Nillable for nullable columns,
+// defaults, Unique and StorageKey, edges for both sides of each foreign key
+// to a primary key, indexes, and the table name and CHECK constraints as
+// entsql annotations. Join tables keyed by two foreign keys become edge
+// schemas the tables they join go through. ent needs an id, so tables
+// without a primary key, or with another composite one, are skipped.
+func generateEnt(schema *Schema, opts GenerateOptions) ([]File, error) {
+	w := &entWriter{
+		schema:  schema,
+		types:   make(map[string]string),
+		joins:   make(map[string][2]ForeignKey),
+		fields:  make(map[string]map[string]string),
+		edges:   make(map[string]map[string]string),
+		through: make(map[string]map[string]string),
+	}
+	pkg := opts.Package
+	if pkg == "" {
+		pkg = "schema"
+	}
+	taken := make(map[string]bool)
+	for name := range entReserved {
+		taken[name] = true
+	}
+	reserve := func(t Table) {
+		name := goName(singular(t.Name))
+		for taken[name] {
+			name += "Row"
+		}
+		taken[name] = true
+		w.types[t.Name] = name
+	}
+	for _, t := range schema.Tables {
+		if t.PrimaryKey != nil && len(t.PrimaryKey.Columns) == 1 {
+			reserve(t)
+		}
+	}
+	for i := range schema.Tables {
+		t := &schema.Tables[i]
+		if t.PrimaryKey == nil || len(t.PrimaryKey.Columns) != 2 {
+			continue
+		}
+		var fks [2]ForeignKey
+		found := 0
+		for n, column := range t.PrimaryKey.Columns {
+			for _, fk := range t.ForeignKeys {
+				if slices.Equal(fk.SourceColumns, []string{column}) && w.edgeable(fk) {
+					fks[n] = fk
+					found++
+					break
+				}
+			}
+		}
+		if found == 2 {
+			w.joins[t.Name] = fks
+			reserve(*t)
+		}
+	}
+
+	for _, t := range schema.Tables {
+		if w.types[t.Name] == "" {
+			continue
+		}
+		used := make(map[string]bool)
+		w.fields[t.Name] = make(map[string]string)
+		_, join := w.joins[t.Name]
+		for _, col := range t.Columns {
+			name := snakeCase(col.ColumnName)
+			if name == "" || name[0] >= '0' && name[0] <= '9' {
+				name = "f_" + name
+			}
+			if !join && t.IsPrimaryKey(col.ColumnName) {
+				name = "id"
+			} else if name == "id" || name == "config" || name == "edges" {
+				name += "_column"
+			}
+			for used[name] {
+				name += "_"
+			}
+			used[name] = true
+			w.fields[t.Name][col.ColumnName] = name
+		}
+		edge := func(base string) string {
+			for used[base] {
+				base += "_ref"
+			}
+			used[base] = true
+			return base
+		}
+		w.edges[t.Name] = make(map[string]string)
+		for _, rel := range schema.Relations(t.Name) {
+			// The edges to join tables are named too, as the edges
+			// through them.
+			if w.types[rel.Other] != "" && w.edgeable(rel.ForeignKey) {
+				w.edges[t.Name][relationKey(rel.Owner, rel.Other, rel.ForeignKey.ConstraintName)] = edge(rel.Name)
+			}
+		}
+		w.through[t.Name] = make(map[string]string)
+		for _, join := range schema.Tables {
+			fks, ok := w.joins[join.Name]
+			if !ok {
+				continue
+			}
+			if fks[0].TargetTable == t.Name {
+				w.through[t.Name][join.Name+" to"] = edge(plural(ownerRelationName(fks[1])))
+			}
+			if fks[1].TargetTable == t.Name {
+				w.through[t.Name][join.Name+" from"] = edge(plural(ownerRelationName(fks[0])))
+			}
+		}
+	}
+
+	var files []File
+	for i := range schema.Tables {
+		t := &schema.Tables[i]
+		if w.types[t.Name] == "" {
+			continue
+		}
+		content, err := w.file(t, pkg)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, File{Name: strings.ToLower(w.types[t.Name]) + ".go", Content: content})
+	}
+	return files, nil
+}
+
+// edgeable reports whether ent can make an edge of a foreign key: a single
+// column of the same type referencing the id of a table with a schema.
+func (w *entWriter) edgeable(fk ForeignKey) bool {
+	target := w.schema.Table(fk.TargetTable)
+	_, join := w.joins[fk.TargetTable]
+	if len(fk.SourceColumns) != 1 || target == nil || w.types[target.Name] == "" || join {
+		return false
+	}
+	if target.PrimaryKey == nil || !slices.Equal(target.PrimaryKey.Columns, fk.TargetColumns) {
+		return false
+	}
+	source := w.schema.Table(fk.SourceTable).Column(fk.SourceColumns[0])
+	return source != nil && source.UDTName == target.Column(fk.TargetColumns[0]).UDTName
+}
+
+// related reports whether a relation is an edge: both tables have schemas,
+// and the foreign key isn't one of a join table's keys, which the tables it
+// joins go through instead.
+func (w *entWriter) related(rel Relation) bool {
+	if w.types[rel.Table] == "" || w.types[rel.Other] == "" || !w.edgeable(rel.ForeignKey) {
+		return false
+	}
+	return rel.Owner || !w.joinKey(rel.ForeignKey)
+}
+
+// joinKey reports whether a foreign key is one of a join table's keys.
+func (w *entWriter) joinKey(fk ForeignKey) bool {
+	fks, ok := w.joins[fk.SourceTable]
+	return ok && (fks[0].ConstraintName == fk.ConstraintName || fks[1].ConstraintName == fk.ConstraintName)
+}
+
+// file renders the schema file of a table.
+func (w *entWriter) file(t *Table, pkg string) ([]byte, error) {
+	f := &entFile{imports: map[string]bool{"entgo.io/ent": true}}
+	name := w.types[t.Name]
+	var b strings.Builder
+	fmt.Fprintf(&b, "// %s holds the schema definition of the %s entity, stored in the %s table.\n", name, name, t.Name)
+	if t.Comment != "" {
+		fmt.Fprintf(&b, "//\n// %s\n", oneLine(t.Comment))
+	}
+	fmt.Fprintf(&b, "type %s struct {\n\tent.Schema\n}\n", name)
+
+	f.use("entgo.io/ent/schema/field")
+	fmt.Fprintf(&b, "\n// Fields of the %s.\nfunc (%s) Fields() []ent.Field {\n\treturn []ent.Field{\n", name, name)
+	for _, col := range t.Columns {
+		fmt.Fprintf(&b, "\t\t%s,\n", w.field(f, t, col))
+	}
+	b.WriteString("\t}\n}\n")
+
+	if edges := w.entEdges(f, t); len(edges) > 0 {
+		f.use("entgo.io/ent/schema/edge")
+		fmt.Fprintf(&b, "\n// Edges of the %s.\nfunc (%s) Edges() []ent.Edge {\n\treturn []ent.Edge{\n", name, name)
+		for _, e := range edges {
+			fmt.Fprintf(&b, "\t\t%s,\n", e)
+		}
+		b.WriteString("\t}\n}\n")
+	}
+
+	if indexes := w.indexes(f, t); len(indexes) > 0 {
+		f.use("entgo.io/ent/schema/index")
+		fmt.Fprintf(&b, "\n// Indexes of the %s.\nfunc (%s) Indexes() []ent.Index {\n\treturn []ent.Index{\n", name, name)
+		for _, idx := range indexes {
+			fmt.Fprintf(&b, "\t\t%s,\n", idx)
+		}
+		b.WriteString("\t}\n}\n")
+	}
+
+	f.use("entgo.io/ent/schema")
+	f.use("entgo.io/ent/dialect/entsql")
+	fmt.Fprintf(&b, "\n// Annotations of the %s.\nfunc (%s) Annotations() []schema.Annotation {\n\treturn []schema.Annotation{\n", name, name)
+	if len(t.Checks) > 0 {
+		fmt.Fprintf(&b, "\t\tentsql.Annotation{\n\t\t\tTable: %q,\n\t\t\tChecks: map[string]string{\n", t.Name)
+		for _, c := range t.Checks {
+			fmt.Fprintf(&b, "\t\t\t\t%q: %q,\n", c.ConstraintName, c.Expression)
+		}
+		b.WriteString("\t\t\t},\n\t\t},\n")
+	} else {
+		fmt.Fprintf(&b, "\t\tentsql.Annotation{Table: %q},\n", t.Name)
+	}
+	if t.Comment != "" {
+		fmt.Fprintf(&b, "\t\tschema.Comment(%q),\n", oneLine(t.Comment))
+	}
+	if fks, ok := w.joins[t.Name]; ok {
+		fmt.Fprintf(&b, "\t\tfield.ID(%q, %q),\n", w.fields[t.Name][fks[0].SourceColumns[0]], w.fields[t.Name][fks[1].SourceColumns[0]])
+	}
+	b.WriteString("\t}\n}\n")
+
+	var std, other []string
+	for pkg := range f.imports {
+		if strings.Contains(pkg, ".") {
+			other = append(other, strconv.Quote(pkg))
+		} else {
+			std = append(std, strconv.Quote(pkg))
+		}
+	}
+	slices.Sort(std)
+	slices.Sort(other)
+	imports := strings.Join(other, "\n")
+	if len(std) > 0 {
+		imports = strings.Join(std, "\n") + "\n\n" + imports
+	}
+	source := fmt.Sprintf("// Code generated by schema transform. DO NOT EDIT.\n\npackage %s\n\nimport (\n%s\n)\n\n%s", pkg, imports, b.String())
+	formatted, err := format.Source([]byte(source))
+	if err != nil {
+		return nil, fmt.Errorf("failed to format the %s schema: %w", name, err)
+	}
+	return formatted, nil
+}
+
+// schemaType returns the SchemaType option setting a column's Postgres type.
+func (f *entFile) schemaType(typ string) string {
+	f.use("entgo.io/ent/dialect")
+	return fmt.Sprintf(".SchemaType(map[string]string{dialect.Postgres: %q})", typ)
+}
+
+// field renders the field of a column. The primary key is the id field,
+// stored in its column.
+func (w *entWriter) field(f *entFile, t *Table, col Column) string {
+	name := w.fields[t.Name][col.ColumnName]
+	var b strings.Builder
+	// kind is the field's builder, which decides the defaults it takes.
+	var kind string
+	switch {
+	case col.IsArray():
+		typ, pkg := goType(col, w.schema)
+		f.use(pkg)
+		kind = "Other"
+		fmt.Fprintf(&b, "field.Other(%q, %s{})%s", name, typ, f.schemaType(col.FullType))
+	case w.schema.Enum(col.UDTName) != nil:
+		kind = "Enum"
+		e := w.schema.Enum(col.UDTName)
+		fmt.Fprintf(&b, "field.Enum(%q)", name)
+		named := false
+		for _, v := range e.Values {
+			named = named || !entEnumValue.MatchString(v)
+		}
+		if named {
+			var pairs []string
+			used := make(map[string]bool)
+			for _, v := range e.Values {
+				n := goName(v)
+				for used[n] {
+					n += "_"
+				}
+				used[n] = true
+				pairs = append(pairs, strconv.Quote(n), strconv.Quote(v))
+			}
+			fmt.Fprintf(&b, ".NamedValues(%s)", strings.Join(pairs, ", "))
+		} else {
+			fmt.Fprintf(&b, ".Values(%s)", strings.Join(tsStrings(e.Values), ", "))
+		}
+		b.WriteString(f.schemaType(quoteIdent(e.Name)))
+	default:
+		kind = w.fieldKind(col)
+		switch kind {
+		case "UUID":
+			f.use("github.com/google/uuid")
+			fmt.Fprintf(&b, "field.UUID(%q, uuid.UUID{})", name)
+		case "JSON":
+			f.use("encoding/json")
+			fmt.Fprintf(&b, "field.JSON(%q, json.RawMessage{})", name)
+		default:
+			fmt.Fprintf(&b, "field.%s(%q)", kind, name)
+		}
+		switch col.UDTName {
+		case "varchar":
+			if length, _, ok := columnModifiers(col); ok {
+				fmt.Fprintf(&b, ".MaxLen(%d)", length)
+			}
+		case "int8", "float4", "float8", "bool", "text", "uuid", "jsonb", "timestamptz", "bytea":
+		default:
+			b.WriteString(f.schemaType(col.FullType))
+		}
+	}
+
+	id := name == "id" && t.IsPrimaryKey(col.ColumnName)
+	if col.Nullable() {
+		b.WriteString(".Optional().Nillable()")
+	}
+	if !id && !t.IsPrimaryKey(col.ColumnName) && isUniqueColumns(t, []string{col.ColumnName}) {
+		b.WriteString(".Unique()")
+	}
+	if !(id && (col.IsSerial() || col.IsIdentity)) {
+		b.WriteString(w.defaultValue(f, col, kind))
+	}
+	if name != col.ColumnName {
+		fmt.Fprintf(&b, ".StorageKey(%q)", col.ColumnName)
+	}
+	if col.Comment != "" {
+		fmt.Fprintf(&b, ".Comment(%q)", oneLine(col.Comment))
+	}
+	return b.String()
+}
+
+// fieldKind returns the field builder of a scalar column.
+func (w *entWriter) fieldKind(col Column) string {
+	switch col.UDTName {
+	case "int2", "int4":
+		// ids are ints, and so are the fields of edges to them.
+		return "Int"
+	case "int8":
+		return "Int64"
+	case "float4":
+		return "Float32"
+	case "float8", "numeric":
+		return "Float"
+	case "bool":
+		return "Bool"
+	case "text":
+		return "Text"
+	case "uuid":
+		return "UUID"
+	case "json", "jsonb":
+		return "JSON"
+	case "timestamp", "timestamptz", "date":
+		return "Time"
+	case "bytea":
+		return "Bytes"
+	}
+	return "String"
+}
+
+// defaultValue renders the Default option of a column, or the default
+// expression ent has the database apply.
+func (w *entWriter) defaultValue(f *entFile, col Column, kind string) string {
+	def, value := parseDefault(col.Default)
+	switch {
+	case def == NoDefault:
+		return ""
+	case def == BoolDefault && kind == "Bool":
+		return ".Default(" + value + ")"
+	case def == NumberDefault && strings.HasPrefix(kind, "Int") && !strings.ContainsAny(value, ".eE"),
+		def == NumberDefault && strings.HasPrefix(kind, "Float"):
+		return ".Default(" + value + ")"
+	case def == StringDefault && (kind == "String" || kind == "Text" || kind == "Enum"):
+		return ".Default(" + strconv.Quote(value) + ")"
+	case def == NowDefault && kind == "Time":
+		f.use("time")
+		return ".Default(time.Now)"
+	case def == UUIDDefault && kind == "UUID":
+		return ".Default(uuid.New)"
+	}
+	f.use("entgo.io/ent/dialect/entsql")
+	return fmt.Sprintf(".Annotations(entsql.DefaultExpr(%q))", strings.TrimSpace(col.Default))
+}
+
+// entEdges renders the edges of a table. The table referenced by a foreign
+// key has the To edge and the one holding it the From edge, bound to the
+// foreign key field; an edge schema has To edges bound to its keys, and
+// the tables it joins a To and a From edge through it.
+func (w *entWriter) entEdges(f *entFile, t *Table) []string {
+	var edges []string
+	_, join := w.joins[t.Name]
+	for _, rel := range w.schema.Relations(t.Name) {
+		if !w.related(rel) {
+			continue
+		}
+		fk := rel.ForeignKey
+		name := w.edges[t.Name][relationKey(rel.Owner, rel.Other, fk.ConstraintName)]
+		other := w.types[rel.Other]
+		var e string
+		switch {
+		case rel.Owner && join && w.joinKey(fk):
+			e = fmt.Sprintf("edge.To(%q, %s.Type).Unique().Required().Field(%q)", name, other, w.fields[t.Name][fk.SourceColumns[0]])
+			e += w.onDelete(f, fk)
+		case rel.Owner:
+			ref := w.edges[rel.Other][relationKey(false, t.Name, fk.ConstraintName)]
+			e = fmt.Sprintf("edge.From(%q, %s.Type).Ref(%q).Field(%q).Unique()", name, other, ref, w.fields[t.Name][fk.SourceColumns[0]])
+			if !anyNullable(t, fk.SourceColumns) {
+				e += ".Required()"
+			}
+		default:
+			e = fmt.Sprintf("edge.To(%q, %s.Type)", name, other)
+			if !rel.Many {
+				e += ".Unique()"
+			}
+			e += w.onDelete(f, fk)
+		}
+		edges = append(edges, e)
+	}
+	for _, j := range w.schema.Tables {
+		fks, ok := w.joins[j.Name]
+		if !ok {
+			continue
+		}
+		if name, ok := w.through[t.Name][j.Name+" to"]; ok {
+			via := w.edges[t.Name][relationKey(false, j.Name, fks[0].ConstraintName)]
+			edges = append(edges, fmt.Sprintf("edge.To(%q, %s.Type).Through(%q, %s.Type)", name, w.types[fks[1].TargetTable], via, w.types[j.Name]))
+		}
+		if name, ok := w.through[t.Name][j.Name+" from"]; ok {
+			via := w.edges[t.Name][relationKey(false, j.Name, fks[1].ConstraintName)]
+			ref := w.through[fks[0].TargetTable][j.Name+" to"]
+			edges = append(edges, fmt.Sprintf("edge.From(%q, %s.Type).Ref(%q).Through(%q, %s.Type)", name, w.types[fks[0].TargetTable], ref, via, w.types[j.Name]))
+		}
+	}
+	return edges
+}
+
+// onDelete renders the annotation of a foreign key's ON DELETE action.
+func (w *entWriter) onDelete(f *entFile, fk ForeignKey) string {
+	action, ok := entOnDelete[fk.OnDelete]
+	if !ok {
+		return ""
+	}
+	f.use("entgo.io/ent/dialect/entsql")
+	return ".Annotations(entsql.OnDelete(" + action + "))"
+}
+
+// indexes renders the composite unique constraints and the indexes of a
+// table; single column unique ones are Unique fields. Expression indexes
+// aren't on fields and are left out.
+func (w *entWriter) indexes(f *entFile, t *Table) []string {
+	var indexes []string
+	add := func(name string, columns []string, unique bool, method, where string) {
+		var fields []string
+		for _, c := range columns {
+			field, ok := w.fields[t.Name][c]
+			if !ok {
+				return
+			}
+			fields = append(fields, strconv.Quote(field))
+		}
+		idx := "index.Fields(" + strings.Join(fields, ", ") + ")"
+		if unique {
+			idx += ".Unique()"
+		}
+		idx += ".StorageKey(" + strconv.Quote(name) + ")"
+		var annotations []string
+		if where != "" {
+			annotations = append(annotations, fmt.Sprintf("entsql.IndexWhere(%q)", where))
+		}
+		if method != "" && method != "btree" {
+			f.use("entgo.io/ent/dialect")
+			annotations = append(annotations, fmt.Sprintf("entsql.IndexTypes(map[string]string{dialect.Postgres: %q})", strings.ToUpper(method)))
+		}
+		if len(annotations) > 0 {
+			f.use("entgo.io/ent/dialect/entsql")
+			idx += ".Annotations(" + strings.Join(annotations, ", ") + ")"
+		}
+		indexes = append(indexes, idx)
+	}
+	for _, u := range t.Uniques {
+		if len(u.Columns) > 1 {
+			add(u.ConstraintName, u.Columns, true, "", "")
+		}
+	}
+	for _, idx := range t.Indexes {
+		if !(idx.Unique && idx.Where == "" && len(idx.Columns) == 1) {
+			add(idx.Name, idx.Columns, idx.Unique, idx.Method, idx.Where)
+		}
+	}
+	return indexes
+}
@@ -0,0 +1,34 @@
+package internal
+
+import (
+	"go/format"
+	"slices"
+	"testing"
+)
+
+func TestGenerateEnt(t *testing.T) {
+	files := generateGolden(t, generateEnt, "ent", "user.go", "post.go", "tag.go", "posttag.go")
+	for _, f := range files {
+		formatted, err := format.Source(f.Content)
+		if err != nil {
+			t.Fatalf("%s doesn't parse: %v", f.Name, err)
+		}
+		if string(formatted) != string(f.Content) {
+			t.Errorf("%s is not gofmt formatted", f.Name)
+		}
+	}
+}
+
+func TestGenerateEntSkipsTablesWithoutID(t *testing.T) {
+	schema := blogSchemaWith(
+		addTable(Table{Name: "audit_log", Columns: []Column{column("message", "text", false)}}),
+		alterTable("post_tags", func(t *Table) { t.ForeignKeys = nil }),
+	)
+	files, err := generateEnt(schema, GenerateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"user.go", "post.go", "tag.go"}; !slices.Equal(fileNames(files), want) {
+		t.Errorf("files = %q, want %q", fileNames(files), want)
+	}
+}
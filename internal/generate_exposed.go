@@ -0,0 +1,611 @@
+package internal
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// exposedColumn is the Exposed column function and Kotlin type of a udt.
+type exposedColumn struct {
+	function string
+	typ      string
+}
+
+var exposedColumns = map[string]exposedColumn{
+	"int2": {"short", "Short"}, "int4": {"integer", "Int"}, "int8": {"long", "Long"},
+	"float4": {"float", "Float"}, "float8": {"double", "Double"},
+	"numeric": {"decimal", "BigDecimal"}, "bool": {"bool", "Boolean"},
+	"varchar": {"varchar", "String"}, "bpchar": {"char", "String"}, "text": {"text", "String"},
+	"citext": {"text", "String"}, "uuid": {"uuid", "UUID"}, "json": {"json", "String"},
+	"jsonb": {"jsonb", "String"}, "date": {"date", "LocalDate"}, "timestamp": {"datetime", "LocalDateTime"},
+	"timestamptz": {"timestampWithTimeZone", "OffsetDateTime"}, "time": {"time", "LocalTime"},
+	"bytea": {"binary", "ByteArray"},
+}
+
+// exposedImports holds the imports of the names the generated code uses.
+var exposedImports = map[string]string{
+	"BigDecimal": "java.math.BigDecimal", "UUID": "java.util.UUID",
+	"LocalDate": "java.time.LocalDate", "LocalDateTime": "java.time.LocalDateTime",
+	"OffsetDateTime": "java.time.OffsetDateTime", "LocalTime": "java.time.LocalTime",
+	"Table": "org.jetbrains.exposed.sql.Table", "ReferenceOption": "org.jetbrains.exposed.sql.ReferenceOption",
+	"IdTable": "org.jetbrains.exposed.dao.id.IdTable", "IntIdTable": "org.jetbrains.exposed.dao.id.IntIdTable",
+	"LongIdTable": "org.jetbrains.exposed.dao.id.LongIdTable", "UUIDTable": "org.jetbrains.exposed.dao.id.UUIDTable",
+	"EntityID": "org.jetbrains.exposed.dao.id.EntityID", "Entity": "org.jetbrains.exposed.dao.Entity",
+	"EntityClass": "org.jetbrains.exposed.dao.EntityClass", "IntEntity": "org.jetbrains.exposed.dao.IntEntity",
+	"IntEntityClass": "org.jetbrains.exposed.dao.IntEntityClass", "LongEntity": "org.jetbrains.exposed.dao.LongEntity",
+	"LongEntityClass": "org.jetbrains.exposed.dao.LongEntityClass", "UUIDEntity": "org.jetbrains.exposed.dao.UUIDEntity",
+	"UUIDEntityClass": "org.jetbrains.exposed.dao.UUIDEntityClass",
+	"date":            "org.jetbrains.exposed.sql.javatime.date", "datetime": "org.jetbrains.exposed.sql.javatime.datetime",
+	"timestampWithTimeZone": "org.jetbrains.exposed.sql.javatime.timestampWithTimeZone",
+	"time":                  "org.jetbrains.exposed.sql.javatime.time", "CurrentDate": "org.jetbrains.exposed.sql.javatime.CurrentDate",
+	"CurrentDateTime":              "org.jetbrains.exposed.sql.javatime.CurrentDateTime",
+	"CurrentTimestampWithTimeZone": "org.jetbrains.exposed.sql.javatime.CurrentTimestampWithTimeZone",
+	"json":                         "org.jetbrains.exposed.sql.json.json", "jsonb": "org.jetbrains.exposed.sql.json.jsonb",
+	"PGobject": "org.postgresql.util.PGobject",
+}
+
+var kotlinKeywords = map[string]bool{
+	"as": true, "break": true, "class": true, "continue": true, "do": true, "else": true,
+	"false": true, "for": true, "fun": true, "if": true, "in": true, "interface": true,
+	"is": true, "null": true, "object": true, "package": true, "return": true, "super": true,
+	"this": true, "throw": true, "true": true, "try": true, "typealias": true, "typeof": true,
+	"val": true, "var": true, "when": true, "while": true,
+}
+
+// exposedMembers are the members of Exposed tables and entities that
+// properties must not override.
+var exposedMembers = map[string]bool{
+	"tableName": true, "columns": true, "primaryKey": true, "indices": true, "foreignKeys": true,
+	"ddl": true, "fields": true, "source": true, "schemaName": true, "realFields": true,
+	"autoIncColumn": true, "db": true, "klass": true, "readValues": true, "writeValues": true,
+	"flush": true, "delete": true, "refresh": true,
+}
+
+var exposedActions = map[string]string{
+	"CASCADE":     "ReferenceOption.CASCADE",
+	"SET NULL":    "ReferenceOption.SET_NULL",
+	"SET DEFAULT": "ReferenceOption.SET_DEFAULT",
+	"RESTRICT":    "ReferenceOption.RESTRICT",
+}
+
+// ktIdent turns a database name into a camelCase Kotlin property name,
+// backquoted when it's a keyword.
+func ktIdent(name string) string {
+	ident := camelCase(name)
+	if ident == "" || ident[0] >= '0' && ident[0] <= '9' {
+		ident = "_" + ident
+	}
+	if kotlinKeywords[ident] {
+		ident = "`" + ident + "`"
+	}
+	return ident
+}
+
+// ktString returns s as a Kotlin string literal.
+func ktString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\' || r == '$':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, `\u%04x`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+type exposedWriter struct {
+	schema *Schema
+	// objects maps tables to their table objects, entities the tables
+	// with a single column key to their DAO entity classes and idTypes
+	// to the Kotlin type of that key.
+	objects  map[string]string
+	entities map[string]string
+	idTypes  map[string]string
+	enums    map[string]string
+	// constants maps each enum's values to the Kotlin enum constants.
+	constants map[string]map[string]string
+	// properties maps each table's columns to their properties; the key
+	// column of an entity is its id.
+	properties map[string]map[string]string
+	imports    map[string]bool
+	order      *CreationOrder
+}
+
+// generateExposed renders the schema as Kotlin for JetBrains Exposed:
+// Models.kt with a Kotlin enum per enum type, a table object per table
+// (IntIdTable, LongIdTable, UUIDTable or IdTable when it has a single
+// column key, Table otherwise) and a DAO entity class per table with a
+// single column key, with references for both sides of its foreign keys.
+func generateExposed(schema *Schema, opts GenerateOptions) ([]File, error) {
+	w := &exposedWriter{
+		schema:     schema,
+		objects:    make(map[string]string),
+		entities:   make(map[string]string),
+		idTypes:    make(map[string]string),
+		enums:      make(map[string]string),
+		constants:  make(map[string]map[string]string),
+		properties: make(map[string]map[string]string),
+		imports:    make(map[string]bool),
+		order:      OrderSchema(schema),
+	}
+	taken := map[string]bool{"PGEnum": true}
+	for name := range exposedImports {
+		taken[name] = true
+	}
+	for _, name := range []string{"String", "Int", "Long", "Short", "Float", "Double", "Boolean", "ByteArray", "Any", "Unit", "Nothing", "Array", "List", "Pair"} {
+		taken[name] = true
+	}
+	reserve := func(name, suffix string) string {
+		if name == "" || name[0] >= '0' && name[0] <= '9' {
+			name = "T" + name
+		}
+		for taken[name] {
+			name += suffix
+		}
+		taken[name] = true
+		return name
+	}
+	for _, t := range schema.Tables {
+		w.objects[t.Name] = reserve(pascalCase(plural(singular(t.Name))), "Table")
+	}
+	for _, t := range schema.Tables {
+		if t.PrimaryKey == nil || len(t.PrimaryKey.Columns) != 1 {
+			continue
+		}
+		col := t.Column(t.PrimaryKey.Columns[0])
+		c, ok := exposedColumns[col.UDTName]
+		if !ok || col.IsArray() {
+			continue
+		}
+		w.idTypes[t.Name] = c.typ
+		w.entities[t.Name] = reserve(className(t.Name), "Entity")
+	}
+	for _, e := range schema.Enums {
+		w.enums[e.Name] = reserve(pascalCase(e.Name), "Enum")
+		w.constants[e.Name] = make(map[string]string)
+		used := make(map[string]bool)
+		for _, v := range e.Values {
+			constant := pythonConstant(v)
+			for used[constant] {
+				constant += "_"
+			}
+			used[constant] = true
+			w.constants[e.Name][v] = constant
+		}
+	}
+	for _, t := range schema.Tables {
+		_, entity := w.entities[t.Name]
+		used := map[string]bool{"id": entity}
+		properties := make(map[string]string)
+		for _, col := range t.Columns {
+			if entity && t.IsPrimaryKey(col.ColumnName) {
+				properties[col.ColumnName] = "id"
+				continue
+			}
+			name := ktIdent(col.ColumnName)
+			for used[name] || exposedMembers[name] {
+				name += "Column"
+			}
+			used[name] = true
+			properties[col.ColumnName] = name
+		}
+		w.properties[t.Name] = properties
+	}
+
+	var body strings.Builder
+	if len(schema.Enums) > 0 {
+		w.use("PGobject")
+		body.WriteString("\n/** Binds enum values as the Postgres enum type. */\n")
+		body.WriteString("class PGEnum(type: String, value: String?) : PGobject() {\n")
+		body.WriteString("    init {\n        this.type = type\n        this.value = value\n    }\n}\n")
+	}
+	for _, e := range schema.Enums {
+		name := w.enums[e.Name]
+		fmt.Fprintf(&body, "\nenum class %s(val value: String) {\n", name)
+		for i, v := range e.Values {
+			sep := ","
+			if i == len(e.Values)-1 {
+				sep = ";"
+			}
+			fmt.Fprintf(&body, "    %s(%s)%s\n", w.constants[e.Name][v], ktString(v), sep)
+		}
+		fmt.Fprintf(&body, "\n    companion object {\n        fun fromValue(value: String): %s = entries.first { it.value == value }\n    }\n}\n", name)
+	}
+	for i := range schema.Tables {
+		body.WriteString(w.table(&schema.Tables[i]))
+	}
+	for i := range schema.Tables {
+		if _, ok := w.entities[schema.Tables[i].Name]; ok {
+			body.WriteString(w.entity(&schema.Tables[i]))
+		}
+	}
+
+	pkg := opts.Package
+	if pkg == "" {
+		pkg = "models"
+	}
+	var b strings.Builder
+	b.WriteString("// Generated by schema transform. Do not edit.\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	var imports []string
+	for name := range w.imports {
+		imports = append(imports, exposedImports[name])
+	}
+	slices.Sort(imports)
+	for _, imp := range imports {
+		fmt.Fprintf(&b, "import %s\n", imp)
+	}
+	b.WriteString(body.String())
+	return []File{{Name: "Models.kt", Content: []byte(b.String())}}, nil
+}
+
+// use returns name, importing it.
+func (w *exposedWriter) use(name string) string {
+	w.imports[name] = true
+	return name
+}
+
+// reference returns the foreign key a column holds as an Exposed
+// reference to the id of an entity table, or nil.
+func (w *exposedWriter) reference(t *Table, column string) *ForeignKey {
+	fk := t.ForeignKeyFor(column)
+	if fk == nil || w.order.IsDeferred(*fk) || w.properties[t.Name][column] == "id" {
+		return nil
+	}
+	if _, ok := w.entities[fk.TargetTable]; !ok {
+		return nil
+	}
+	if target := w.schema.Table(fk.TargetTable); target.PrimaryKey.Columns[0] != fk.TargetColumns[0] {
+		return nil
+	}
+	return fk
+}
+
+func (w *exposedWriter) table(t *Table) string {
+	object := w.objects[t.Name]
+	properties := w.properties[t.Name]
+	var b strings.Builder
+	b.WriteString("\n")
+	if t.Comment != "" {
+		fmt.Fprintf(&b, "/** %s */\n", oneLine(t.Comment))
+	}
+
+	var members []string
+	if _, ok := w.entities[t.Name]; ok {
+		key := t.Column(t.PrimaryKey.Columns[0])
+		kind, _ := parseDefault(key.Default)
+		args := ktString(t.Name)
+		if key.ColumnName != "id" {
+			args += ", " + ktString(key.ColumnName)
+		}
+		switch {
+		case key.UDTName == "int4" && key.IsGenerated():
+			fmt.Fprintf(&b, "object %s : %s(%s) {\n", object, w.use("IntIdTable"), args)
+		case key.UDTName == "int8" && key.IsGenerated():
+			fmt.Fprintf(&b, "object %s : %s(%s) {\n", object, w.use("LongIdTable"), args)
+		case key.UDTName == "uuid" && kind == UUIDDefault:
+			fmt.Fprintf(&b, "object %s : %s(%s) {\n", object, w.use("UUIDTable"), args)
+		default:
+			typ := w.idTypes[t.Name]
+			if imp, ok := exposedImports[typ]; ok && imp != "" {
+				w.use(typ)
+			}
+			fmt.Fprintf(&b, "object %s : %s<%s>(%s) {\n", object, w.use("IdTable"), typ, ktString(t.Name))
+			column, _ := w.column(t, *key)
+			members = append(members, fmt.Sprintf("    override val id = %s.entityId()\n", column))
+			members = append(members, fmt.Sprintf("    override val primaryKey = PrimaryKey(id, name = %s)\n", ktString(t.PrimaryKey.ConstraintName)))
+		}
+	} else {
+		fmt.Fprintf(&b, "object %s : %s(%s) {\n", object, w.use("Table"), ktString(t.Name))
+	}
+
+	for _, col := range t.Columns {
+		if properties[col.ColumnName] == "id" {
+			continue
+		}
+		if col.Comment != "" {
+			members = append(members, fmt.Sprintf("    /** %s */\n", oneLine(col.Comment)))
+		}
+		if fk := t.ForeignKeyFor(col.ColumnName); fk != nil && w.order.IsDeferred(*fk) {
+			members = append(members, fmt.Sprintf("    // References %s.%s, which can't be declared: the tables reference each other.\n", fk.TargetTable, fk.TargetColumns[0]))
+		}
+		column, ok := w.column(t, col)
+		if !ok {
+			members = append(members, fmt.Sprintf("    // %s %s has no Exposed column type and is left out.\n", col.ColumnName, col.FullType))
+			continue
+		}
+		members = append(members, fmt.Sprintf("    val %s = %s\n", properties[col.ColumnName], column))
+	}
+
+	if _, ok := w.entities[t.Name]; !ok && t.PrimaryKey != nil {
+		var columns []string
+		for _, c := range t.PrimaryKey.Columns {
+			columns = append(columns, properties[c])
+		}
+		members = append(members, fmt.Sprintf("\n    override val primaryKey = PrimaryKey(%s, name = %s)\n", strings.Join(columns, ", "), ktString(t.PrimaryKey.ConstraintName)))
+	}
+
+	// Constraints over several columns, or on columns declared later, are
+	// added once every column exists.
+	var init []string
+	columnList := func(columns []string) (string, bool) {
+		var names []string
+		for _, c := range columns {
+			name, ok := properties[c]
+			if !ok {
+				return "", false
+			}
+			names = append(names, name)
+		}
+		return strings.Join(names, ", "), true
+	}
+	for _, fk := range t.ForeignKeys {
+		target := w.schema.Table(fk.TargetTable)
+		if target == nil || w.order.IsDeferred(fk) || w.reference(t, fk.SourceColumns[0]) != nil && len(fk.SourceColumns) == 1 {
+			continue
+		}
+		var pairs []string
+		for i, c := range fk.SourceColumns {
+			other := w.objects[fk.TargetTable]
+			if fk.TargetTable == t.Name {
+				other = "this"
+			}
+			pairs = append(pairs, fmt.Sprintf("%s to %s.%s", properties[c], other, w.properties[fk.TargetTable][fk.TargetColumns[i]]))
+		}
+		args := strings.Join(pairs, ", ")
+		if action, ok := exposedActions[fk.OnDelete]; ok {
+			w.use("ReferenceOption")
+			args += ", onDelete = " + action
+		}
+		if action, ok := exposedActions[fk.OnUpdate]; ok {
+			w.use("ReferenceOption")
+			args += ", onUpdate = " + action
+		}
+		init = append(init, fmt.Sprintf("        foreignKey(%s, name = %s)\n", args, ktString(fk.ConstraintName)))
+	}
+	for _, u := range t.Uniques {
+		if list, ok := columnList(u.Columns); ok {
+			init = append(init, fmt.Sprintf("        uniqueIndex(%s, %s)\n", ktString(u.ConstraintName), list))
+		}
+	}
+	for _, idx := range t.Indexes {
+		list, ok := columnList(idx.Columns)
+		if !ok || idx.Where != "" || len(idx.Columns) == 0 {
+			init = append(init, fmt.Sprintf("        // Not mapped: %s\n", oneLine(idx.Definition)))
+			continue
+		}
+		args := fmt.Sprintf("%s, %t, %s", ktString(idx.Name), idx.Unique, list)
+		if idx.Method != "" && idx.Method != "btree" {
+			args += ", indexType = " + ktString(strings.ToUpper(idx.Method))
+		}
+		init = append(init, fmt.Sprintf("        index(%s)\n", args))
+	}
+	for _, c := range t.Checks {
+		init = append(init, fmt.Sprintf("        // CHECK %s: %s\n", c.ConstraintName, oneLine(c.Expression)))
+	}
+	if len(init) > 0 {
+		members = append(members, "\n    init {\n"+strings.Join(init, "")+"    }\n")
+	}
+	b.WriteString(strings.Join(members, ""))
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// column returns the column declaration of a table column.
+func (w *exposedWriter) column(t *Table, col Column) (string, bool) {
+	name := ktString(col.ColumnName)
+	udt := col.ElementType()
+	c, known := exposedColumns[udt]
+	enum, isEnum := w.enums[udt]
+
+	var expr string
+	size, scale, sized := columnModifiers(col)
+	if ref := w.reference(t, col.ColumnName); ref != nil {
+		function := "reference"
+		if col.Nullable() {
+			// optReference makes the column nullable itself.
+			function = "optReference"
+		}
+		target := w.objects[ref.TargetTable]
+		if ref.TargetTable == t.Name {
+			target = "this"
+		}
+		args := []string{name, target}
+		if action, ok := exposedActions[ref.OnDelete]; ok {
+			w.use("ReferenceOption")
+			args = append(args, "onDelete = "+action)
+		}
+		if action, ok := exposedActions[ref.OnUpdate]; ok {
+			w.use("ReferenceOption")
+			args = append(args, "onUpdate = "+action)
+		}
+		args = append(args, "fkName = "+ktString(ref.ConstraintName))
+		return fmt.Sprintf("%s(%s)", function, strings.Join(args, ", ")), true
+	}
+	switch {
+	case col.IsArray():
+		// Exposed resolves the element column type of these itself.
+		if !known || !slices.Contains([]string{"Short", "Int", "Long", "Float", "Double", "Boolean", "String", "UUID"}, c.typ) {
+			return "", false
+		}
+		if c.typ == "UUID" {
+			w.use("UUID")
+		}
+		expr = fmt.Sprintf("array<%s>(%s)", c.typ, name)
+	case isEnum:
+		expr = fmt.Sprintf("customEnumeration(%s, %s, { %s.fromValue(it.toString()) }, { PGEnum(%s, it.value) })",
+			name, ktString(col.FullType), enum, ktString(udt))
+	case !known:
+		return "", false
+	case udt == "numeric" && sized:
+		expr = fmt.Sprintf("decimal(%s, %d, %d)", name, size, scale)
+	case udt == "numeric":
+		// Exposed needs a scale; values are rounded to it.
+		expr = fmt.Sprintf("decimal(%s, 1000, 20)", name)
+	case udt == "varchar" && sized:
+		expr = fmt.Sprintf("varchar(%s, %d)", name, size)
+	case udt == "varchar":
+		expr = fmt.Sprintf("text(%s)", name)
+	case udt == "bpchar":
+		if !sized {
+			size = 1
+		}
+		expr = fmt.Sprintf("char(%s, %d)", name, size)
+	case udt == "json" || udt == "jsonb":
+		expr = fmt.Sprintf("%s<String>(%s, { it }, { it })", w.use(c.function), name)
+	case udt == "date" || udt == "timestamp" || udt == "timestamptz" || udt == "time":
+		expr = fmt.Sprintf("%s(%s)", w.use(c.function), name)
+	default:
+		expr = fmt.Sprintf("%s(%s)", c.function, name)
+	}
+	if col.Nullable() {
+		expr += ".nullable()"
+	}
+	if !t.IsPrimaryKey(col.ColumnName) {
+		expr += w.defaultValue(col, c.typ, enum)
+	}
+	return expr, true
+}
+
+// defaultValue returns the default of a column as a call on its column
+// declaration. Defaults Exposed can't evaluate are left to the database.
+func (w *exposedWriter) defaultValue(col Column, typ, enum string) string {
+	kind, value := parseDefault(col.Default)
+	if col.IsGenerated() {
+		return ".databaseGenerated()"
+	}
+	switch {
+	case kind == NoDefault:
+		return ""
+	case col.IsArray():
+	case kind == NowDefault && col.UDTName == "timestamptz":
+		return fmt.Sprintf(".defaultExpression(%s)", w.use("CurrentTimestampWithTimeZone"))
+	case kind == NowDefault && col.UDTName == "timestamp":
+		return fmt.Sprintf(".defaultExpression(%s)", w.use("CurrentDateTime"))
+	case kind == NowDefault && col.UDTName == "date":
+		return fmt.Sprintf(".defaultExpression(%s)", w.use("CurrentDate"))
+	case kind == StringDefault && enum != "":
+		if constant, ok := w.constants[col.UDTName][value]; ok {
+			return fmt.Sprintf(".default(%s.%s)", enum, constant)
+		}
+	case kind == StringDefault && typ == "String":
+		return fmt.Sprintf(".default(%s)", ktString(value))
+	case kind == BoolDefault && typ == "Boolean":
+		return fmt.Sprintf(".default(%s)", value)
+	case kind == NumberDefault:
+		switch typ {
+		case "Int":
+			return fmt.Sprintf(".default(%s)", value)
+		case "Long":
+			return fmt.Sprintf(".default(%sL)", value)
+		case "Short":
+			return fmt.Sprintf(".default(%s.toShort())", value)
+		case "Float":
+			return fmt.Sprintf(".default(%sf)", value)
+		case "Double":
+			if !strings.ContainsAny(value, ".eE") {
+				value += ".0"
+			}
+			return fmt.Sprintf(".default(%s)", value)
+		case "BigDecimal":
+			w.use("BigDecimal")
+			return fmt.Sprintf(".default(BigDecimal(%s))", ktString(value))
+		}
+	}
+	return ".databaseGenerated()"
+}
+
+func (w *exposedWriter) entity(t *Table) string {
+	class := w.entities[t.Name]
+	object := w.objects[t.Name]
+	typ := w.idTypes[t.Name]
+	var base, companion string
+	switch typ {
+	case "Int":
+		base, companion = w.use("IntEntity")+"(id)", w.use("IntEntityClass")+"<"+class+">"
+	case "Long":
+		base, companion = w.use("LongEntity")+"(id)", w.use("LongEntityClass")+"<"+class+">"
+	case "UUID":
+		w.use("UUID")
+		base, companion = w.use("UUIDEntity")+"(id)", w.use("UUIDEntityClass")+"<"+class+">"
+	default:
+		if _, ok := exposedImports[typ]; ok {
+			w.use(typ)
+		}
+		base = w.use("Entity") + "<" + typ + ">(id)"
+		companion = w.use("EntityClass") + "<" + typ + ", " + class + ">"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "\nclass %s(id: %s<%s>) : %s {\n", class, w.use("EntityID"), typ, base)
+	fmt.Fprintf(&b, "    companion object : %s(%s)\n\n", companion, object)
+
+	properties := w.properties[t.Name]
+	taken := make(map[string]bool)
+	for _, name := range properties {
+		taken[name] = true
+	}
+	for _, col := range t.Columns {
+		name := properties[col.ColumnName]
+		if name == "id" || w.reference(t, col.ColumnName) != nil {
+			continue
+		}
+		if _, ok := w.column(t, col); !ok {
+			continue
+		}
+		fmt.Fprintf(&b, "    var %s by %s.%s\n", name, object, name)
+	}
+	for _, rel := range w.schema.Relations(t.Name) {
+		fk := rel.ForeignKey
+		source := t
+		if !rel.Owner {
+			source = w.schema.Table(rel.Other)
+		}
+		// Only references to entity ids have DAO relations.
+		if len(fk.SourceColumns) != 1 || w.reference(source, fk.SourceColumns[0]) == nil {
+			continue
+		}
+		other, ok := w.entities[rel.Other]
+		if !ok {
+			continue
+		}
+		name := ktIdent(rel.Name)
+		for taken[name] || exposedMembers[name] {
+			name += "Ref"
+		}
+		taken[name] = true
+		column := w.objects[source.Name] + "." + w.properties[source.Name][fk.SourceColumns[0]]
+		nullable := anyNullable(source, fk.SourceColumns)
+		var relation string
+		switch {
+		case rel.Owner && nullable:
+			relation = "var %s by %s optionalReferencedOn %s"
+		case rel.Owner:
+			relation = "var %s by %s referencedOn %s"
+		case !rel.Many && nullable:
+			relation = "val %s by %s optionalBackReferencedOn %s"
+		case !rel.Many:
+			relation = "val %s by %s backReferencedOn %s"
+		case nullable:
+			relation = "val %s by %s optionalReferrersOn %s"
+		default:
+			relation = "val %s by %s referrersOn %s"
+		}
+		fmt.Fprintf(&b, "    "+relation+"\n", name, other, column)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
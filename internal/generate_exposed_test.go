@@ -0,0 +1,7 @@
+package internal
+
+import "testing"
+
+func TestGenerateExposed(t *testing.T) {
+	generateGolden(t, generateExposed, "exposed", "Models.kt")
+}
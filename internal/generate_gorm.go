@@ -0,0 +1,369 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// goInitialisms are the words Go spells in upper case, so "user_id" becomes
+// UserID rather than UserId.
+var goInitialisms = map[string]bool{
+	"acl": true, "api": true, "ascii": true, "cpu": true, "css": true, "dns": true,
+	"html": true, "http": true, "https": true, "id": true, "ip": true, "json": true,
+	"sku": true, "sql": true, "ssh": true, "tcp": true, "tls": true, "ttl": true,
+	"ui": true, "uid": true, "uri": true, "url": true, "utc": true, "uuid": true,
+	"xml": true,
+}
+
+// goName turns a database name into an exported Go identifier.
+func goName(name string) string {
+	var b strings.Builder
+	for _, word := range splitWords(name) {
+		if goInitialisms[word] {
+			b.WriteString(strings.ToUpper(word))
+			continue
+		}
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	ident := b.String()
+	if ident == "" || ident[0] >= '0' && ident[0] <= '9' {
+		ident = "X" + ident
+	}
+	return ident
+}
+
+// goType maps a column to its Go type and the import it needs. Nullable
+// columns use the database/sql Null types where one exists and pointers
+// otherwise.
+func goType(col Column, schema *Schema) (string, string) {
+	if col.IsArray() {
+		switch col.ElementType() {
+		case "int2", "int4":
+			return "pq.Int32Array", "github.com/lib/pq"
+		case "int8":
+			return "pq.Int64Array", "github.com/lib/pq"
+		case "float4":
+			return "pq.Float32Array", "github.com/lib/pq"
+		case "float8", "numeric":
+			return "pq.Float64Array", "github.com/lib/pq"
+		case "bool":
+			return "pq.BoolArray", "github.com/lib/pq"
+		case "bytea":
+			return "pq.ByteaArray", "github.com/lib/pq"
+		}
+		return "pq.StringArray", "github.com/lib/pq"
+	}
+
+	nullable := col.Nullable()
+	pick := func(typ, nullType, pkg string) (string, string) {
+		if !nullable {
+			return typ, pkg
+		}
+		if nullType == "" {
+			return "*" + typ, pkg
+		}
+		return nullType, "database/sql"
+	}
+	switch col.UDTName {
+	case "int2":
+		return pick("int16", "sql.NullInt16", "")
+	case "int4":
+		return pick("int32", "sql.NullInt32", "")
+	case "int8":
+		return pick("int64", "sql.NullInt64", "")
+	case "float4":
+		return pick("float32", "", "")
+	case "float8", "numeric":
+		return pick("float64", "sql.NullFloat64", "")
+	case "bool":
+		return pick("bool", "sql.NullBool", "")
+	case "uuid":
+		return pick("uuid.UUID", "", "github.com/google/uuid")
+	case "json", "jsonb":
+		return "datatypes.JSON", "gorm.io/datatypes"
+	case "timestamp", "timestamptz", "date":
+		if nullable {
+			return "sql.NullTime", "database/sql"
+		}
+		return "time.Time", "time"
+	case "bytea":
+		return "[]byte", ""
+	}
+	if schema.Enum(col.UDTName) != nil {
+		return pick(goName(col.UDTName), "", "")
+	}
+	return pick("string", "sql.NullString", "")
+}
+
+type gormFile struct {
+	Package    string
+	StdImports []string
+	Imports    []string
+	Enums      []gormEnum
+	Models     []gormModel
+}
+
+type gormEnum struct {
+	Name   string
+	DBName string
+	Values []gormConst
+}
+
+type gormConst struct {
+	Name  string
+	Value string
+}
+
+type gormModel struct {
+	Name    string
+	Table   string
+	Comment string
+	Fields  []gormField
+}
+
+type gormField struct {
+	Name    string
+	Type    string
+	Tag     string
+	Comment string
+}
+
+var gormTemplate = template.Must(template.New("gorm").Parse(`// Code generated by schema transform. DO NOT EDIT.
+
+package {{.Package}}
+{{if or .StdImports .Imports}}
+import (
+{{- range .StdImports}}
+	"{{.}}"
+{{- end}}
+{{if and .StdImports .Imports}}
+{{end}}
+{{- range .Imports}}
+	"{{.}}"
+{{- end}}
+)
+{{end}}
+{{- range .Enums}}
+// {{.Name}} is the {{.DBName}} enum type.
+type {{.Name}} string
+
+const (
+{{- $enum := .Name}}
+{{- range .Values}}
+	{{.Name}} {{$enum}} = {{.Value}}
+{{- end}}
+)
+{{end}}
+{{- range .Models}}
+// {{.Name}} is a row of the {{.Table}} table.
+{{- if .Comment}}
+//
+// {{.Comment}}
+{{- end}}
+type {{.Name}} struct {
+{{- range .Fields}}
+{{- if .Comment}}
+	// {{.Comment}}
+{{- end}}
+	{{.Name}} {{.Type}} {{.Tag}}
+{{- end}}
+}
+
+// TableName returns the name of the table {{.Name}} is stored in.
+func ({{.Name}}) TableName() string {
+	return {{printf "%q" .Table}}
+}
+{{end}}`))
+
+// generateGORM renders the schema as Go structs with GORM tags, one struct
+// per table plus a string type per enum, formatted with gofmt.
+func generateGORM(schema *Schema, opts GenerateOptions) ([]File, error) {
+	data := gormFile{Package: opts.Package}
+	if data.Package == "" {
+		data.Package = "models"
+	}
+	imports := make(map[string]bool)
+
+	for _, e := range schema.Enums {
+		enum := gormEnum{Name: goName(e.Name), DBName: e.Name}
+		for _, v := range e.Values {
+			enum.Values = append(enum.Values, gormConst{Name: enum.Name + goName(v), Value: strconv.Quote(v)})
+		}
+		data.Enums = append(data.Enums, enum)
+	}
+
+	for i := range schema.Tables {
+		t := &schema.Tables[i]
+		model := gormModel{Name: goName(singular(t.Name)), Table: t.Name, Comment: oneLine(t.Comment)}
+		taken := map[string]bool{"TableName": true}
+		fieldName := func(name string) string {
+			name = goName(name)
+			for taken[name] {
+				name += "_"
+			}
+			taken[name] = true
+			return name
+		}
+		indexTags := gormIndexTags(t)
+
+		for _, col := range t.Columns {
+			typ, pkg := goType(col, schema)
+			if pkg != "" {
+				imports[pkg] = true
+			}
+			dbType := col.FullType
+			if serial, ok := serialTypes[dbType]; ok && col.IsSerial() {
+				dbType = serial
+			}
+			tags := []string{"column:" + col.ColumnName, "type:" + dbType}
+			if t.IsPrimaryKey(col.ColumnName) {
+				tags = append(tags, "primaryKey")
+			}
+			if col.IsSerial() || col.IsIdentity {
+				tags = append(tags, "autoIncrement")
+			} else if t.IsPrimaryKey(col.ColumnName) && strings.HasPrefix(col.UDTName, "int") {
+				tags = append(tags, "autoIncrement:false")
+			}
+			if !col.Nullable() {
+				tags = append(tags, "not null")
+			}
+			if col.Default != "" && !col.IsSerial() {
+				tags = append(tags, "default:"+col.Default)
+			}
+			tags = append(tags, indexTags[col.ColumnName]...)
+			if col.Comment != "" {
+				tags = append(tags, "comment:"+oneLine(col.Comment))
+			}
+
+			model.Fields = append(model.Fields, gormField{
+				Name:    fieldName(col.ColumnName),
+				Type:    typ,
+				Tag:     structTag(gormTagValue(tags), col.ColumnName),
+				Comment: oneLine(col.Comment),
+			})
+		}
+
+		for _, rel := range schema.Relations(t.Name) {
+			other := schema.Table(rel.Other)
+			fk := rel.ForeignKey
+			tags := []string{
+				"foreignKey:" + goNames(fk.SourceColumns),
+				"references:" + goNames(fk.TargetColumns),
+			}
+			typ := "*" + goName(singular(other.Name))
+			if rel.Many {
+				typ = "[]" + goName(singular(other.Name))
+			}
+			if rel.Owner {
+				var actions []string
+				if fk.OnUpdate != "" && fk.OnUpdate != "NO ACTION" {
+					actions = append(actions, "OnUpdate:"+fk.OnUpdate)
+				}
+				if fk.OnDelete != "" && fk.OnDelete != "NO ACTION" {
+					actions = append(actions, "OnDelete:"+fk.OnDelete)
+				}
+				if len(actions) > 0 {
+					tags = append(tags, "constraint:"+strings.Join(actions, ","))
+				}
+			}
+			model.Fields = append(model.Fields, gormField{
+				Name: fieldName(rel.Name),
+				Type: typ,
+				Tag:  structTag(gormTagValue(tags), rel.Name+",omitempty"),
+			})
+		}
+		data.Models = append(data.Models, model)
+	}
+
+	for pkg := range imports {
+		if strings.Contains(pkg, ".") {
+			data.Imports = append(data.Imports, pkg)
+		} else {
+			data.StdImports = append(data.StdImports, pkg)
+		}
+	}
+	slices.Sort(data.StdImports)
+	slices.Sort(data.Imports)
+
+	var b bytes.Buffer
+	if err := gormTemplate.Execute(&b, data); err != nil {
+		return nil, fmt.Errorf("failed to render models: %w", err)
+	}
+	source, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format models: %w", err)
+	}
+	return []File{{Name: "models.go", Content: source}}, nil
+}
+
+// gormIndexTags returns the index and uniqueIndex tags of every column.
+// Columns of composite indexes get a priority so GORM keeps their order;
+// expression indexes can't be expressed as tags and are left out.
+func gormIndexTags(t *Table) map[string][]string {
+	tags := make(map[string][]string)
+	add := func(kind, name string, columns []string, where string) {
+		for _, c := range columns {
+			if t.Column(c) == nil {
+				return
+			}
+		}
+		for i, c := range columns {
+			tag := kind + ":" + name
+			if len(columns) > 1 {
+				tag += ",priority:" + strconv.Itoa(i+1)
+			}
+			if where != "" {
+				tag += ",where:" + where
+			}
+			tags[c] = append(tags[c], tag)
+		}
+	}
+	for _, u := range t.Uniques {
+		add("uniqueIndex", u.ConstraintName, u.Columns, "")
+	}
+	for _, idx := range t.Indexes {
+		kind := "index"
+		if idx.Unique {
+			kind = "uniqueIndex"
+		}
+		add(kind, idx.Name, idx.Columns, idx.Where)
+	}
+	return tags
+}
+
+// gormTagValue joins GORM tag settings, escaping the separator in values.
+func gormTagValue(settings []string) string {
+	escaped := make([]string, len(settings))
+	for i, s := range settings {
+		escaped[i] = strings.ReplaceAll(s, ";", `\;`)
+	}
+	return strings.Join(escaped, ";")
+}
+
+// structTag renders the gorm and json tags as a raw string literal, or as
+// an interpreted one when a value contains a backquote.
+func structTag(gorm, json string) string {
+	tag := fmt.Sprintf(`gorm:%s json:%s`, strconv.Quote(gorm), strconv.Quote(json))
+	if strings.Contains(tag, "`") {
+		return strconv.Quote(tag)
+	}
+	return "`" + tag + "`"
+}
+
+func goNames(columns []string) string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = goName(c)
+	}
+	return strings.Join(names, ",")
+}
+
+// oneLine collapses a comment onto a single line.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,52 @@
+package internal
+
+import (
+	"go/format"
+	"testing"
+)
+
+func TestGenerateGORM(t *testing.T) {
+	files, err := generateGORM(blogSchema(), GenerateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Name != "models.go" {
+		t.Fatalf("got %d files, want models.go", len(files))
+	}
+	golden(t, "gorm/models.go", files[0].Content)
+	// The generator formats its output, anything else means it is not
+	// valid Go.
+	formatted, err := format.Source(files[0].Content)
+	if err != nil {
+		t.Fatalf("generated code doesn't parse: %v", err)
+	}
+	if string(formatted) != string(files[0].Content) {
+		t.Error("generated code is not gofmt formatted")
+	}
+}
+
+func TestGenerateGORMPackage(t *testing.T) {
+	files, err := generateGORM(blogSchema(), GenerateOptions{Package: "blog"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "package blog\n"; len(files) != 1 || !containsLine(string(files[0].Content), want) {
+		t.Errorf("models.go doesn't declare %q:\n%s", want, files[0].Content)
+	}
+}
+
+func TestGoName(t *testing.T) {
+	tests := map[string]string{
+		"user_id":       "UserID",
+		"api_key":       "APIKey",
+		"createdAt":     "CreatedAt",
+		"html_body_url": "HTMLBodyURL",
+		"2fa_enabled":   "X2faEnabled",
+		"post_tags":     "PostTags",
+	}
+	for name, want := range tests {
+		if got := goName(name); got != want {
+			t.Errorf("goName(%s) = %s, want %s", name, got, want)
+		}
+	}
+}
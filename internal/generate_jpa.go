@@ -0,0 +1,728 @@
+package internal
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// javaTypes maps udt names to the Java types the Postgres JDBC driver
+// reads them as, fully qualified where they need an import.
+var javaTypes = map[string]string{
+	"int2": "Short", "int4": "Integer", "int8": "Long", "float4": "Float", "float8": "Double",
+	"numeric": "java.math.BigDecimal", "bool": "Boolean", "varchar": "String", "bpchar": "String",
+	"text": "String", "citext": "String", "uuid": "java.util.UUID", "json": "String", "jsonb": "String",
+	"date": "java.time.LocalDate", "timestamp": "java.time.LocalDateTime",
+	"timestamptz": "java.time.OffsetDateTime", "time": "java.time.LocalTime",
+	"timetz": "java.time.OffsetTime", "interval": "java.time.Duration", "bytea": "byte[]",
+}
+
+var javaKeywords = map[string]bool{
+	"abstract": true, "assert": true, "boolean": true, "break": true, "byte": true, "case": true,
+	"catch": true, "char": true, "class": true, "const": true, "continue": true, "default": true,
+	"do": true, "double": true, "else": true, "enum": true, "extends": true, "false": true,
+	"final": true, "finally": true, "float": true, "for": true, "goto": true, "if": true,
+	"implements": true, "import": true, "instanceof": true, "int": true, "interface": true,
+	"long": true, "native": true, "new": true, "null": true, "package": true, "private": true,
+	"protected": true, "public": true, "record": true, "return": true, "short": true,
+	"static": true, "strictfp": true, "super": true, "switch": true, "synchronized": true,
+	"this": true, "throw": true, "throws": true, "transient": true, "true": true, "try": true,
+	"var": true, "void": true, "volatile": true, "while": true, "yield": true, "_": true,
+}
+
+// javaReservedClasses are the java.lang types and the simple names the
+// generated files import; entity classes named like them get an "Entity"
+// suffix so neither shadows the other.
+var javaReservedClasses = map[string]bool{
+	"Object": true, "String": true, "Class": true, "Enum": true, "Record": true, "Boolean": true,
+	"Byte": true, "Short": true, "Integer": true, "Long": true, "Float": true, "Double": true,
+	"Number": true, "Character": true, "Void": true, "Math": true, "System": true, "Thread": true,
+	"Override": true, "Exception": true, "Error": true, "Iterable": true, "Comparable": true,
+	"BigDecimal": true, "UUID": true, "LocalDate": true, "LocalDateTime": true, "OffsetDateTime": true,
+	"LocalTime": true, "OffsetTime": true, "Duration": true, "List": true, "ArrayList": true,
+	"Objects": true, "Serializable": true, "Entity": true, "Table": true, "Column": true, "Id": true,
+	"EmbeddedId": true, "Embeddable": true, "GeneratedValue": true, "GenerationType": true,
+	"Enumerated": true, "EnumType": true, "Convert": true, "Converter": true,
+	"AttributeConverter": true, "ManyToOne": true, "OneToMany": true, "OneToOne": true,
+	"JoinColumn": true, "JoinColumns": true, "ForeignKey": true, "FetchType": true, "Index": true,
+	"UniqueConstraint": true, "Check": true, "Comment": true, "ColumnTransformer": true,
+	"DynamicInsert": true, "Generated": true, "Immutable": true, "JdbcType": true,
+	"JdbcTypeCode": true, "OnDelete": true, "OnDeleteAction": true, "SqlTypes": true,
+	"PostgreSQLEnumJdbcType": true, "JpaRepository": true, "ListCrudRepository": true,
+	"AggregateReference": true, "ReadOnlyProperty": true,
+}
+
+// javaIdent turns a database name into a camelCase Java field name.
+func javaIdent(name string) string {
+	ident := camelCase(name)
+	if ident == "" || ident[0] >= '0' && ident[0] <= '9' {
+		ident = "_" + ident
+	}
+	if javaKeywords[ident] {
+		ident += "_"
+	}
+	return ident
+}
+
+// javaString returns s as a Java string literal. Control characters are
+// escaped in octal since unicode escapes are translated before lexing.
+func javaString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, `\%03o`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// javaStrings renders a string array annotation value.
+func javaStrings(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = javaString(v)
+	}
+	if len(quoted) == 1 {
+		return quoted[0]
+	}
+	return "{" + strings.Join(quoted, ", ") + "}"
+}
+
+// javaPlainEnum reports whether every value of an enum is a Java
+// identifier, so the values can name the enum constants directly.
+func javaPlainEnum(e Enum) bool {
+	for _, v := range e.Values {
+		if !tsIdentifier.MatchString(v) || javaKeywords[v] {
+			return false
+		}
+	}
+	return true
+}
+
+// javaFile collects the imports of a Java source file while its body is
+// written.
+type javaFile struct {
+	pkg     string
+	imports map[string]bool
+}
+
+func newJavaFile(pkg string) *javaFile {
+	return &javaFile{pkg: pkg, imports: make(map[string]bool)}
+}
+
+// use returns the simple name of a fully qualified type and imports it.
+func (f *javaFile) use(typ string) string {
+	i := strings.LastIndex(typ, ".")
+	if i < 0 {
+		return typ
+	}
+	if typ[:i] != f.pkg {
+		f.imports[typ] = true
+	}
+	return typ[i+1:]
+}
+
+// render returns the file with its header, package and imports.
+func (f *javaFile) render(body string) []byte {
+	var b strings.Builder
+	b.WriteString("// Generated by schema transform. Do not edit.\n")
+	fmt.Fprintf(&b, "package %s;\n", f.pkg)
+	var imports []string
+	for imp := range f.imports {
+		imports = append(imports, imp)
+	}
+	slices.Sort(imports)
+	if len(imports) > 0 {
+		b.WriteString("\n")
+	}
+	for _, imp := range imports {
+		fmt.Fprintf(&b, "import %s;\n", imp)
+	}
+	b.WriteString("\n")
+	b.WriteString(body)
+	return []byte(b.String())
+}
+
+// javaField is a private field of a generated class, exposed through a
+// getter and a setter.
+type javaField struct {
+	annotations []string
+	typ         string
+	name        string
+	init        string
+}
+
+// writeJavaMembers writes the fields of a class followed by their
+// accessors.
+func writeJavaMembers(b *strings.Builder, fields []javaField) {
+	for _, field := range fields {
+		b.WriteString("\n")
+		for _, a := range field.annotations {
+			fmt.Fprintf(b, "    %s\n", a)
+		}
+		if field.init != "" {
+			fmt.Fprintf(b, "    private %s %s = %s;\n", field.typ, field.name, field.init)
+		} else {
+			fmt.Fprintf(b, "    private %s %s;\n", field.typ, field.name)
+		}
+	}
+	for _, field := range fields {
+		accessor := strings.ToUpper(field.name[:1]) + field.name[1:]
+		fmt.Fprintf(b, "\n    public %s get%s() {\n        return %s;\n    }\n", field.typ, accessor, field.name)
+		fmt.Fprintf(b, "\n    public void set%s(%s %s) {\n        this.%s = %s;\n    }\n", accessor, field.typ, field.name, field.name, field.name)
+	}
+}
+
+// javaWriter holds the names shared by the Java generators.
+type javaWriter struct {
+	schema *Schema
+	pkg    string
+	// classes maps tables to their entity classes, ids to the embeddable
+	// id classes of tables with a composite key, enums the enum types to
+	// their Java enums.
+	classes map[string]string
+	ids     map[string]string
+	enums   map[string]string
+	// fields maps each table's columns to their field names, idFields the
+	// tables with an id class to the field holding it. taken holds every
+	// field name of a table.
+	fields   map[string]map[string]string
+	idFields map[string]string
+	taken    map[string]map[string]bool
+}
+
+func newJavaWriter(schema *Schema, opts GenerateOptions) *javaWriter {
+	w := &javaWriter{
+		schema:   schema,
+		pkg:      opts.Package,
+		classes:  make(map[string]string),
+		ids:      make(map[string]string),
+		enums:    make(map[string]string),
+		fields:   make(map[string]map[string]string),
+		idFields: make(map[string]string),
+		taken:    make(map[string]map[string]bool),
+	}
+	if w.pkg == "" {
+		w.pkg = "models"
+	}
+	taken := make(map[string]bool)
+	for name := range javaReservedClasses {
+		taken[name] = true
+	}
+	for _, t := range schema.Tables {
+		name := className(t.Name)
+		for taken[name] {
+			name += "Entity"
+		}
+		taken[name] = true
+		w.classes[t.Name] = name
+	}
+	for _, e := range schema.Enums {
+		name := pascalCase(e.Name)
+		if name == "" || name[0] >= '0' && name[0] <= '9' {
+			name = "E" + name
+		}
+		for taken[name] {
+			name += "Enum"
+		}
+		taken[name] = true
+		w.enums[e.Name] = name
+	}
+	for _, t := range schema.Tables {
+		if t.PrimaryKey == nil || len(t.PrimaryKey.Columns) > 1 {
+			name := w.classes[t.Name] + "Id"
+			for taken[name] {
+				name += "Key"
+			}
+			taken[name] = true
+			w.ids[t.Name] = name
+		}
+		fields := make(map[string]string)
+		used := make(map[string]bool)
+		for _, col := range t.Columns {
+			name := javaIdent(col.ColumnName)
+			for used[name] {
+				name += "_"
+			}
+			used[name] = true
+			fields[col.ColumnName] = name
+		}
+		w.fields[t.Name] = fields
+		w.taken[t.Name] = used
+		if w.ids[t.Name] != "" {
+			w.idFields[t.Name] = w.name(t.Name, "id")
+		}
+	}
+	return w
+}
+
+// name reserves a field name on a table.
+func (w *javaWriter) name(table, base string) string {
+	name := javaIdent(base)
+	for w.taken[table][name] {
+		name += "_"
+	}
+	w.taken[table][name] = true
+	return name
+}
+
+// enum renders a Java enum. Enums whose values can't name constants get
+// upper case constants holding the values instead, and with converter set
+// a JPA AttributeConverter translating between the two.
+func (w *javaWriter) enum(e Enum, converter bool) File {
+	name := w.enums[e.Name]
+	f := newJavaFile(w.pkg)
+	var b strings.Builder
+	fmt.Fprintf(&b, "public enum %s {\n", name)
+	if javaPlainEnum(e) {
+		for i, v := range e.Values {
+			sep := ","
+			if i == len(e.Values)-1 {
+				sep = ""
+			}
+			fmt.Fprintf(&b, "    %s%s\n", v, sep)
+		}
+		b.WriteString("}\n")
+		return File{Name: name + ".java", Content: f.render(b.String())}
+	}
+
+	used := make(map[string]bool)
+	for i, v := range e.Values {
+		constant := pythonConstant(v)
+		for used[constant] {
+			constant += "_"
+		}
+		used[constant] = true
+		sep := ","
+		if i == len(e.Values)-1 {
+			sep = ";"
+		}
+		fmt.Fprintf(&b, "    %s(%s)%s\n", constant, javaString(v), sep)
+	}
+	fmt.Fprintf(&b, "\n    private final String value;\n\n    %s(String value) {\n        this.value = value;\n    }\n", name)
+	b.WriteString("\n    public String getValue() {\n        return value;\n    }\n")
+	fmt.Fprintf(&b, "\n    public static %s fromValue(String value) {\n", name)
+	fmt.Fprintf(&b, "        for (%s constant : values()) {\n", name)
+	b.WriteString("            if (constant.value.equals(value)) {\n                return constant;\n            }\n        }\n")
+	fmt.Fprintf(&b, "        throw new IllegalArgumentException(%s + value);\n    }\n", javaString("Unknown "+e.Name+" value: "))
+	if converter {
+		fmt.Fprintf(&b, "\n    @%s\n", f.use("jakarta.persistence.Converter"))
+		fmt.Fprintf(&b, "    public static class ValueConverter implements %s<%s, String> {\n", f.use("jakarta.persistence.AttributeConverter"), name)
+		fmt.Fprintf(&b, "        @Override\n        public String convertToDatabaseColumn(%s attribute) {\n", name)
+		b.WriteString("            return attribute == null ? null : attribute.value;\n        }\n")
+		fmt.Fprintf(&b, "\n        @Override\n        public %s convertToEntityAttribute(String dbData) {\n", name)
+		b.WriteString("            return dbData == null ? null : fromValue(dbData);\n        }\n    }\n")
+	}
+	b.WriteString("}\n")
+	return File{Name: name + ".java", Content: f.render(b.String())}
+}
+
+// idClass renders the class holding the columns of a composite key, with
+// the equals and hashCode ids need.
+func (w *javaWriter) idClass(f *javaFile, name string, annotations []string, fields []javaField) []byte {
+	var b strings.Builder
+	for _, a := range annotations {
+		b.WriteString(a + "\n")
+	}
+	fmt.Fprintf(&b, "public class %s implements %s {\n", name, f.use("java.io.Serializable"))
+	writeJavaMembers(&b, fields)
+	var equal, names []string
+	for _, field := range fields {
+		equal = append(equal, fmt.Sprintf("%s.equals(%s, other.%s)", f.use("java.util.Objects"), field.name, field.name))
+		names = append(names, field.name)
+	}
+	fmt.Fprintf(&b, "\n    @Override\n    public boolean equals(Object o) {\n        if (this == o) {\n            return true;\n        }\n")
+	fmt.Fprintf(&b, "        if (!(o instanceof %s other)) {\n            return false;\n        }\n", name)
+	fmt.Fprintf(&b, "        return %s;\n    }\n", strings.Join(equal, "\n            && "))
+	fmt.Fprintf(&b, "\n    @Override\n    public int hashCode() {\n        return Objects.hash(%s);\n    }\n", strings.Join(names, ", "))
+	b.WriteString("}\n")
+	return f.render(b.String())
+}
+
+// repository renders a Spring Data repository interface for an entity.
+func (w *javaWriter) repository(class, base, id, comment string) File {
+	f := newJavaFile(w.pkg)
+	var b strings.Builder
+	if comment != "" {
+		fmt.Fprintf(&b, "// %s\n", comment)
+	}
+	fmt.Fprintf(&b, "public interface %sRepository extends %s<%s, %s> {\n}\n", class, f.use(base), class, f.use(id))
+	return File{Name: class + "Repository.java", Content: f.render(b.String())}
+}
+
+var jpaOnDelete = map[string]string{
+	"CASCADE":     "CASCADE",
+	"SET NULL":    "SET_NULL",
+	"SET DEFAULT": "SET_DEFAULT",
+	"RESTRICT":    "RESTRICT",
+}
+
+type jpaWriter struct {
+	*javaWriter
+	// relations maps the relation fields of each table by relationKey.
+	relations map[string]map[string]string
+}
+
+func relationKey(owner bool, other, constraint string) string {
+	return fmt.Sprintf("%t %s %s", owner, other, constraint)
+}
+
+// generateJPA renders the schema as Jakarta Persistence entities mapped
+// the way Hibernate 6 reads Postgres: an entity class per table with
+// @ManyToOne, @OneToOne and @OneToMany relations, an @Embeddable id class
+// per composite key, a Java enum per enum type and a Spring Data
+// JpaRepository per entity. Tables without a primary key are mapped read
+// only, keyed by all of their columns.
+func generateJPA(schema *Schema, opts GenerateOptions) ([]File, error) {
+	w := &jpaWriter{javaWriter: newJavaWriter(schema, opts), relations: make(map[string]map[string]string)}
+	for _, t := range schema.Tables {
+		w.relations[t.Name] = make(map[string]string)
+		for _, rel := range schema.Relations(t.Name) {
+			if w.related(rel) {
+				w.relations[t.Name][relationKey(rel.Owner, rel.Other, rel.ForeignKey.ConstraintName)] = w.name(t.Name, rel.Name)
+			}
+		}
+	}
+	var files []File
+	for _, e := range schema.Enums {
+		files = append(files, w.enum(e, true))
+	}
+	for i := range schema.Tables {
+		t := &schema.Tables[i]
+		files = append(files, w.entity(t)...)
+	}
+	return files, nil
+}
+
+// idType returns the type of an entity's id, qualified where it needs an
+// import.
+func (w *jpaWriter) idType(t *Table) string {
+	if id, ok := w.ids[t.Name]; ok {
+		return w.pkg + "." + id
+	}
+	typ, ok := javaTypes[t.Column(t.PrimaryKey.Columns[0]).ElementType()]
+	if !ok || t.Column(t.PrimaryKey.Columns[0]).IsArray() {
+		return "String"
+	}
+	return typ
+}
+
+// keyColumns returns the columns making up an entity's id.
+func keyColumns(t *Table) []string {
+	if t.PrimaryKey != nil {
+		return t.PrimaryKey.Columns
+	}
+	var columns []string
+	for _, col := range t.Columns {
+		columns = append(columns, col.ColumnName)
+	}
+	return columns
+}
+
+func (w *jpaWriter) entity(t *Table) []File {
+	class := w.classes[t.Name]
+	f := newJavaFile(w.pkg)
+	keys := keyColumns(t)
+	embedded := w.ids[t.Name] != ""
+
+	var files []File
+	var fields []javaField
+	if embedded {
+		idFile := newJavaFile(w.pkg)
+		var idFields []javaField
+		for _, c := range keys {
+			idFields = append(idFields, w.column(idFile, t, *t.Column(c), true))
+		}
+		content := w.idClass(idFile, w.ids[t.Name], []string{"@" + idFile.use("jakarta.persistence.Embeddable")}, idFields)
+		files = append(files, File{Name: w.ids[t.Name] + ".java", Content: content})
+		fields = append(fields, javaField{
+			annotations: []string{"@" + f.use("jakarta.persistence.EmbeddedId")},
+			typ:         w.ids[t.Name],
+			name:        w.idFields[t.Name],
+		})
+	}
+
+	// The foreign key columns are written through the relations owning
+	// them; key columns and columns shared by several foreign keys are
+	// written through their own field and the relation is read only.
+	mapped := make(map[string]bool)
+	for _, c := range keys {
+		mapped[c] = true
+	}
+	readOnly := make(map[string]bool)
+	relations := w.schema.Relations(t.Name)
+	for _, rel := range relations {
+		if !rel.Owner || !w.related(rel) {
+			continue
+		}
+		for _, c := range rel.ForeignKey.SourceColumns {
+			if mapped[c] {
+				readOnly[rel.ForeignKey.ConstraintName] = true
+			}
+		}
+		if !readOnly[rel.ForeignKey.ConstraintName] {
+			for _, c := range rel.ForeignKey.SourceColumns {
+				mapped[c] = true
+			}
+		}
+	}
+	for _, col := range t.Columns {
+		switch {
+		case embedded && slices.Contains(keys, col.ColumnName):
+		case !mapped[col.ColumnName] || t.IsPrimaryKey(col.ColumnName):
+			fields = append(fields, w.column(f, t, col, t.IsPrimaryKey(col.ColumnName)))
+		}
+	}
+	for _, rel := range relations {
+		if w.related(rel) {
+			fields = append(fields, w.relation(f, t, rel, readOnly[rel.ForeignKey.ConstraintName]))
+		}
+	}
+
+	tableArgs := []string{"name = " + javaString(quoteIdent(t.Name))}
+	var uniques, indexes, skipped []string
+	for _, u := range t.Uniques {
+		if len(u.Columns) > 1 {
+			uniques = append(uniques, fmt.Sprintf("@%s(name = %s, columnNames = %s)",
+				f.use("jakarta.persistence.UniqueConstraint"), javaString(u.ConstraintName), javaStrings(quoteColumns(u.Columns))))
+		}
+	}
+	for _, idx := range t.Indexes {
+		plain := len(idx.Columns) > 0
+		for _, c := range idx.Columns {
+			if t.Column(c) == nil {
+				plain = false
+			}
+		}
+		if !plain || idx.Where != "" || idx.Method != "" && idx.Method != "btree" {
+			skipped = append(skipped, oneLine(idx.Definition))
+			continue
+		}
+		index := fmt.Sprintf("@%s(name = %s, columnList = %s", f.use("jakarta.persistence.Index"),
+			javaString(idx.Name), javaString(strings.Join(quoteColumns(idx.Columns), ", ")))
+		if idx.Unique {
+			index += ", unique = true"
+		}
+		indexes = append(indexes, index+")")
+	}
+	if len(uniques) > 0 {
+		tableArgs = append(tableArgs, "uniqueConstraints = "+javaAnnotations(uniques, ""))
+	}
+	if len(indexes) > 0 {
+		tableArgs = append(tableArgs, "indexes = "+javaAnnotations(indexes, ""))
+	}
+	var b strings.Builder
+	for _, definition := range skipped {
+		fmt.Fprintf(&b, "// Not mapped: %s\n", definition)
+	}
+	b.WriteString("@" + f.use("jakarta.persistence.Entity") + "\n")
+	fmt.Fprintf(&b, "@%s(%s)\n", f.use("jakarta.persistence.Table"), strings.Join(tableArgs, ", "))
+	for _, c := range t.Checks {
+		fmt.Fprintf(&b, "@%s(name = %s, constraints = %s)\n", f.use("org.hibernate.annotations.Check"), javaString(c.ConstraintName), javaString(c.Expression))
+	}
+	if t.Comment != "" {
+		fmt.Fprintf(&b, "@%s(%s)\n", f.use("org.hibernate.annotations.Comment"), javaString(t.Comment))
+	}
+	if t.PrimaryKey == nil {
+		b.WriteString("@" + f.use("org.hibernate.annotations.Immutable") + "\n")
+	}
+	// Hibernate writes every column on insert, nulls included; leaving
+	// the null ones out lets the database defaults apply.
+	for _, col := range t.Columns {
+		if kind, _ := parseDefault(col.Default); kind != NoDefault && !col.IsGenerated() && !t.IsPrimaryKey(col.ColumnName) {
+			b.WriteString("@" + f.use("org.hibernate.annotations.DynamicInsert") + "\n")
+			break
+		}
+	}
+	fmt.Fprintf(&b, "public class %s {\n", class)
+	writeJavaMembers(&b, fields)
+	b.WriteString("}\n")
+	files = append(files, File{Name: class + ".java", Content: f.render(b.String())})
+
+	comment := ""
+	if t.PrimaryKey == nil {
+		comment = t.Name + " has no primary key, so its entities are read only."
+	}
+	files = append(files, w.repository(class, "org.springframework.data.jpa.repository.JpaRepository", w.idType(t), comment))
+	return files
+}
+
+// related reports whether a relation's other side is mapped as an entity
+// relations can point at.
+func (w *jpaWriter) related(rel Relation) bool {
+	target := rel.Other
+	if !rel.Owner {
+		target = rel.Table
+	}
+	return w.schema.Table(target).PrimaryKey != nil
+}
+
+// column returns the field of a column. Key columns are part of the
+// entity's id, held by the field itself or by its id class.
+func (w *jpaWriter) column(f *javaFile, t *Table, col Column, key bool) javaField {
+	udt := col.ElementType()
+	field := javaField{name: w.fields[t.Name][col.ColumnName]}
+	var annotations []string
+	if key && w.ids[t.Name] == "" {
+		annotations = append(annotations, "@"+f.use("jakarta.persistence.Id"))
+		kind, _ := parseDefault(col.Default)
+		switch {
+		case col.IsGenerated():
+			annotations = append(annotations, fmt.Sprintf("@%s(strategy = %s.IDENTITY)",
+				f.use("jakarta.persistence.GeneratedValue"), f.use("jakarta.persistence.GenerationType")))
+		case kind == UUIDDefault:
+			annotations = append(annotations, fmt.Sprintf("@%s(strategy = %s.UUID)",
+				f.use("jakarta.persistence.GeneratedValue"), f.use("jakarta.persistence.GenerationType")))
+		}
+	}
+
+	// Values the driver would send as varchar are cast to the column type
+	// on write.
+	cast := false
+	typ, known := javaTypes[udt]
+	enum, isEnum := w.enums[udt]
+	switch {
+	case isEnum && !col.IsArray() && javaPlainEnum(*w.schema.Enum(udt)):
+		typ = enum
+		annotations = append(annotations,
+			fmt.Sprintf("@%s(%s.STRING)", f.use("jakarta.persistence.Enumerated"), f.use("jakarta.persistence.EnumType")),
+			fmt.Sprintf("@%s(%s.class)", f.use("org.hibernate.annotations.JdbcType"), f.use("org.hibernate.dialect.PostgreSQLEnumJdbcType")))
+	case isEnum && !col.IsArray():
+		typ = enum
+		annotations = append(annotations, fmt.Sprintf("@%s(converter = %s.ValueConverter.class)", f.use("jakarta.persistence.Convert"), enum))
+		cast = true
+	case isEnum, !known:
+		typ, cast = "String", true
+	case col.IsArray() && (udt == "json" || udt == "jsonb" || udt == "interval"):
+		cast = true
+	case udt == "json" || udt == "jsonb":
+		annotations = append(annotations, fmt.Sprintf("@%s(%s.JSON)", f.use("org.hibernate.annotations.JdbcTypeCode"), f.use("org.hibernate.type.SqlTypes")))
+	case udt == "interval":
+		annotations = append(annotations, fmt.Sprintf("@%s(%s.INTERVAL_SECOND)", f.use("org.hibernate.annotations.JdbcTypeCode"), f.use("org.hibernate.type.SqlTypes")))
+	}
+	if cast {
+		annotations = append(annotations, fmt.Sprintf("@%s(write = %s)", f.use("org.hibernate.annotations.ColumnTransformer"), javaString("?::"+col.FullType)))
+	}
+	field.typ = f.use(typ)
+	if col.IsArray() {
+		field.typ += "[]"
+	}
+
+	args := []string{"name = " + javaString(quoteIdent(col.ColumnName))}
+	if !col.Nullable() {
+		args = append(args, "nullable = false")
+	}
+	if !key && t.IsUnique(col.ColumnName) {
+		args = append(args, "unique = true")
+	}
+	if size, scale, ok := columnModifiers(col); ok && !col.IsArray() {
+		switch udt {
+		case "varchar", "bpchar":
+			args = append(args, fmt.Sprintf("length = %d", size))
+		case "numeric":
+			args = append(args, fmt.Sprintf("precision = %d", size), fmt.Sprintf("scale = %d", scale))
+		}
+	}
+	if col.IsGenerated() && !key {
+		annotations = append(annotations, "@"+f.use("org.hibernate.annotations.Generated"))
+		args = append(args, "insertable = false", "updatable = false")
+	}
+	annotations = append(annotations, fmt.Sprintf("@%s(%s)", f.use("jakarta.persistence.Column"), strings.Join(args, ", ")))
+	if col.Comment != "" {
+		annotations = append(annotations, fmt.Sprintf("@%s(%s)", f.use("org.hibernate.annotations.Comment"), javaString(col.Comment)))
+	}
+	field.annotations = annotations
+	return field
+}
+
+// relation returns the field of a relation. The owning side carries the
+// join columns, the inverse side names the owning field in mappedBy.
+func (w *jpaWriter) relation(f *javaFile, t *Table, rel Relation, readOnly bool) javaField {
+	fk := rel.ForeignKey
+	other := w.classes[rel.Other]
+	field := javaField{name: w.relations[t.Name][relationKey(rel.Owner, rel.Other, fk.ConstraintName)], typ: other}
+
+	source := t
+	if !rel.Owner {
+		source = w.schema.Table(rel.Other)
+	}
+	oneToOne := isUniqueColumns(source, fk.SourceColumns)
+	if !rel.Owner {
+		mappedBy := w.relations[rel.Other][relationKey(true, t.Name, fk.ConstraintName)]
+		if oneToOne {
+			field.annotations = []string{fmt.Sprintf("@%s(mappedBy = %s)", f.use("jakarta.persistence.OneToOne"), javaString(mappedBy))}
+			return field
+		}
+		field.annotations = []string{fmt.Sprintf("@%s(mappedBy = %s)", f.use("jakarta.persistence.OneToMany"), javaString(mappedBy))}
+		field.typ = f.use("java.util.List") + "<" + other + ">"
+		field.init = "new " + f.use("java.util.ArrayList") + "<>()"
+		return field
+	}
+
+	kind := "jakarta.persistence.ManyToOne"
+	if oneToOne {
+		kind = "jakarta.persistence.OneToOne"
+	}
+	args := []string{"fetch = " + f.use("jakarta.persistence.FetchType") + ".LAZY"}
+	if !anyNullable(t, fk.SourceColumns) {
+		args = append(args, "optional = false")
+	}
+	field.annotations = append(field.annotations, fmt.Sprintf("@%s(%s)", f.use(kind), strings.Join(args, ", ")))
+	if action, ok := jpaOnDelete[fk.OnDelete]; ok {
+		field.annotations = append(field.annotations, fmt.Sprintf("@%s(action = %s.%s)",
+			f.use("org.hibernate.annotations.OnDelete"), f.use("org.hibernate.annotations.OnDeleteAction"), action))
+	}
+	foreignKey := fmt.Sprintf("foreignKey = @%s(name = %s)", f.use("jakarta.persistence.ForeignKey"), javaString(fk.ConstraintName))
+	var joins []string
+	for i, c := range fk.SourceColumns {
+		join := fmt.Sprintf("name = %s, referencedColumnName = %s", javaString(quoteIdent(c)), javaString(quoteIdent(fk.TargetColumns[i])))
+		if readOnly {
+			join += ", insertable = false, updatable = false"
+		}
+		joins = append(joins, join)
+	}
+	joinColumn := f.use("jakarta.persistence.JoinColumn")
+	if len(joins) == 1 {
+		field.annotations = append(field.annotations, fmt.Sprintf("@%s(%s, %s)", joinColumn, joins[0], foreignKey))
+	} else {
+		var columns []string
+		for _, join := range joins {
+			columns = append(columns, fmt.Sprintf("@%s(%s)", joinColumn, join))
+		}
+		field.annotations = append(field.annotations, fmt.Sprintf("@%s(value = %s, %s)",
+			f.use("jakarta.persistence.JoinColumns"), javaAnnotations(columns, "    "), foreignKey))
+	}
+	return field
+}
+
+// javaAnnotations renders annotations as an array value, one per line
+// below an annotation indented by indent.
+func javaAnnotations(annotations []string, indent string) string {
+	if len(annotations) == 1 {
+		return annotations[0]
+	}
+	inner := "\n" + indent + "    "
+	return "{" + inner + strings.Join(annotations, ","+inner) + "\n" + indent + "}"
+}
+
+func quoteColumns(columns []string) []string {
+	quoted := make([]string, len(columns))
+	for i, c := range columns {
+		quoted[i] = quoteIdent(c)
+	}
+	return quoted
+}
@@ -0,0 +1,9 @@
+package internal
+
+import "testing"
+
+func TestGenerateJPA(t *testing.T) {
+	generateGolden(t, generateJPA, "jpa",
+		"PostStatus.java", "User.java", "UserRepository.java", "Post.java", "PostRepository.java",
+		"Tag.java", "TagRepository.java", "PostTagId.java", "PostTag.java", "PostTagRepository.java")
+}
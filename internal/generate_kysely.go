@@ -0,0 +1,140 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// kyselyAliases are the ColumnType aliases for columns that are read as
+// one type and written as others, declared in the output when used.
+var kyselyAliases = map[string]string{
+	"Int8":      "export type Int8 = ColumnType<string, bigint | number | string, bigint | number | string>;",
+	"Numeric":   "export type Numeric = ColumnType<string, number | string, number | string>;",
+	"Timestamp": "export type Timestamp = ColumnType<Date, Date | string, Date | string>;",
+	"Json": `export type JsonPrimitive = boolean | number | string | null;
+export type JsonValue = JsonPrimitive | JsonValue[] | { [key: string]: JsonValue };
+export type Json = ColumnType<JsonValue, string, string>;`,
+}
+
+var tsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// generateKysely renders the schema as the TypeScript types Kysely is
+// typed with: an interface per table, keyed by column name, with
+// Generated<> for columns the database fills and ColumnType<> where reads
+// and writes differ, the Selectable, Insertable and Updateable types of
+// each table and the Database interface listing the tables.
+func generateKysely(schema *Schema, opts GenerateOptions) ([]File, error) {
+	imports := map[string]bool{"Insertable": true, "Selectable": true, "Updateable": true}
+	aliases := make(map[string]bool)
+	enums := make(map[string]string)
+	var body strings.Builder
+
+	taken := make(map[string]bool)
+	for _, t := range schema.Tables {
+		taken[className(t.Name)] = true
+	}
+	for _, e := range schema.Enums {
+		name := pascalCase(e.Name)
+		for taken[name] {
+			name += "Enum"
+		}
+		taken[name] = true
+		enums[e.Name] = name
+		fmt.Fprintf(&body, "\nexport type %s = %s;\n", name, strings.Join(tsStrings(e.Values), " | "))
+	}
+
+	columnType := func(col Column) string {
+		udt := col.ElementType()
+		var typ string
+		if enum, ok := enums[udt]; ok {
+			typ = enum
+		} else if col.IsArray() {
+			typ = tsType(udt)
+		} else {
+			switch udt {
+			case "int8":
+				typ = "Int8"
+			case "numeric":
+				typ = "Numeric"
+			case "timestamp", "timestamptz", "date":
+				typ = "Timestamp"
+			case "json", "jsonb":
+				typ = "Json"
+			default:
+				typ = tsType(udt)
+			}
+			if kyselyAliases[typ] != "" {
+				aliases[typ] = true
+				imports["ColumnType"] = true
+			}
+		}
+		if col.IsArray() {
+			typ += "[]"
+		}
+		if col.Nullable() {
+			typ += " | null"
+		}
+		kind, _ := parseDefault(col.Default)
+		switch {
+		case col.IsIdentity && col.IdentityGeneration == "ALWAYS":
+			imports["GeneratedAlways"] = true
+			typ = "GeneratedAlways<" + typ + ">"
+		case col.IsGenerated() || kind != NoDefault:
+			imports["Generated"] = true
+			typ = "Generated<" + typ + ">"
+		}
+		return typ
+	}
+
+	var database strings.Builder
+	database.WriteString("\nexport interface Database {\n")
+	for _, t := range schema.Tables {
+		name := className(t.Name)
+		body.WriteString("\n")
+		if t.Comment != "" {
+			fmt.Fprintf(&body, "/** %s */\n", oneLine(t.Comment))
+		}
+		fmt.Fprintf(&body, "export interface %sTable {\n", name)
+		for _, col := range t.Columns {
+			if col.Comment != "" {
+				fmt.Fprintf(&body, "  /** %s */\n", oneLine(col.Comment))
+			}
+			fmt.Fprintf(&body, "  %s: %s;\n", tsKey(col.ColumnName), columnType(col))
+		}
+		body.WriteString("}\n\n")
+		fmt.Fprintf(&body, "export type %s = Selectable<%sTable>;\n", name, name)
+		fmt.Fprintf(&body, "export type New%s = Insertable<%sTable>;\n", name, name)
+		fmt.Fprintf(&body, "export type %sUpdate = Updateable<%sTable>;\n", name, name)
+		fmt.Fprintf(&database, "  %s: %sTable;\n", tsKey(t.Name), name)
+	}
+	database.WriteString("}\n")
+
+	var b strings.Builder
+	b.WriteString("// Generated by schema transform. Do not edit.\n")
+	var names []string
+	for name := range imports {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	fmt.Fprintf(&b, "import type { %s } from \"kysely\";\n", strings.Join(names, ", "))
+	for _, alias := range []string{"Int8", "Json", "Numeric", "Timestamp"} {
+		if aliases[alias] {
+			b.WriteString("\n" + kyselyAliases[alias] + "\n")
+		}
+	}
+	b.WriteString(body.String())
+	b.WriteString(database.String())
+	return []File{{Name: "database.ts", Content: []byte(b.String())}}, nil
+}
+
+// tsKey returns a name as an object key, quoted when it isn't an
+// identifier.
+func tsKey(name string) string {
+	if tsIdentifier.MatchString(name) {
+		return name
+	}
+	return strconv.Quote(name)
+}
@@ -0,0 +1,7 @@
+package internal
+
+import "testing"
+
+func TestGenerateKysely(t *testing.T) {
+	generateGolden(t, generateKysely, "kysely", "database.ts")
+}
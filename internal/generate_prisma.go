@@ -0,0 +1,437 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// prismaActions maps referential actions to Prisma's names.
+var prismaActions = map[string]string{
+	"CASCADE":     "Cascade",
+	"SET NULL":    "SetNull",
+	"SET DEFAULT": "SetDefault",
+	"RESTRICT":    "Restrict",
+	"NO ACTION":   "NoAction",
+	"":            "NoAction",
+}
+
+var prismaIndexTypes = map[string]string{
+	"hash":   "Hash",
+	"gist":   "Gist",
+	"gin":    "Gin",
+	"spgist": "SpGist",
+	"brin":   "Brin",
+}
+
+var (
+	prismaIdentifier  = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+	timePrecision     = regexp.MustCompile(`\((\d)\)`)
+	currentTimestamps = regexp.MustCompile(`(?i)^(now\(\)|current_timestamp)$`)
+)
+
+// prismaField is a line of a model block: the name, the type and the
+// attributes, aligned the way prisma format does.
+type prismaField struct {
+	Name, Type, Attributes string
+	Doc                    string
+}
+
+type prismaWriter struct {
+	schema *Schema
+	// fields maps each table's columns to their field names, models and
+	// enums the tables and enum types to their names in the schema.
+	fields map[string]map[string]string
+	models map[string]string
+	enums  map[string]string
+	// ignored holds the tables without a primary key or unique
+	// constraint, which Prisma Client can't handle.
+	ignored map[string]bool
+}
+
+// generatePrisma renders the schema as a schema.prisma with a Postgres
+// datasource, a model per table and an enum per enum type. Names follow
+// Prisma's conventions, with @map and @@map keeping the database names.
+func generatePrisma(schema *Schema, opts GenerateOptions) ([]File, error) {
+	w := &prismaWriter{
+		schema:  schema,
+		fields:  make(map[string]map[string]string),
+		models:  make(map[string]string),
+		enums:   make(map[string]string),
+		ignored: make(map[string]bool),
+	}
+	taken := make(map[string]bool)
+	for _, t := range schema.Tables {
+		model := className(t.Name)
+		for taken[model] {
+			model += "_"
+		}
+		taken[model] = true
+		w.models[t.Name] = model
+		w.ignored[t.Name] = t.PrimaryKey == nil && !slices.ContainsFunc(t.Indexes, func(idx Index) bool {
+			return idx.Unique && idx.Where == ""
+		}) && len(t.Uniques) == 0
+
+		fields := make(map[string]bool)
+		w.fields[t.Name] = make(map[string]string)
+		for _, col := range t.Columns {
+			name := prismaName(camelCase(col.ColumnName), "field")
+			for fields[name] {
+				name += "_"
+			}
+			fields[name] = true
+			w.fields[t.Name][col.ColumnName] = name
+		}
+	}
+	for _, e := range schema.Enums {
+		enum := prismaName(pascalCase(e.Name), "Enum")
+		for taken[enum] {
+			enum += "Enum"
+		}
+		taken[enum] = true
+		w.enums[e.Name] = enum
+	}
+
+	var b strings.Builder
+	b.WriteString("// Generated by schema transform. Do not edit.\n\n")
+	b.WriteString("generator client {\n  provider = \"prisma-client-js\"\n}\n\n")
+	b.WriteString("datasource db {\n  provider = \"postgresql\"\n  url      = env(\"DATABASE_URL\")\n}\n")
+	for i := range schema.Tables {
+		b.WriteString("\n")
+		b.WriteString(w.model(&schema.Tables[i]))
+	}
+	for _, e := range schema.Enums {
+		fmt.Fprintf(&b, "\nenum %s {\n", w.enums[e.Name])
+		for _, v := range e.Values {
+			value := prismaName(v, "value")
+			if value != v {
+				fmt.Fprintf(&b, "  %s @map(%s)\n", value, strconv.Quote(v))
+			} else {
+				fmt.Fprintf(&b, "  %s\n", value)
+			}
+		}
+		if w.enums[e.Name] != e.Name {
+			fmt.Fprintf(&b, "\n  @@map(%s)\n", strconv.Quote(e.Name))
+		}
+		b.WriteString("}\n")
+	}
+	return []File{{Name: "schema.prisma", Content: []byte(b.String())}}, nil
+}
+
+// prismaName makes a name a valid Prisma identifier, prefixing it when it
+// doesn't start with a letter.
+func prismaName(name, prefix string) string {
+	if prismaIdentifier.MatchString(name) {
+		return name
+	}
+	var b strings.Builder
+	for _, r := range name {
+		if r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	ident := b.String()
+	if !prismaIdentifier.MatchString(ident) {
+		ident = prefix + "_" + ident
+	}
+	return ident
+}
+
+func (w *prismaWriter) model(t *Table) string {
+	names := w.fields[t.Name]
+	fieldList := func(columns []string) (string, bool) {
+		var list []string
+		for _, c := range columns {
+			name, ok := names[c]
+			if !ok {
+				return "", false
+			}
+			list = append(list, name)
+		}
+		return "[" + strings.Join(list, ", ") + "]", true
+	}
+	// mapName returns the map: argument of a constraint whose name differs
+	// from the one Prisma would pick.
+	mapName := func(name, conventional string) string {
+		if name == conventional {
+			return ""
+		}
+		return "map: " + strconv.Quote(name)
+	}
+	args := func(first string, rest ...string) string {
+		var list []string
+		for _, a := range append([]string{first}, rest...) {
+			if a != "" {
+				list = append(list, a)
+			}
+		}
+		return strings.Join(list, ", ")
+	}
+
+	// Single column unique constraints become @unique, the rest block
+	// attributes.
+	uniques := make(map[string]string)
+	var blocks, comments []string
+	pkName := ""
+	if t.PrimaryKey != nil {
+		pkName = mapName(t.PrimaryKey.ConstraintName, t.Name+"_pkey")
+	}
+	if t.PrimaryKey != nil && len(t.PrimaryKey.Columns) > 1 {
+		list, _ := fieldList(t.PrimaryKey.Columns)
+		blocks = append(blocks, "@@id("+args(list, pkName)+")")
+	}
+	addUnique := func(name string, columns []string) {
+		conventional := mapName(name, t.Name+"_"+strings.Join(columns, "_")+"_key")
+		if len(columns) == 1 {
+			if _, ok := uniques[columns[0]]; !ok && t.Column(columns[0]) != nil {
+				uniques[columns[0]] = "@unique"
+				if conventional != "" {
+					uniques[columns[0]] = "@unique(" + conventional + ")"
+				}
+			}
+			return
+		}
+		if list, ok := fieldList(columns); ok {
+			blocks = append(blocks, "@@unique("+args(list, conventional)+")")
+		}
+	}
+	for _, u := range t.Uniques {
+		addUnique(u.ConstraintName, u.Columns)
+	}
+	for _, idx := range t.Indexes {
+		list, ok := fieldList(idx.Columns)
+		switch {
+		case !ok:
+			comments = append(comments, "// Expression index: "+oneLine(idx.Definition))
+		case idx.Where != "":
+			comments = append(comments, "// Partial index: "+oneLine(idx.Definition))
+		case idx.Unique:
+			addUnique(idx.Name, idx.Columns)
+		default:
+			typ := ""
+			if method, ok := prismaIndexTypes[idx.Method]; ok {
+				typ = "type: " + method
+			}
+			blocks = append(blocks, "@@index("+args(list, mapName(idx.Name, t.Name+"_"+strings.Join(idx.Columns, "_")+"_idx"), typ)+")")
+		}
+	}
+	for _, c := range t.Checks {
+		comments = append(comments, fmt.Sprintf("// CHECK %s: %s", c.ConstraintName, oneLine(c.Expression)))
+	}
+	if w.models[t.Name] != t.Name {
+		blocks = append(blocks, "@@map("+strconv.Quote(t.Name)+")")
+	}
+	if w.ignored[t.Name] {
+		blocks = append(blocks, "@@ignore")
+	}
+
+	var fields []prismaField
+	for _, col := range t.Columns {
+		fields = append(fields, w.field(t, col, uniques[col.ColumnName], pkName))
+	}
+	for _, rel := range w.schema.Relations(t.Name) {
+		fields = append(fields, w.relation(t, rel))
+	}
+
+	var b strings.Builder
+	if t.Comment != "" {
+		fmt.Fprintf(&b, "/// %s\n", oneLine(t.Comment))
+	}
+	if w.ignored[t.Name] {
+		b.WriteString("/// The table has no primary key or unique constraint, so Prisma Client can't use it.\n")
+	}
+	fmt.Fprintf(&b, "model %s {\n", w.models[t.Name])
+	nameWidth, typeWidth := 0, 0
+	for _, f := range fields {
+		nameWidth = max(nameWidth, len(f.Name))
+		typeWidth = max(typeWidth, len(f.Type))
+	}
+	for _, f := range fields {
+		if f.Doc != "" {
+			fmt.Fprintf(&b, "  /// %s\n", f.Doc)
+		}
+		line := fmt.Sprintf("  %-*s %-*s %s", nameWidth, f.Name, typeWidth, f.Type, f.Attributes)
+		b.WriteString(strings.TrimRight(line, " ") + "\n")
+	}
+	if len(blocks)+len(comments) > 0 {
+		b.WriteString("\n")
+	}
+	for _, line := range slices.Concat(blocks, comments) {
+		b.WriteString("  " + line + "\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func (w *prismaWriter) field(t *Table, col Column, unique, pkName string) prismaField {
+	name := w.fields[t.Name][col.ColumnName]
+	typ, native := w.fieldType(col)
+	var attrs []string
+	if t.PrimaryKey != nil && len(t.PrimaryKey.Columns) == 1 && t.PrimaryKey.Columns[0] == col.ColumnName {
+		if pkName != "" {
+			attrs = append(attrs, "@id("+pkName+")")
+		} else {
+			attrs = append(attrs, "@id")
+		}
+	}
+	if unique != "" {
+		attrs = append(attrs, unique)
+	}
+
+	kind, value := parseDefault(col.Default)
+	switch {
+	case col.IsSerial() || col.IsIdentity:
+		attrs = append(attrs, "@default(autoincrement())")
+	case kind == StringDefault && w.enums[col.UDTName] != "":
+		attrs = append(attrs, "@default("+prismaName(value, "value")+")")
+	case kind == StringDefault && (typ == "String" || typ == "String?"):
+		attrs = append(attrs, "@default("+strconv.Quote(value)+")")
+	case kind == NumberDefault || kind == BoolDefault:
+		attrs = append(attrs, "@default("+value+")")
+	case kind == NowDefault && currentTimestamps.MatchString(value):
+		attrs = append(attrs, "@default(now())")
+	case kind != NoDefault:
+		attrs = append(attrs, "@default(dbgenerated("+strconv.Quote(col.Default)+"))")
+	}
+	if name != col.ColumnName {
+		attrs = append(attrs, "@map("+strconv.Quote(col.ColumnName)+")")
+	}
+	if native != "" {
+		attrs = append(attrs, native)
+	}
+	return prismaField{Name: name, Type: typ, Attributes: strings.Join(attrs, " "), Doc: oneLine(col.Comment)}
+}
+
+// fieldType returns the Prisma type of a column and its native type
+// attribute, which is left out where it's Prisma's default for the type.
+func (w *prismaWriter) fieldType(col Column) (string, string) {
+	udt := col.ElementType()
+	var typ, native string
+	if enum, ok := w.enums[udt]; ok {
+		typ = enum
+	} else {
+		size, scale, sized := columnModifiers(col)
+		precision := "6"
+		if m := timePrecision.FindStringSubmatch(col.FullType); m != nil {
+			precision = m[1]
+		}
+		switch udt {
+		case "int2":
+			typ, native = "Int", "@db.SmallInt"
+		case "int4":
+			typ = "Int"
+		case "int8":
+			typ = "BigInt"
+		case "float4":
+			typ, native = "Float", "@db.Real"
+		case "float8":
+			typ = "Float"
+		case "numeric":
+			typ = "Decimal"
+			if sized {
+				native = fmt.Sprintf("@db.Decimal(%d, %d)", size, scale)
+			}
+		case "bool":
+			typ = "Boolean"
+		case "varchar", "bpchar":
+			typ, native = "String", map[string]string{"varchar": "@db.VarChar", "bpchar": "@db.Char"}[udt]
+			if sized {
+				native += fmt.Sprintf("(%d)", size)
+			}
+		case "text":
+			typ = "String"
+		case "uuid":
+			typ, native = "String", "@db.Uuid"
+		case "json":
+			typ, native = "Json", "@db.Json"
+		case "jsonb":
+			typ = "Json"
+		case "date":
+			typ, native = "DateTime", "@db.Date"
+		case "timestamp":
+			typ, native = "DateTime", "@db.Timestamp("+precision+")"
+		case "timestamptz":
+			typ, native = "DateTime", "@db.Timestamptz("+precision+")"
+		case "time":
+			typ, native = "DateTime", "@db.Time("+precision+")"
+		case "timetz":
+			typ, native = "DateTime", "@db.Timetz("+precision+")"
+		case "bytea":
+			typ = "Bytes"
+		case "inet", "citext", "xml":
+			typ, native = "String", "@db."+map[string]string{"inet": "Inet", "citext": "Citext", "xml": "Xml"}[udt]
+		case "money":
+			typ, native = "Decimal", "@db.Money"
+		default:
+			name := col.FullType
+			if col.IsArray() {
+				name = strings.TrimSuffix(name, "[]")
+			}
+			typ = "Unsupported(" + strconv.Quote(name) + ")"
+		}
+	}
+	switch {
+	case col.IsArray():
+		// Prisma lists can't be optional.
+		typ += "[]"
+	case col.Nullable():
+		typ += "?"
+	}
+	return typ, native
+}
+
+// relation renders a relation field. Ambiguous foreign keys are told apart
+// by naming the relation after the constraint on both sides.
+func (w *prismaWriter) relation(t *Table, rel Relation) prismaField {
+	fk := rel.ForeignKey
+	typ := w.models[rel.Other]
+	var args []string
+	if w.schema.isAmbiguous(fk) {
+		args = append(args, strconv.Quote(fk.ConstraintName))
+	}
+	switch {
+	case rel.Owner:
+		fields := make([]string, len(fk.SourceColumns))
+		for i, c := range fk.SourceColumns {
+			fields[i] = w.fields[t.Name][c]
+		}
+		references := make([]string, len(fk.TargetColumns))
+		for i, c := range fk.TargetColumns {
+			references[i] = w.fields[rel.Other][c]
+		}
+		args = append(args, "fields: ["+strings.Join(fields, ", ")+"]", "references: ["+strings.Join(references, ", ")+"]")
+		optional := anyNullable(t, fk.SourceColumns)
+		// Prisma's defaults: SetNull on delete for optional relations,
+		// Restrict for required ones, and Cascade on update.
+		onDelete := "Restrict"
+		if optional {
+			onDelete = "SetNull"
+			typ += "?"
+		}
+		if action := prismaActions[fk.OnDelete]; action != onDelete {
+			args = append(args, "onDelete: "+action)
+		}
+		if action := prismaActions[fk.OnUpdate]; action != "Cascade" {
+			args = append(args, "onUpdate: "+action)
+		}
+		if fk.ConstraintName != t.Name+"_"+strings.Join(fk.SourceColumns, "_")+"_fkey" {
+			args = append(args, "map: "+strconv.Quote(fk.ConstraintName))
+		}
+	case rel.Many:
+		typ += "[]"
+	default:
+		typ += "?"
+	}
+	attrs := ""
+	if len(args) > 0 {
+		attrs = "@relation(" + strings.Join(args, ", ") + ")"
+	}
+	if w.ignored[rel.Other] && !w.ignored[t.Name] {
+		attrs = strings.TrimSpace(attrs + " @ignore")
+	}
+	return prismaField{Name: camelCase(rel.Name), Type: typ, Attributes: attrs}
+}
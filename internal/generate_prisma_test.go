@@ -0,0 +1,31 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGeneratePrisma(t *testing.T) {
+	files, err := generatePrisma(blogSchema(), GenerateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Name != "schema.prisma" {
+		t.Fatalf("got %d files, want schema.prisma", len(files))
+	}
+	golden(t, "prisma/schema.prisma", files[0].Content)
+}
+
+func TestGeneratePrismaIgnoresUnkeyedTables(t *testing.T) {
+	schema := blogSchemaWith(addTable(Table{Name: "audit_log", Columns: []Column{column("message", "text", true)}}))
+	files, err := generatePrisma(schema, GenerateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(files[0].Content)
+	model := content[strings.Index(content, "model AuditLog {"):]
+	model = model[:strings.Index(model, "}")]
+	if !strings.Contains(model, "@@ignore") {
+		t.Errorf("table without a key is not ignored:\n%s", model)
+	}
+}
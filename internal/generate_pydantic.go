@@ -0,0 +1,305 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// pydanticReserved are the names BaseModel keeps for itself; columns with
+// these names are validated under another field name with an alias.
+var pydanticReserved = map[string]bool{
+	"construct": true, "copy": true, "dict": true, "fields": true, "from_orm": true, "json": true,
+	"parse_file": true, "parse_obj": true, "parse_raw": true, "schema": true, "schema_json": true,
+	"update_forward_refs": true, "validate": true,
+}
+
+type pydanticWriter struct {
+	schema *Schema
+	// fields maps each table's columns to their field names.
+	fields map[string]map[string]string
+	enums  map[string]string
+	// The names imported from each module.
+	imports map[string]map[string]bool
+}
+
+func (w *pydanticWriter) use(module string, names ...string) {
+	if w.imports[module] == nil {
+		w.imports[module] = make(map[string]bool)
+	}
+	for _, name := range names {
+		w.imports[module][name] = true
+	}
+}
+
+// generatePydantic renders the schema as Pydantic v2 models to validate
+// API payloads with: a str enum per enum type and per table a Read model
+// of the rows, a Create model without the columns the database generates
+// and an Update model whose fields are all optional. Fields carry the
+// lengths and precision of their types and the parts of the CHECK
+// constraints Pydantic can express.
+func generatePydantic(schema *Schema, opts GenerateOptions) ([]File, error) {
+	w := &pydanticWriter{
+		schema:  schema,
+		fields:  make(map[string]map[string]string),
+		enums:   make(map[string]string),
+		imports: make(map[string]map[string]bool),
+	}
+	w.use("pydantic", "BaseModel")
+
+	taken := make(map[string]bool)
+	for _, t := range schema.Tables {
+		for _, suffix := range []string{"Read", "Create", "Update"} {
+			taken[className(t.Name)+suffix] = true
+		}
+		used := make(map[string]bool)
+		w.fields[t.Name] = make(map[string]string)
+		for _, col := range t.Columns {
+			name := pythonIdent(col.ColumnName)
+			if strings.HasPrefix(name, "model_") {
+				name = "field_" + name
+			}
+			for used[name] || pydanticReserved[name] {
+				name += "_"
+			}
+			used[name] = true
+			w.fields[t.Name][col.ColumnName] = name
+		}
+	}
+
+	var body strings.Builder
+	for _, e := range schema.Enums {
+		w.use("enum")
+		name := pascalCase(e.Name)
+		for taken[name] {
+			name += "Enum"
+		}
+		taken[name] = true
+		w.enums[e.Name] = name
+		fmt.Fprintf(&body, "\n\nclass %s(str, enum.Enum):\n", name)
+		for _, v := range e.Values {
+			fmt.Fprintf(&body, "    %s = %s\n", pythonConstant(v), pythonString(v))
+		}
+	}
+	for i := range schema.Tables {
+		t := &schema.Tables[i]
+		body.WriteString(w.model(t, "Read"))
+		body.WriteString(w.model(t, "Create"))
+		body.WriteString(w.model(t, "Update"))
+	}
+
+	var b strings.Builder
+	b.WriteString("# Generated by schema transform. Do not edit.\n")
+	var std []string
+	for _, module := range []string{"datetime", "decimal", "enum", "uuid"} {
+		if w.imports[module] != nil {
+			std = append(std, "import "+module+"\n")
+		}
+	}
+	if w.imports["typing"] != nil {
+		std = append(std, "from typing import "+importList(w.imports["typing"])+"\n")
+	}
+	if len(std) > 0 {
+		b.WriteString(strings.Join(std, "") + "\n")
+	}
+	fmt.Fprintf(&b, "from pydantic import %s\n", importList(w.imports["pydantic"]))
+	b.WriteString(body.String())
+	return []File{{Name: "schemas.py", Content: []byte(b.String())}}, nil
+}
+
+// model renders one variant of a table's models. Read models are read from
+// ORM objects as well as dicts, Create models leave out the columns the
+// database generates and make those with a default optional, and Update
+// models leave out the primary key too and make every field optional.
+func (w *pydanticWriter) model(t *Table, variant string) string {
+	var fields []string
+	aliased := false
+	for _, col := range t.Columns {
+		if variant != "Read" && col.IsGenerated() || variant == "Update" && t.IsPrimaryKey(col.ColumnName) {
+			continue
+		}
+		name := w.fields[t.Name][col.ColumnName]
+		typ := w.fieldType(t, col)
+		// def is the default of the field, args the other arguments of
+		// its Field().
+		var def string
+		var args []string
+		kind, value := parseDefault(col.Default)
+		switch {
+		case variant == "Update":
+			w.use("typing", "Optional")
+			typ = "Optional[" + typ + "]"
+			def = "None"
+		case col.Nullable():
+			w.use("typing", "Optional")
+			typ = "Optional[" + typ + "]"
+			if variant == "Create" {
+				def = w.defaultValue(col, kind, value, "None")
+			}
+		case variant == "Create" && kind != NoDefault:
+			def = w.defaultValue(col, kind, value, "")
+			if def == "" {
+				// The database fills in the value when the field is left
+				// unset, so dump the model with exclude_unset=True.
+				w.use("typing", "Optional")
+				typ = "Optional[" + typ + "]"
+				def = "None"
+			}
+		}
+		if name != col.ColumnName {
+			aliased = true
+			args = append(args, "alias="+pythonString(col.ColumnName))
+		}
+		if col.Comment != "" {
+			args = append(args, "description="+pythonString(col.Comment))
+		}
+		field := fmt.Sprintf("    %s: %s", name, typ)
+		switch {
+		case len(args) > 0:
+			w.use("pydantic", "Field")
+			if def == "" {
+				def = "..."
+			}
+			field += " = Field(" + strings.Join(append([]string{def}, args...), ", ") + ")"
+		case def != "":
+			field += " = " + def
+		}
+		fields = append(fields, field+"\n")
+	}
+
+	var config []string
+	if variant == "Read" {
+		config = append(config, "from_attributes=True")
+	}
+	if aliased {
+		config = append(config, "populate_by_name=True")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n\nclass %s%s(BaseModel):\n", className(t.Name), variant)
+	if t.Comment != "" && variant == "Read" {
+		fmt.Fprintf(&b, "    %s\n\n", pythonBlock(oneLine(t.Comment)))
+	}
+	if len(config) > 0 {
+		w.use("pydantic", "ConfigDict")
+		fmt.Fprintf(&b, "    model_config = ConfigDict(%s)\n\n", strings.Join(config, ", "))
+	}
+	if len(fields) == 0 {
+		b.WriteString("    pass\n")
+	}
+	b.WriteString(strings.Join(fields, ""))
+	return b.String()
+}
+
+// fieldType returns the annotation of a column's values, with the rules
+// Pydantic checks them by.
+func (w *pydanticWriter) fieldType(t *Table, col Column) string {
+	if col.IsArray() {
+		w.use("typing", "List")
+		return "List[" + w.fieldType(t, elementColumn(col)) + "]"
+	}
+	typ := w.pythonType(col)
+	if _, isEnum := w.enums[col.UDTName]; isEnum {
+		return typ
+	}
+	rules := columnRules(t, col)
+	if len(rules.values) > 0 {
+		var values []string
+		for _, v := range rules.values {
+			if v.string {
+				values = append(values, pythonString(v.value))
+			} else {
+				values = append(values, v.value)
+			}
+		}
+		w.use("typing", "Literal")
+		return "Literal[" + strings.Join(values, ", ") + "]"
+	}
+
+	var constraints []string
+	switch typ {
+	case "str":
+		if rules.minLength > 0 {
+			constraints = append(constraints, fmt.Sprintf("min_length=%d", rules.minLength))
+		}
+		if rules.maxLength > 0 {
+			constraints = append(constraints, fmt.Sprintf("max_length=%d", rules.maxLength))
+		}
+	case "int", "float", "decimal.Decimal":
+		if rules.min != nil {
+			constraints = append(constraints, map[bool]string{false: "ge=", true: "gt="}[rules.min.exclusive]+rules.min.value)
+		}
+		if rules.max != nil {
+			constraints = append(constraints, map[bool]string{false: "le=", true: "lt="}[rules.max.exclusive]+rules.max.value)
+		}
+		if precision, scale, ok := columnModifiers(col); ok && col.UDTName == "numeric" {
+			constraints = append(constraints, fmt.Sprintf("max_digits=%d", precision), fmt.Sprintf("decimal_places=%d", scale))
+		}
+	}
+	if len(constraints) == 0 {
+		return typ
+	}
+	w.use("typing", "Annotated")
+	w.use("pydantic", "Field")
+	return fmt.Sprintf("Annotated[%s, Field(%s)]", typ, strings.Join(constraints, ", "))
+}
+
+// pythonType returns the Python type of a column's values.
+func (w *pydanticWriter) pythonType(col Column) string {
+	if enum, ok := w.enums[col.UDTName]; ok {
+		return enum
+	}
+	switch col.UDTName {
+	case "int2", "int4", "int8":
+		return "int"
+	case "float4", "float8":
+		return "float"
+	case "numeric", "money":
+		w.use("decimal")
+		return "decimal.Decimal"
+	case "bool":
+		return "bool"
+	case "uuid":
+		w.use("uuid")
+		return "uuid.UUID"
+	case "json", "jsonb":
+		w.use("typing", "Any")
+		return "Any"
+	case "date":
+		w.use("datetime")
+		return "datetime.date"
+	case "timestamp", "timestamptz":
+		w.use("datetime")
+		return "datetime.datetime"
+	case "time", "timetz":
+		w.use("datetime")
+		return "datetime.time"
+	case "interval":
+		w.use("datetime")
+		return "datetime.timedelta"
+	case "bytea":
+		return "bytes"
+	}
+	return "str"
+}
+
+// defaultValue returns a column default as a Python literal, or fallback
+// when it isn't one.
+func (w *pydanticWriter) defaultValue(col Column, kind DefaultKind, value, fallback string) string {
+	if col.IsArray() {
+		return fallback
+	}
+	typ := w.pythonType(col)
+	switch {
+	case kind == BoolDefault && typ == "bool":
+		return map[string]string{"true": "True", "false": "False"}[value]
+	case kind == NumberDefault && typ == "decimal.Decimal":
+		return "decimal.Decimal(" + pythonString(value) + ")"
+	case kind == NumberDefault && (typ == "float" || typ == "int" && !strings.ContainsAny(value, ".eE")):
+		return value
+	case kind == StringDefault && typ == "str":
+		return pythonString(value)
+	case kind == StringDefault && w.enums[col.UDTName] == typ:
+		return typ + "." + pythonConstant(value)
+	}
+	return fallback
+}
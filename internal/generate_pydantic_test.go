@@ -0,0 +1,8 @@
+package internal
+
+import "testing"
+
+func TestGeneratePydantic(t *testing.T) {
+	files := generateGolden(t, generatePydantic, "pydantic", "schemas.py")
+	compilePython(t, files, "import schemas", "pydantic")
+}
@@ -0,0 +1,255 @@
+package internal
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// seaormTypes maps udt names to the Rust types of SeaORM model fields,
+// most of them re-exported by sea_orm::entity::prelude.
+var seaormTypes = map[string]string{
+	"int2": "i16", "int4": "i32", "int8": "i64", "float4": "f32", "float8": "f64",
+	"numeric": "Decimal", "bool": "bool", "varchar": "String", "bpchar": "String",
+	"text": "String", "citext": "String", "uuid": "Uuid", "json": "Json", "jsonb": "Json",
+	"date": "Date", "timestamp": "DateTime", "timestamptz": "DateTimeWithTimeZone",
+	"time": "Time", "bytea": "Vec<u8>", "inet": "IpNetwork", "cidr": "IpNetwork",
+}
+
+var seaormActions = map[string]string{
+	"CASCADE":     "Cascade",
+	"SET NULL":    "SetNull",
+	"SET DEFAULT": "SetDefault",
+	"RESTRICT":    "Restrict",
+	"NO ACTION":   "NoAction",
+	"":            "NoAction",
+}
+
+type seaormWriter struct {
+	schema *Schema
+	// modules maps the tables with a primary key to their entity modules,
+	// enums the enum types to their ActiveEnums.
+	modules map[string]string
+	enums   map[string]string
+}
+
+// generateSeaORM renders the schema as SeaORM entities the way sea-orm-cli
+// does: a module per table with its Model, Relation enum and Related
+// impls, sea_orm_active_enums.rs with an ActiveEnum per enum type, the
+// prelude and mod.rs.
+func generateSeaORM(schema *Schema, opts GenerateOptions) ([]File, error) {
+	w := &seaormWriter{schema: schema, modules: make(map[string]string), enums: make(map[string]string)}
+	var skipped, modules []string
+	for _, t := range schema.Tables {
+		// SeaORM entities need a primary key.
+		if t.PrimaryKey == nil {
+			skipped = append(skipped, t.Name)
+			continue
+		}
+		w.modules[t.Name] = rustIdent(t.Name)
+		modules = append(modules, w.modules[t.Name])
+	}
+	for _, e := range schema.Enums {
+		w.enums[e.Name] = pascalCase(e.Name)
+	}
+
+	var files []File
+	var prelude strings.Builder
+	prelude.WriteString("//! Generated by schema transform. Do not edit.\n\n")
+	for _, t := range schema.Tables {
+		module, ok := w.modules[t.Name]
+		if !ok {
+			continue
+		}
+		files = append(files, File{Name: module + ".rs", Content: []byte(w.entity(&t))})
+		fmt.Fprintf(&prelude, "pub use super::%s::Entity as %s;\n", module, pascalCase(t.Name))
+	}
+	files = append(files, File{Name: "prelude.rs", Content: []byte(prelude.String())})
+
+	if len(schema.Enums) > 0 {
+		var b strings.Builder
+		b.WriteString("//! Generated by schema transform. Do not edit.\n\n")
+		b.WriteString("use sea_orm::entity::prelude::*;\n")
+		for _, e := range schema.Enums {
+			b.WriteString("\n#[derive(Debug, Clone, PartialEq, Eq, EnumIter, DeriveActiveEnum)]\n")
+			fmt.Fprintf(&b, "#[sea_orm(rs_type = \"String\", db_type = \"Enum\", enum_name = %q)]\n", e.Name)
+			fmt.Fprintf(&b, "pub enum %s {\n", w.enums[e.Name])
+			for _, v := range e.Values {
+				variant := pascalCase(v)
+				if variant == "" || variant[0] >= '0' && variant[0] <= '9' {
+					variant = "V" + variant
+				}
+				fmt.Fprintf(&b, "    #[sea_orm(string_value = %q)]\n    %s,\n", v, variant)
+			}
+			b.WriteString("}\n")
+		}
+		files = append(files, File{Name: "sea_orm_active_enums.rs", Content: []byte(b.String())})
+		modules = append(modules, "sea_orm_active_enums")
+	}
+
+	var mod strings.Builder
+	mod.WriteString("//! Generated by schema transform. Do not edit.\n\npub mod prelude;\n\n")
+	slices.Sort(modules)
+	for _, module := range modules {
+		fmt.Fprintf(&mod, "pub mod %s;\n", module)
+	}
+	if len(skipped) > 0 {
+		fmt.Fprintf(&mod, "\n// Tables without a primary key, which SeaORM entities need: %s\n", strings.Join(skipped, ", "))
+	}
+	files = append(files, File{Name: "mod.rs", Content: []byte(mod.String())})
+	return files, nil
+}
+
+// field returns the attributes and type of a model field.
+func (w *seaormWriter) field(t *Table, col Column) ([]string, string) {
+	var attrs []string
+	if t.IsPrimaryKey(col.ColumnName) {
+		attrs = append(attrs, "primary_key")
+		if !col.IsGenerated() {
+			attrs = append(attrs, "auto_increment = false")
+		}
+	}
+	if rustIdent(col.ColumnName) != col.ColumnName {
+		attrs = append(attrs, fmt.Sprintf("column_name = %q", col.ColumnName))
+	}
+
+	udt := col.ElementType()
+	typ, ok := seaormTypes[udt]
+	if enum, isEnum := w.enums[udt]; isEnum {
+		typ, ok = enum, true
+	}
+	size, scale, sized := columnModifiers(col)
+	switch {
+	case !ok:
+		typ = "String"
+		attrs = append(attrs, fmt.Sprintf("column_type = %q", fmt.Sprintf("custom(%q)", udt)))
+	case col.IsArray():
+	case udt == "text":
+		attrs = append(attrs, `column_type = "Text"`)
+	case udt == "jsonb":
+		attrs = append(attrs, `column_type = "JsonBinary"`)
+	case udt == "numeric" && sized:
+		attrs = append(attrs, fmt.Sprintf(`column_type = "Decimal(Some((%d, %d)))"`, size, scale))
+	case udt == "varchar" && sized:
+		attrs = append(attrs, fmt.Sprintf(`column_type = "String(StringLen::N(%d))"`, size))
+	case udt == "bpchar" && sized:
+		attrs = append(attrs, fmt.Sprintf(`column_type = "Char(Some(%d))"`, size))
+	}
+	if !t.IsPrimaryKey(col.ColumnName) && t.IsUnique(col.ColumnName) {
+		attrs = append(attrs, "unique")
+	}
+	if col.IsArray() {
+		typ = "Vec<" + typ + ">"
+	}
+	if col.Nullable() {
+		typ = "Option<" + typ + ">"
+	}
+	return attrs, typ
+}
+
+func (w *seaormWriter) entity(t *Table) string {
+	var fields []string
+	eq := true
+	var enums []string
+	for _, col := range t.Columns {
+		attrs, typ := w.field(t, col)
+		if strings.Contains(typ, "f32") || strings.Contains(typ, "f64") {
+			eq = false
+		}
+		if enum, ok := w.enums[col.ElementType()]; ok && !slices.Contains(enums, enum) {
+			enums = append(enums, enum)
+		}
+		var f strings.Builder
+		if col.Comment != "" {
+			fmt.Fprintf(&f, "    /// %s\n", oneLine(col.Comment))
+		}
+		if len(attrs) > 0 {
+			fmt.Fprintf(&f, "    #[sea_orm(%s)]\n", strings.Join(attrs, ", "))
+		}
+		fmt.Fprintf(&f, "    pub %s: %s,\n", rustIdent(col.ColumnName), typ)
+		fields = append(fields, f.String())
+	}
+
+	// Relations to entities joined by a single foreign key also get a
+	// Related impl; has_many and has_one go through it, so they're only
+	// declared for those.
+	var variants, related []string
+	for _, rel := range w.schema.Relations(t.Name) {
+		module, ok := w.modules[rel.Other]
+		if !ok {
+			continue
+		}
+		fk := rel.ForeignKey
+		entity := "super::" + module + "::Entity"
+		if rel.Other == t.Name {
+			entity = "Entity"
+		}
+		ambiguous := w.schema.isAmbiguous(fk)
+		var attr string
+		switch {
+		case rel.Owner:
+			var from, to []string
+			for _, c := range fk.SourceColumns {
+				from = append(from, "Column::"+pascalCase(rustIdent(c)))
+			}
+			prefix := "super::" + module + "::"
+			if rel.Other == t.Name {
+				prefix = ""
+			}
+			for _, c := range fk.TargetColumns {
+				to = append(to, prefix+"Column::"+pascalCase(rustIdent(c)))
+			}
+			attr = fmt.Sprintf("belongs_to = %q, from = %q, to = %q, on_update = %q, on_delete = %q",
+				entity, seaormColumns(from), seaormColumns(to), seaormActions[fk.OnUpdate], seaormActions[fk.OnDelete])
+		case ambiguous:
+			continue
+		case rel.Many:
+			attr = fmt.Sprintf("has_many = %q", entity)
+		default:
+			attr = fmt.Sprintf("has_one = %q", entity)
+		}
+		variant := pascalCase(rel.Name)
+		variants = append(variants, fmt.Sprintf("    #[sea_orm(%s)]\n    %s,\n", attr, variant))
+		if !ambiguous {
+			related = append(related, fmt.Sprintf("\nimpl Related<%s> for Entity {\n    fn to() -> RelationDef {\n        Relation::%s.def()\n    }\n}\n", entity, variant))
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("//! Generated by schema transform. Do not edit.\n\n")
+	b.WriteString("use sea_orm::entity::prelude::*;\n")
+	if len(enums) > 0 {
+		slices.Sort(enums)
+		if len(enums) == 1 {
+			fmt.Fprintf(&b, "\nuse super::sea_orm_active_enums::%s;\n", enums[0])
+		} else {
+			fmt.Fprintf(&b, "\nuse super::sea_orm_active_enums::{%s};\n", strings.Join(enums, ", "))
+		}
+	}
+	derives := "Clone, Debug, PartialEq, DeriveEntityModel"
+	if eq {
+		derives = "Clone, Debug, PartialEq, DeriveEntityModel, Eq"
+	}
+	if t.Comment != "" {
+		fmt.Fprintf(&b, "\n/// %s", oneLine(t.Comment))
+	}
+	fmt.Fprintf(&b, "\n#[derive(%s)]\n", derives)
+	fmt.Fprintf(&b, "#[sea_orm(table_name = %q)]\n", t.Name)
+	b.WriteString("pub struct Model {\n")
+	b.WriteString(strings.Join(fields, ""))
+	b.WriteString("}\n\n#[derive(Copy, Clone, Debug, EnumIter, DeriveRelation)]\npub enum Relation {\n")
+	b.WriteString(strings.Join(variants, ""))
+	b.WriteString("}\n")
+	b.WriteString(strings.Join(related, ""))
+	b.WriteString("\nimpl ActiveModelBehavior for ActiveModel {}\n")
+	return b.String()
+}
+
+// seaormColumns renders the from and to columns of a relation, a tuple
+// for composite foreign keys.
+func seaormColumns(columns []string) string {
+	if len(columns) == 1 {
+		return columns[0]
+	}
+	return "(" + strings.Join(columns, ", ") + ")"
+}
@@ -0,0 +1,8 @@
+package internal
+
+import "testing"
+
+func TestGenerateSeaORM(t *testing.T) {
+	generateGolden(t, generateSeaORM, "seaorm",
+		"users.rs", "posts.rs", "tags.rs", "post_tags.rs", "prelude.rs", "sea_orm_active_enums.rs", "mod.rs")
+}
@@ -0,0 +1,136 @@
+package internal
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type springJDBCWriter struct {
+	*javaWriter
+}
+
+// generateSpringJDBC renders the schema as Spring Data JDBC aggregates: a
+// class per table mapped with @Table and @Column, foreign keys to other
+// aggregates held as AggregateReferences, an id class per composite key, a
+// Java enum per enum type whose values can name its constants and a
+// ListCrudRepository per table with a primary key. Spring Data JDBC quotes
+// the names it writes, so they're kept as they are in the database.
+func generateSpringJDBC(schema *Schema, opts GenerateOptions) ([]File, error) {
+	w := &springJDBCWriter{newJavaWriter(schema, opts)}
+	var files []File
+	for _, e := range schema.Enums {
+		// Enums are written by constant name; the other enum columns are
+		// mapped as strings.
+		if !javaPlainEnum(e) {
+			delete(w.enums, e.Name)
+			continue
+		}
+		files = append(files, w.enum(e, false))
+	}
+	for i := range schema.Tables {
+		files = append(files, w.aggregate(&schema.Tables[i])...)
+	}
+	return files, nil
+}
+
+func (w *springJDBCWriter) aggregate(t *Table) []File {
+	class := w.classes[t.Name]
+	f := newJavaFile(w.pkg)
+	composite := t.PrimaryKey != nil && len(t.PrimaryKey.Columns) > 1
+
+	var files []File
+	var fields []javaField
+	if composite {
+		idFile := newJavaFile(w.pkg)
+		var idFields []javaField
+		for _, c := range t.PrimaryKey.Columns {
+			idFields = append(idFields, w.column(idFile, t, *t.Column(c), true))
+		}
+		files = append(files, File{Name: w.ids[t.Name] + ".java", Content: w.idClass(idFile, w.ids[t.Name], nil, idFields)})
+		fields = append(fields, javaField{
+			annotations: []string{"@" + f.use("org.springframework.data.annotation.Id")},
+			typ:         w.ids[t.Name],
+			name:        w.idFields[t.Name],
+		})
+	}
+	for _, col := range t.Columns {
+		if composite && t.IsPrimaryKey(col.ColumnName) {
+			continue
+		}
+		fields = append(fields, w.column(f, t, col, t.IsPrimaryKey(col.ColumnName)))
+	}
+
+	var b strings.Builder
+	if t.PrimaryKey == nil {
+		fmt.Fprintf(&b, "// %s has no primary key, so there is no repository for it.\n", t.Name)
+	}
+	fmt.Fprintf(&b, "@%s(%s)\n", f.use("org.springframework.data.relational.core.mapping.Table"), javaString(t.Name))
+	fmt.Fprintf(&b, "public class %s {\n", class)
+	writeJavaMembers(&b, fields)
+	b.WriteString("}\n")
+	files = append(files, File{Name: class + ".java", Content: f.render(b.String())})
+	if t.PrimaryKey == nil {
+		return files
+	}
+
+	// save() inserts the aggregates whose id is null and updates the
+	// others, which doesn't fit keys the application assigns.
+	comment := ""
+	if composite || !t.Column(t.PrimaryKey.Columns[0]).IsGenerated() {
+		comment = fmt.Sprintf("save() updates %s rows whose key is set; insert new ones with JdbcAggregateTemplate.insert.", t.Name)
+	}
+	files = append(files, w.repository(class, "org.springframework.data.repository.ListCrudRepository", w.idType(t), comment))
+	return files
+}
+
+// idType returns the type of an aggregate's id, qualified where it needs an
+// import.
+func (w *springJDBCWriter) idType(t *Table) string {
+	if len(t.PrimaryKey.Columns) > 1 {
+		return w.pkg + "." + w.ids[t.Name]
+	}
+	return w.javaType(*t.Column(t.PrimaryKey.Columns[0]))
+}
+
+// javaType returns the type of a column, qualified where it needs an
+// import. Types without a JDBC mapping are read as strings.
+func (w *springJDBCWriter) javaType(col Column) string {
+	udt := col.ElementType()
+	typ, ok := javaTypes[udt]
+	if enum, isEnum := w.enums[udt]; isEnum {
+		typ, ok = w.pkg+"."+enum, true
+	}
+	if !ok || udt == "interval" {
+		typ = "String"
+	}
+	if col.IsArray() {
+		typ += "[]"
+	}
+	return typ
+}
+
+// column returns the field of a column. Key columns are part of the
+// aggregate's id, held by the field itself or by its id class.
+func (w *springJDBCWriter) column(f *javaFile, t *Table, col Column, key bool) javaField {
+	field := javaField{name: w.fields[t.Name][col.ColumnName], typ: f.use(w.javaType(col))}
+	if key && w.ids[t.Name] == "" {
+		field.annotations = append(field.annotations, "@"+f.use("org.springframework.data.annotation.Id"))
+	}
+	if col.IsGenerated() && !key {
+		field.annotations = append(field.annotations, "@"+f.use("org.springframework.data.annotation.ReadOnlyProperty"))
+	}
+	field.annotations = append(field.annotations, fmt.Sprintf("@%s(%s)",
+		f.use("org.springframework.data.relational.core.mapping.Column"), javaString(col.ColumnName)))
+
+	// A foreign key to another aggregate's single column key is held as a
+	// reference to that aggregate.
+	if fk := t.ForeignKeyFor(col.ColumnName); fk != nil && !key {
+		target := w.schema.Table(fk.TargetTable)
+		if target != nil && target.PrimaryKey != nil && slices.Equal(target.PrimaryKey.Columns, fk.TargetColumns) {
+			field.typ = fmt.Sprintf("%s<%s, %s>", f.use("org.springframework.data.jdbc.core.mapping.AggregateReference"),
+				w.classes[target.Name], f.use(w.javaType(*target.Column(fk.TargetColumns[0]))))
+		}
+	}
+	return field
+}
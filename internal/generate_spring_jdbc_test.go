@@ -0,0 +1,9 @@
+package internal
+
+import "testing"
+
+func TestGenerateSpringJDBC(t *testing.T) {
+	generateGolden(t, generateSpringJDBC, "spring-jdbc",
+		"PostStatus.java", "User.java", "UserRepository.java", "Post.java", "PostRepository.java",
+		"Tag.java", "TagRepository.java", "PostTagId.java", "PostTag.java", "PostTagRepository.java")
+}
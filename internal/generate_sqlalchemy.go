@@ -0,0 +1,395 @@
+package internal
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// sqlalchemyReserved are the attribute names declarative classes keep for
+// themselves; columns with these names are mapped under another attribute.
+var sqlalchemyReserved = map[string]bool{"metadata": true, "registry": true}
+
+type sqlalchemyWriter struct {
+	schema *Schema
+	// attrs maps each table's columns to their attribute names.
+	attrs map[string]map[string]string
+	// enumClasses maps enum types to their enum.Enum class, enumTypes to the
+	// module level ENUM type shared by the columns using it.
+	enumClasses map[string]string
+	enumTypes   map[string]string
+	// The names imported from each module.
+	imports map[string]map[string]bool
+}
+
+func (w *sqlalchemyWriter) use(module string, names ...string) {
+	if w.imports[module] == nil {
+		w.imports[module] = make(map[string]bool)
+	}
+	for _, name := range names {
+		w.imports[module][name] = true
+	}
+}
+
+// generateSQLAlchemy renders the schema as SQLAlchemy 2.0 declarative models:
+// Mapped[] annotated columns, relationship() pairs with back_populates, an
+// enum.Enum class per enum type and the constraints and indexes in
+// __table_args__.
+func generateSQLAlchemy(schema *Schema, opts GenerateOptions) ([]File, error) {
+	w := &sqlalchemyWriter{
+		schema:      schema,
+		attrs:       make(map[string]map[string]string),
+		enumClasses: make(map[string]string),
+		enumTypes:   make(map[string]string),
+		imports:     make(map[string]map[string]bool),
+	}
+	w.use("sqlalchemy.orm", "DeclarativeBase", "Mapped", "mapped_column")
+
+	classes := make(map[string]bool)
+	for _, t := range schema.Tables {
+		classes[className(t.Name)] = true
+		taken := make(map[string]bool)
+		w.attrs[t.Name] = make(map[string]string)
+		for _, col := range t.Columns {
+			name := pythonIdent(col.ColumnName)
+			for taken[name] || sqlalchemyReserved[name] {
+				name += "_"
+			}
+			taken[name] = true
+			w.attrs[t.Name][col.ColumnName] = name
+		}
+	}
+	base := "Base"
+	if classes[base] {
+		base = "ModelBase"
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "\n\nclass %s(DeclarativeBase):\n    pass\n", base)
+	for _, e := range schema.Enums {
+		w.use("enum")
+		w.use("sqlalchemy.dialects.postgresql", "ENUM")
+		class := pascalCase(e.Name)
+		if classes[class] || class == base {
+			class += "Enum"
+		}
+		w.enumClasses[e.Name] = class
+		w.enumTypes[e.Name] = pythonIdent(e.Name) + "_enum"
+		fmt.Fprintf(&body, "\n\nclass %s(enum.Enum):\n", class)
+		for _, v := range e.Values {
+			fmt.Fprintf(&body, "    %s = %s\n", pythonConstant(v), pythonString(v))
+		}
+		// values_callable stores the values rather than the member names.
+		fmt.Fprintf(&body, "\n\n%s = ENUM(%s, name=%s, values_callable=lambda e: [m.value for m in e])\n",
+			w.enumTypes[e.Name], class, pythonString(e.Name))
+	}
+	for i := range schema.Tables {
+		body.WriteString("\n\n")
+		body.WriteString(w.model(&schema.Tables[i], base))
+	}
+
+	var b strings.Builder
+	b.WriteString("# Generated by schema transform. Do not edit.\n")
+	var std []string
+	for _, module := range []string{"datetime", "decimal", "enum", "uuid"} {
+		if w.imports[module] != nil {
+			std = append(std, "import "+module+"\n")
+		}
+	}
+	if w.imports["typing"] != nil {
+		std = append(std, "from typing import "+importList(w.imports["typing"])+"\n")
+	}
+	if len(std) > 0 {
+		b.WriteString(strings.Join(std, "") + "\n")
+	}
+	for _, module := range []string{"sqlalchemy", "sqlalchemy.dialects.postgresql", "sqlalchemy.orm"} {
+		if w.imports[module] != nil {
+			fmt.Fprintf(&b, "from %s import %s\n", module, importList(w.imports[module]))
+		}
+	}
+	b.WriteString(body.String())
+	return []File{{Name: "models.py", Content: []byte(b.String())}}, nil
+}
+
+func importList(names map[string]bool) string {
+	var list []string
+	for name := range names {
+		list = append(list, name)
+	}
+	slices.Sort(list)
+	return strings.Join(list, ", ")
+}
+
+func (w *sqlalchemyWriter) model(t *Table, base string) string {
+	class := className(t.Name)
+	relations := w.schema.Relations(t.Name)
+
+	// Single column foreign keys go on the column, composite ones into
+	// __table_args__.
+	columnFKs := make(map[string]ForeignKey)
+	var args []string
+	for _, fk := range t.ForeignKeys {
+		if len(fk.SourceColumns) == 1 {
+			columnFKs[fk.SourceColumns[0]] = fk
+			continue
+		}
+		var targets []string
+		for _, c := range fk.TargetColumns {
+			targets = append(targets, pythonString(fk.TargetTable+"."+c))
+		}
+		w.use("sqlalchemy", "ForeignKeyConstraint")
+		args = append(args, fmt.Sprintf("ForeignKeyConstraint([%s], [%s]%s)",
+			strings.Join(pythonStrings(fk.SourceColumns), ", "), strings.Join(targets, ", "), foreignKeyOptions(fk)))
+	}
+	for _, u := range t.Uniques {
+		w.use("sqlalchemy", "UniqueConstraint")
+		args = append(args, fmt.Sprintf("UniqueConstraint(%s, name=%s)", strings.Join(pythonStrings(u.Columns), ", "), pythonString(u.ConstraintName)))
+	}
+	for _, c := range t.Checks {
+		w.use("sqlalchemy", "CheckConstraint")
+		args = append(args, fmt.Sprintf("CheckConstraint(%s, name=%s)", pythonString(c.Expression), pythonString(c.ConstraintName)))
+	}
+	for _, idx := range t.Indexes {
+		w.use("sqlalchemy", "Index")
+		parts := []string{pythonString(idx.Name)}
+		for _, c := range idx.Columns {
+			if t.Column(c) != nil {
+				parts = append(parts, pythonString(c))
+			} else {
+				w.use("sqlalchemy", "text")
+				parts = append(parts, "text("+pythonString(c)+")")
+			}
+		}
+		if idx.Unique {
+			parts = append(parts, "unique=True")
+		}
+		if idx.Method != "" && idx.Method != "btree" {
+			parts = append(parts, "postgresql_using="+pythonString(idx.Method))
+		}
+		if idx.Where != "" {
+			w.use("sqlalchemy", "text")
+			parts = append(parts, "postgresql_where=text("+pythonString(idx.Where)+")")
+		}
+		args = append(args, "Index("+strings.Join(parts, ", ")+")")
+	}
+	if t.Comment != "" {
+		args = append(args, "{'comment': "+pythonString(t.Comment)+"}")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "class %s(%s):\n", class, base)
+	fmt.Fprintf(&b, "    __tablename__ = %s\n", pythonString(t.Name))
+	if len(args) > 0 {
+		b.WriteString("    __table_args__ = (\n")
+		for _, arg := range args {
+			fmt.Fprintf(&b, "        %s,\n", arg)
+		}
+		b.WriteString("    )\n")
+	}
+	b.WriteString("\n")
+	for _, col := range t.Columns {
+		b.WriteString(w.column(t, col, columnFKs))
+	}
+	if t.PrimaryKey == nil {
+		// The mapper needs a primary key; without one rows are told apart
+		// by all their columns.
+		var columns []string
+		for _, col := range t.Columns {
+			columns = append(columns, w.attrs[t.Name][col.ColumnName])
+		}
+		fmt.Fprintf(&b, "\n    __mapper_args__ = {'primary_key': [%s]}\n", strings.Join(columns, ", "))
+	}
+	if len(relations) > 0 {
+		b.WriteString("\n")
+	}
+	for _, rel := range relations {
+		b.WriteString(w.relationship(t, rel))
+	}
+	return b.String()
+}
+
+// column renders a mapped_column. Nullability follows the Optional[] in the
+// annotation.
+func (w *sqlalchemyWriter) column(t *Table, col Column, fks map[string]ForeignKey) string {
+	name := w.attrs[t.Name][col.ColumnName]
+	sqlType, pyType := w.columnType(col)
+	var args []string
+	if name != col.ColumnName {
+		args = append(args, pythonString(col.ColumnName))
+	}
+	args = append(args, sqlType)
+	if col.IsIdentity {
+		w.use("sqlalchemy", "Identity")
+		if col.IdentityGeneration == "ALWAYS" {
+			args = append(args, "Identity(always=True)")
+		} else {
+			args = append(args, "Identity()")
+		}
+	}
+	if fk, ok := fks[col.ColumnName]; ok {
+		w.use("sqlalchemy", "ForeignKey")
+		args = append(args, fmt.Sprintf("ForeignKey(%s%s)", pythonString(fk.TargetTable+"."+fk.TargetColumns[0]), foreignKeyOptions(fk)))
+	}
+	if t.IsPrimaryKey(col.ColumnName) {
+		args = append(args, "primary_key=True")
+	}
+	if col.Default != "" && !col.IsSerial() {
+		w.use("sqlalchemy", "text")
+		args = append(args, "server_default=text("+pythonString(col.Default)+")")
+	}
+	if col.Comment != "" {
+		args = append(args, "comment="+pythonString(col.Comment))
+	}
+	if col.Nullable() {
+		w.use("typing", "Optional")
+		pyType = "Optional[" + pyType + "]"
+	}
+	return fmt.Sprintf("    %s: Mapped[%s] = mapped_column(%s)\n", name, pyType, strings.Join(args, ", "))
+}
+
+// relationship renders one side of a foreign key. foreign_keys is only
+// spelled out for ambiguous foreign keys, and remote_side when a table
+// references itself.
+func (w *sqlalchemyWriter) relationship(t *Table, rel Relation) string {
+	w.use("sqlalchemy.orm", "relationship")
+	fk := rel.ForeignKey
+	source := t
+	if !rel.Owner {
+		source = w.schema.Table(rel.Other)
+	}
+	otherClass := className(rel.Other)
+	var args []string
+	for _, other := range w.schema.Relations(rel.Other) {
+		if other.Other == t.Name && other.Owner != rel.Owner && other.ForeignKey.ConstraintName == fk.ConstraintName {
+			args = append(args, "back_populates="+pythonString(pythonIdent(other.Name)))
+		}
+	}
+
+	sourceClass := className(source.Name)
+	if w.schema.isAmbiguous(fk) {
+		args = append(args, "foreign_keys="+pythonString(w.attributeList(sourceClass, source.Name, fk.SourceColumns)))
+	}
+	if rel.Owner && source.Name == fk.TargetTable {
+		args = append(args, "remote_side="+pythonString(w.attributeList(sourceClass, source.Name, fk.TargetColumns)))
+	}
+
+	var annotation string
+	switch {
+	case rel.Many:
+		w.use("typing", "List")
+		annotation = "List[" + pythonString(otherClass) + "]"
+	case rel.Owner && !anyNullable(source, fk.SourceColumns):
+		annotation = pythonString(otherClass)
+	default:
+		w.use("typing", "Optional")
+		annotation = "Optional[" + pythonString(otherClass) + "]"
+	}
+	return fmt.Sprintf("    %s: Mapped[%s] = relationship(%s)\n", pythonIdent(rel.Name), annotation, strings.Join(args, ", "))
+}
+
+// attributeList renders columns as a list of class attributes, evaluated by
+// SQLAlchemy once all classes exist.
+func (w *sqlalchemyWriter) attributeList(class, table string, columns []string) string {
+	attrs := make([]string, len(columns))
+	for i, c := range columns {
+		attrs[i] = class + "." + w.attrs[table][c]
+	}
+	return "[" + strings.Join(attrs, ", ") + "]"
+}
+
+// columnType maps a column to its Postgres SQLAlchemy type and the Python
+// type of its values.
+func (w *sqlalchemyWriter) columnType(col Column) (string, string) {
+	pg := func(sqlType, pyType string, modules ...string) (string, string) {
+		w.use("sqlalchemy.dialects.postgresql", strings.SplitN(sqlType, "(", 2)[0])
+		for _, module := range modules {
+			w.use(module)
+		}
+		return sqlType, pyType
+	}
+	if col.IsArray() {
+		sqlType, pyType := w.columnType(elementColumn(col))
+		w.use("typing", "List")
+		return pg("ARRAY("+sqlType+")", "List["+pyType+"]")
+	}
+	if enum, ok := w.enumClasses[col.UDTName]; ok {
+		return w.enumTypes[col.UDTName], enum
+	}
+	switch col.UDTName {
+	case "int2":
+		return pg("SMALLINT", "int")
+	case "int4":
+		return pg("INTEGER", "int")
+	case "int8":
+		return pg("BIGINT", "int")
+	case "float4":
+		return pg("REAL", "float")
+	case "float8":
+		return pg("DOUBLE_PRECISION", "float")
+	case "numeric":
+		if precision, scale, ok := columnModifiers(col); ok {
+			return pg(fmt.Sprintf("NUMERIC(%d, %d)", precision, scale), "decimal.Decimal", "decimal")
+		}
+		return pg("NUMERIC", "decimal.Decimal", "decimal")
+	case "bool":
+		return pg("BOOLEAN", "bool")
+	case "varchar", "bpchar":
+		sqlType := map[string]string{"varchar": "VARCHAR", "bpchar": "CHAR"}[col.UDTName]
+		if length, _, ok := columnModifiers(col); ok {
+			return pg(fmt.Sprintf("%s(%d)", sqlType, length), "str")
+		}
+		return pg(sqlType, "str")
+	case "uuid":
+		return pg("UUID", "uuid.UUID", "uuid")
+	case "json", "jsonb":
+		w.use("typing", "Any")
+		return pg(strings.ToUpper(col.UDTName), "Any")
+	case "date":
+		return pg("DATE", "datetime.date", "datetime")
+	case "timestamp":
+		return pg("TIMESTAMP", "datetime.datetime", "datetime")
+	case "timestamptz":
+		return pg("TIMESTAMP(timezone=True)", "datetime.datetime", "datetime")
+	case "time":
+		return pg("TIME", "datetime.time", "datetime")
+	case "timetz":
+		return pg("TIME(timezone=True)", "datetime.time", "datetime")
+	case "interval":
+		return pg("INTERVAL", "datetime.timedelta", "datetime")
+	case "bytea":
+		return pg("BYTEA", "bytes")
+	case "inet", "cidr", "macaddr", "tsvector":
+		return pg(strings.ToUpper(col.UDTName), "str")
+	}
+	return pg("TEXT", "str")
+}
+
+// foreignKeyOptions renders the referential actions of a foreign key as
+// keyword arguments.
+func foreignKeyOptions(fk ForeignKey) string {
+	var options string
+	if fk.OnDelete != "" && fk.OnDelete != "NO ACTION" {
+		options += ", ondelete=" + pythonString(fk.OnDelete)
+	}
+	if fk.OnUpdate != "" && fk.OnUpdate != "NO ACTION" {
+		options += ", onupdate=" + pythonString(fk.OnUpdate)
+	}
+	return options + ", name=" + pythonString(fk.ConstraintName)
+}
+
+func pythonStrings(values []string) []string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = pythonString(v)
+	}
+	return quoted
+}
+
+func anyNullable(t *Table, columns []string) bool {
+	for _, c := range columns {
+		if col := t.Column(c); col == nil || col.Nullable() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,16 @@
+package internal
+
+import "testing"
+
+func TestGenerateSQLAlchemy(t *testing.T) {
+	files, err := generateSQLAlchemy(blogSchema(), GenerateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Name != "models.py" {
+		t.Fatalf("got %d files, want models.py", len(files))
+	}
+	golden(t, "sqlalchemy/models.py", files[0].Content)
+	// Configuring the mappers resolves the relationships and their keys.
+	compilePython(t, files, "import models; from sqlalchemy.orm import configure_mappers; configure_mappers()", "sqlalchemy")
+}
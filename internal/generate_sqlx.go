@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// sqlxTypes maps udt names to the Rust types SQLx decodes them into where
+// they differ from Diesel's.
+var sqlxTypes = map[string]string{
+	"interval": "sqlx::postgres::types::PgInterval",
+	"money":    "sqlx::postgres::types::PgMoney",
+	"macaddr":  "mac_address::MacAddress",
+}
+
+// generateSQLx renders the schema as plain Rust structs deriving
+// sqlx::FromRow, one per table, and a Rust enum deriving sqlx::Type per
+// Postgres enum.
+func generateSQLx(schema *Schema, opts GenerateOptions) ([]File, error) {
+	taken := make(map[string]bool)
+	for _, t := range schema.Tables {
+		taken[className(t.Name)] = true
+	}
+	enums := make(map[string]string)
+	var b strings.Builder
+	b.WriteString("// Generated by schema transform. Do not edit.\n")
+	for _, e := range schema.Enums {
+		name := className(e.Name)
+		for taken[name] {
+			name += "Enum"
+		}
+		enums[e.Name] = name
+		b.WriteString("\n#[derive(Debug, Clone, Copy, PartialEq, Eq, sqlx::Type)]\n")
+		fmt.Fprintf(&b, "#[sqlx(type_name = %q)]\n", e.Name)
+		fmt.Fprintf(&b, "pub enum %s {\n", name)
+		for _, v := range e.Values {
+			variant := pascalCase(v)
+			if variant == "" || variant[0] >= '0' && variant[0] <= '9' {
+				variant = "V" + variant
+			}
+			fmt.Fprintf(&b, "    #[sqlx(rename = %q)]\n    %s,\n", v, variant)
+		}
+		b.WriteString("}\n")
+	}
+
+	for _, t := range schema.Tables {
+		b.WriteString("\n")
+		if t.Comment != "" {
+			fmt.Fprintf(&b, "/// %s\n", oneLine(t.Comment))
+		}
+		b.WriteString("#[derive(Debug, Clone, PartialEq, sqlx::FromRow)]\n")
+		fmt.Fprintf(&b, "pub struct %s {\n", className(t.Name))
+		fields := make(map[string]bool)
+		for _, col := range t.Columns {
+			udt := col.ElementType()
+			typ, ok := sqlxTypes[udt]
+			if !ok {
+				types, known := dieselTypes[udt]
+				typ, ok = types[1], known
+			}
+			if enum, isEnum := enums[udt]; isEnum {
+				typ, ok = enum, true
+			}
+			if !ok {
+				// FromRow only reads the fields a struct has.
+				fmt.Fprintf(&b, "    // %s %s has no Rust type and is left out.\n", col.ColumnName, col.FullType)
+				continue
+			}
+			if col.IsArray() {
+				typ = "Vec<" + typ + ">"
+			}
+			if col.Nullable() {
+				typ = "Option<" + typ + ">"
+			}
+			name := rustIdent(col.ColumnName)
+			for fields[name] {
+				name += "_"
+			}
+			fields[name] = true
+			if col.Comment != "" {
+				fmt.Fprintf(&b, "    /// %s\n", oneLine(col.Comment))
+			}
+			if name != col.ColumnName {
+				fmt.Fprintf(&b, "    #[sqlx(rename = %q)]\n", col.ColumnName)
+			}
+			fmt.Fprintf(&b, "    pub %s: %s,\n", name, typ)
+		}
+		b.WriteString("}\n")
+	}
+	return []File{{Name: "models.rs", Content: []byte(b.String())}}, nil
+}
@@ -0,0 +1,7 @@
+package internal
+
+import "testing"
+
+func TestGenerateSQLx(t *testing.T) {
+	generateGolden(t, generateSQLx, "sqlx", "models.rs")
+}
@@ -0,0 +1,81 @@
+package internal
+
+import (
+	"path"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// containsLine reports whether s has a line equal to line, which ends in a
+// newline.
+func containsLine(s, line string) bool {
+	return slices.Contains(strings.SplitAfter(s, "\n"), line)
+}
+
+// goldenFiles compares every generated file with testdata/<dir>/<name>.
+func goldenFiles(t *testing.T, dir string, files []File) {
+	t.Helper()
+	for _, f := range files {
+		golden(t, path.Join(dir, f.Name), f.Content)
+	}
+}
+
+// generateGolden runs a generator on the blog schema, checks that it
+// writes the named files and compares them with testdata/<dir>.
+func generateGolden(t *testing.T, generate Generator, dir string, names ...string) []File {
+	t.Helper()
+	files, err := generate(blogSchema(), GenerateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fileNames(files); !slices.Equal(got, names) {
+		t.Fatalf("files = %q, want %q", got, names)
+	}
+	goldenFiles(t, dir, files)
+	return files
+}
+
+func TestRelations(t *testing.T) {
+	tests := []struct {
+		schema *Schema
+		table  string
+		want   []string
+	}{
+		{blogSchema(), "users", []string{"author_posts many posts", "editor_posts many posts"}},
+		{blogSchema(), "posts", []string{"author owner users", "editor owner users", "post_tags many post_tags"}},
+		{blogSchema(), "post_tags", []string{"post owner posts", "tag owner tags"}},
+		{
+			// A unique foreign key makes the inverse side one-to-one.
+			schema: blogSchemaWith(
+				addColumn("users", column("pinned_post_id", "bigint", true)),
+				alterTable("users", func(t *Table) {
+					t.ForeignKeys = append(t.ForeignKeys, ForeignKey{ConstraintName: "users_pinned_post_id_fkey", SourceTable: "users",
+						SourceColumns: []string{"pinned_post_id"}, TargetTable: "posts", TargetColumns: []string{"id"}})
+					t.Uniques = append(t.Uniques, UniqueConstraint{ConstraintName: "users_pinned_post_id_key", Columns: []string{"pinned_post_id"}})
+				}),
+			),
+			table: "posts",
+			want:  []string{"author owner users", "editor owner users", "post_tags many post_tags", "user one users"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.table, func(t *testing.T) {
+			var got []string
+			for _, r := range tt.schema.Relations(tt.table) {
+				kind := "one"
+				switch {
+				case r.Owner:
+					kind = "owner"
+				case r.Many:
+					kind = "many"
+				}
+				got = append(got, r.Name+" "+kind+" "+r.Other)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("relations = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
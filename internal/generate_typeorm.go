@@ -0,0 +1,393 @@
+package internal
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// typeormTypes maps udt names to the column types TypeORM knows for
+// Postgres.
+var typeormTypes = map[string]string{
+	"int2": "smallint", "int4": "integer", "int8": "bigint",
+	"float4": "real", "float8": "double precision", "numeric": "numeric",
+	"bool": "boolean", "varchar": "character varying", "bpchar": "character",
+	"text": "text", "uuid": "uuid", "json": "json", "jsonb": "jsonb",
+	"date": "date", "timestamp": "timestamp without time zone",
+	"timestamptz": "timestamp with time zone", "time": "time without time zone",
+	"timetz": "time with time zone", "interval": "interval", "bytea": "bytea",
+	"inet": "inet", "cidr": "cidr", "macaddr": "macaddr", "tsvector": "tsvector",
+	"citext": "citext", "money": "money", "xml": "xml",
+}
+
+// tsType returns the TypeScript type the Postgres driver reads a column
+// type as. bigint and numeric come back as strings so no precision is lost.
+func tsType(udt string) string {
+	switch udt {
+	case "int2", "int4", "float4", "float8":
+		return "number"
+	case "bool":
+		return "boolean"
+	case "timestamp", "timestamptz":
+		return "Date"
+	case "json", "jsonb":
+		return "unknown"
+	case "bytea":
+		return "Buffer"
+	}
+	return "string"
+}
+
+// tsProperty turns a database name into a camelCase property name.
+func tsProperty(name string) string {
+	property := camelCase(name)
+	if property == "" || property[0] >= '0' && property[0] <= '9' {
+		property = "_" + property
+	}
+	return property
+}
+
+type typeormWriter struct {
+	schema *Schema
+	// properties maps each table's columns to their property names.
+	properties map[string]map[string]string
+	enums      map[string]string
+}
+
+// generateTypeORM renders the schema as TypeORM entities, one file per
+// table, plus enums.ts with a TypeScript enum per Postgres enum and an
+// index.ts re-exporting everything.
+func generateTypeORM(schema *Schema, opts GenerateOptions) ([]File, error) {
+	w := &typeormWriter{
+		schema:     schema,
+		properties: make(map[string]map[string]string),
+		enums:      make(map[string]string),
+	}
+	var files []File
+	var exports []string
+
+	classes := make(map[string]bool)
+	for _, t := range schema.Tables {
+		classes[className(t.Name)] = true
+	}
+	if len(schema.Enums) > 0 {
+		var b strings.Builder
+		b.WriteString("// Generated by schema transform. Do not edit.\n")
+		for _, e := range schema.Enums {
+			w.enums[e.Name] = pascalCase(e.Name)
+			if classes[w.enums[e.Name]] {
+				w.enums[e.Name] += "Enum"
+			}
+			fmt.Fprintf(&b, "\nexport enum %s {\n", w.enums[e.Name])
+			for _, v := range e.Values {
+				member := pascalCase(v)
+				if member == "" || member[0] >= '0' && member[0] <= '9' {
+					member = "V" + member
+				}
+				fmt.Fprintf(&b, "  %s = %s,\n", member, strconv.Quote(v))
+			}
+			b.WriteString("}\n")
+		}
+		files = append(files, File{Name: "enums.ts", Content: []byte(b.String())})
+		exports = append(exports, "enums")
+	}
+
+	for _, t := range schema.Tables {
+		taken := make(map[string]bool)
+		w.properties[t.Name] = make(map[string]string)
+		for _, col := range t.Columns {
+			name := tsProperty(col.ColumnName)
+			for taken[name] {
+				name += "_"
+			}
+			taken[name] = true
+			w.properties[t.Name][col.ColumnName] = name
+		}
+	}
+	for i := range schema.Tables {
+		t := &schema.Tables[i]
+		class := className(t.Name)
+		files = append(files, File{Name: class + ".ts", Content: []byte(w.entity(t))})
+		exports = append(exports, class)
+	}
+
+	var index strings.Builder
+	index.WriteString("// Generated by schema transform. Do not edit.\n")
+	for _, name := range exports {
+		fmt.Fprintf(&index, "export * from %s;\n", strconv.Quote("./"+name))
+	}
+	files = append(files, File{Name: "index.ts", Content: []byte(index.String())})
+	return files, nil
+}
+
+func (w *typeormWriter) entity(t *Table) string {
+	properties := w.properties[t.Name]
+	class := className(t.Name)
+	decorators := map[string]bool{"Entity": true}
+	imports := make(map[string]bool)
+	var enums []string
+
+	propertyList := func(columns []string) (string, bool) {
+		var names []string
+		for _, c := range columns {
+			name, ok := properties[c]
+			if !ok {
+				return "", false
+			}
+			names = append(names, strconv.Quote(name))
+		}
+		return "[" + strings.Join(names, ", ") + "]", true
+	}
+
+	entityOptions := []string{"name: " + strconv.Quote(t.Name)}
+	if t.Comment != "" {
+		entityOptions = append(entityOptions, "comment: "+strconv.Quote(t.Comment))
+	}
+	classDecorators := []string{"@Entity({ " + strings.Join(entityOptions, ", ") + " })"}
+	for _, u := range t.Uniques {
+		if list, ok := propertyList(u.Columns); ok {
+			decorators["Unique"] = true
+			classDecorators = append(classDecorators, fmt.Sprintf("@Unique(%s, %s)", strconv.Quote(u.ConstraintName), list))
+		}
+	}
+	for _, idx := range t.Indexes {
+		list, ok := propertyList(idx.Columns)
+		switch {
+		case !ok:
+			classDecorators = append(classDecorators, "// Expression index: "+oneLine(idx.Definition))
+			continue
+		case idx.Method != "" && idx.Method != "btree":
+			classDecorators = append(classDecorators, "// Index using "+idx.Method+": "+oneLine(idx.Definition))
+			continue
+		}
+		var options []string
+		if idx.Unique {
+			options = append(options, "unique: true")
+		}
+		if idx.Where != "" {
+			options = append(options, "where: "+strconv.Quote(idx.Where))
+		}
+		decorators["Index"] = true
+		decorator := fmt.Sprintf("@Index(%s, %s", strconv.Quote(idx.Name), list)
+		if len(options) > 0 {
+			decorator += ", { " + strings.Join(options, ", ") + " }"
+		}
+		classDecorators = append(classDecorators, decorator+")")
+	}
+	for _, c := range t.Checks {
+		decorators["Check"] = true
+		classDecorators = append(classDecorators, fmt.Sprintf("@Check(%s, %s)", strconv.Quote(c.ConstraintName), strconv.Quote(c.Expression)))
+	}
+
+	var members []string
+	for _, col := range t.Columns {
+		decorator, typ := w.column(t, col, decorators)
+		if enum, ok := w.enums[col.ElementType()]; ok && !slices.Contains(enums, enum) {
+			enums = append(enums, enum)
+		}
+		if col.Nullable() {
+			typ += " | null"
+		}
+		members = append(members, fmt.Sprintf("  %s\n  %s!: %s;\n", decorator, properties[col.ColumnName], typ))
+	}
+	relations := w.schema.Relations(t.Name)
+	for _, rel := range relations {
+		members = append(members, w.relation(t, rel, decorators))
+		if rel.Other != t.Name {
+			imports[className(rel.Other)] = true
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("// Generated by schema transform. Do not edit.\n")
+	var names []string
+	for name := range decorators {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	fmt.Fprintf(&b, "import { %s } from \"typeorm\";\n", strings.Join(names, ", "))
+	if len(relations) > 0 {
+		b.WriteString("import type { Relation } from \"typeorm\";\n")
+	}
+	var others []string
+	for other := range imports {
+		others = append(others, other)
+	}
+	slices.Sort(others)
+	for _, other := range others {
+		fmt.Fprintf(&b, "import { %s } from %s;\n", other, strconv.Quote("./"+other))
+	}
+	if len(enums) > 0 {
+		slices.Sort(enums)
+		fmt.Fprintf(&b, "import { %s } from \"./enums\";\n", strings.Join(enums, ", "))
+	}
+	b.WriteString("\n")
+	for _, decorator := range classDecorators {
+		b.WriteString(decorator + "\n")
+	}
+	fmt.Fprintf(&b, "export class %s {\n", class)
+	b.WriteString(strings.Join(members, "\n"))
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// column returns the decorator and TypeScript type of a column.
+func (w *typeormWriter) column(t *Table, col Column, decorators map[string]bool) (string, string) {
+	udt := col.ElementType()
+	options := []string{"name: " + strconv.Quote(col.ColumnName)}
+	typ := tsType(udt)
+	if enum, ok := w.enums[udt]; ok {
+		typ = enum
+		options = append(options, `type: "enum"`, "enum: "+enum, "enumName: "+strconv.Quote(udt))
+	} else {
+		dbType, ok := typeormTypes[udt]
+		if !ok {
+			dbType = udt
+		}
+		options = append(options, "type: "+strconv.Quote(dbType))
+		length, scale, ok := columnModifiers(col)
+		switch {
+		case !ok:
+		case udt == "varchar" || udt == "bpchar":
+			options = append(options, fmt.Sprintf("length: %d", length))
+		case udt == "numeric":
+			options = append(options, fmt.Sprintf("precision: %d", length), fmt.Sprintf("scale: %d", scale))
+		}
+	}
+	if col.IsArray() {
+		typ += "[]"
+		options = append(options, "array: true")
+	}
+
+	decorator := "Column"
+	primary := t.IsPrimaryKey(col.ColumnName)
+	kind, value := parseDefault(col.Default)
+	switch {
+	case primary && col.IsIdentity:
+		decorator = "PrimaryGeneratedColumn"
+		options = append([]string{`"identity"`}, options...)
+		options = append(options, "generatedIdentity: "+strconv.Quote(identityGeneration(col)))
+	case primary && col.IsSerial():
+		decorator = "PrimaryGeneratedColumn"
+	case primary && kind == UUIDDefault:
+		decorator = "PrimaryGeneratedColumn"
+		options = append([]string{`"uuid"`}, options...)
+	case primary:
+		decorator = "PrimaryColumn"
+	case col.IsIdentity:
+		options = append(options, `generated: "identity"`, "generatedIdentity: "+strconv.Quote(identityGeneration(col)))
+	case col.IsSerial():
+		options = append(options, `generated: "increment"`)
+	case kind == UUIDDefault:
+		options = append(options, `generated: "uuid"`)
+	}
+	decorators[decorator] = true
+
+	if col.Nullable() {
+		options = append(options, "nullable: true")
+	}
+	if decorator == "Column" && !col.IsSerial() && kind != UUIDDefault {
+		switch kind {
+		case StringDefault:
+			options = append(options, "default: "+strconv.Quote(value))
+		case NumberDefault, BoolDefault:
+			options = append(options, "default: "+value)
+		case NowDefault, ExpressionDefault:
+			options = append(options, "default: () => "+strconv.Quote(col.Default))
+		}
+	}
+	if col.Comment != "" {
+		options = append(options, "comment: "+strconv.Quote(col.Comment))
+	}
+
+	args := "{ " + strings.Join(options, ", ") + " }"
+	if strings.HasPrefix(options[0], `"`) {
+		args = options[0] + ", { " + strings.Join(options[1:], ", ") + " }"
+	}
+	return "@" + decorator + "(" + args + ")", typ
+}
+
+func identityGeneration(col Column) string {
+	if col.IdentityGeneration == "ALWAYS" {
+		return "ALWAYS"
+	}
+	return "BY DEFAULT"
+}
+
+// relation renders a relation property. The owning side carries the
+// JoinColumn; Relation<> keeps the circular imports between entities from
+// breaking under ES modules.
+func (w *typeormWriter) relation(t *Table, rel Relation, decorators map[string]bool) string {
+	fk := rel.ForeignKey
+	other := className(rel.Other)
+	var inverse string
+	for _, r := range w.schema.Relations(rel.Other) {
+		if r.Other == t.Name && r.Owner != rel.Owner && r.ForeignKey.ConstraintName == fk.ConstraintName {
+			inverse = tsProperty(r.Name)
+		}
+	}
+
+	source := t
+	if !rel.Owner {
+		source = w.schema.Table(rel.Other)
+	}
+	oneToOne := isUniqueColumns(source, fk.SourceColumns)
+	var decorator string
+	switch {
+	case oneToOne:
+		decorator = "OneToOne"
+	case rel.Owner:
+		decorator = "ManyToOne"
+	default:
+		decorator = "OneToMany"
+	}
+	decorators[decorator] = true
+
+	args := []string{"() => " + other}
+	if inverse != "" {
+		args = append(args, fmt.Sprintf("(entity) => entity.%s", inverse))
+	}
+	var options []string
+	if rel.Owner {
+		if !anyNullable(t, fk.SourceColumns) {
+			options = append(options, "nullable: false")
+		}
+		if fk.OnDelete != "" && fk.OnDelete != "NO ACTION" {
+			options = append(options, "onDelete: "+strconv.Quote(fk.OnDelete))
+		}
+		if fk.OnUpdate != "" && fk.OnUpdate != "NO ACTION" {
+			options = append(options, "onUpdate: "+strconv.Quote(fk.OnUpdate))
+		}
+	}
+	if len(options) > 0 {
+		args = append(args, "{ "+strings.Join(options, ", ")+" }")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "  @%s(%s)\n", decorator, strings.Join(args, ", "))
+	typ := "Relation<" + other + ">"
+	switch {
+	case rel.Owner:
+		decorators["JoinColumn"] = true
+		var joins []string
+		for i, c := range fk.SourceColumns {
+			joins = append(joins, fmt.Sprintf("{ name: %s, referencedColumnName: %s, foreignKeyConstraintName: %s }",
+				strconv.Quote(c), strconv.Quote(w.properties[rel.Other][fk.TargetColumns[i]]), strconv.Quote(fk.ConstraintName)))
+		}
+		if len(joins) == 1 {
+			fmt.Fprintf(&b, "  @JoinColumn(%s)\n", joins[0])
+		} else {
+			fmt.Fprintf(&b, "  @JoinColumn([%s])\n", strings.Join(joins, ", "))
+		}
+		if anyNullable(t, fk.SourceColumns) {
+			typ += " | null"
+		}
+	case rel.Many:
+		typ = "Relation<" + other + ">[]"
+	default:
+		typ += " | null"
+	}
+	fmt.Fprintf(&b, "  %s!: %s;\n", tsProperty(rel.Name), typ)
+	return b.String()
+}
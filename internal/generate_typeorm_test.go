@@ -0,0 +1,18 @@
+package internal
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGenerateTypeORM(t *testing.T) {
+	files, err := generateTypeORM(blogSchema(), GenerateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"enums.ts", "User.ts", "Post.ts", "Tag.ts", "PostTag.ts", "index.ts"}
+	if got := fileNames(files); !slices.Equal(got, want) {
+		t.Fatalf("files = %q, want %q", got, want)
+	}
+	goldenFiles(t, "typeorm", files)
+}
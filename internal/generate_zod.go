@@ -0,0 +1,225 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type zodWriter struct {
+	schema *Schema
+	enums  map[string]string
+}
+
+// generateZod renders the schema as Zod schemas to validate API payloads
+// with: a z.enum per enum type and per table a schema of the rows, a
+// Create schema without the columns the database generates and an Update
+// schema whose fields are all optional, each with its inferred type. Keys
+// are the column names, and values are typed as the Postgres driver reads
+// them, bigint and numeric as strings. Fields carry the lengths and
+// precision of their types and the parts of the CHECK constraints Zod can
+// express.
+func generateZod(schema *Schema, opts GenerateOptions) ([]File, error) {
+	w := &zodWriter{schema: schema, enums: make(map[string]string)}
+	var b strings.Builder
+	b.WriteString("// Generated by schema transform. Do not edit.\nimport { z } from \"zod\";\n")
+
+	taken := make(map[string]bool)
+	for _, t := range schema.Tables {
+		for _, suffix := range []string{"", "Create", "Update"} {
+			taken[className(t.Name)+suffix] = true
+		}
+	}
+	for _, e := range schema.Enums {
+		name := pascalCase(e.Name)
+		for taken[name] {
+			name += "Enum"
+		}
+		taken[name] = true
+		w.enums[e.Name] = name
+		fmt.Fprintf(&b, "\nexport const %sSchema = z.enum([%s]);\n", name, strings.Join(tsStrings(e.Values), ", "))
+		fmt.Fprintf(&b, "export type %s = z.infer<typeof %sSchema>;\n", name, name)
+	}
+	for i := range schema.Tables {
+		t := &schema.Tables[i]
+		b.WriteString(w.object(t, ""))
+		b.WriteString(w.object(t, "Create"))
+		b.WriteString(w.object(t, "Update"))
+	}
+	return []File{{Name: "schemas.ts", Content: []byte(b.String())}}, nil
+}
+
+// object renders one variant of a table's schemas: the rows, the values to
+// insert, which leave out the columns the database generates and may leave
+// out those with a default, or the values to update, which leave out the
+// primary key too and may leave out anything.
+func (w *zodWriter) object(t *Table, variant string) string {
+	name := className(t.Name) + variant
+	var b strings.Builder
+	b.WriteString("\n")
+	if t.Comment != "" && variant == "" {
+		fmt.Fprintf(&b, "/** %s */\n", oneLine(t.Comment))
+	}
+	fmt.Fprintf(&b, "export const %sSchema = z.object({\n", name)
+	for _, col := range t.Columns {
+		if variant != "" && col.IsGenerated() || variant == "Update" && t.IsPrimaryKey(col.ColumnName) {
+			continue
+		}
+		field := w.fieldSchema(t, col)
+		kind, value := parseDefault(col.Default)
+		if col.Nullable() {
+			field += ".nullable()"
+		}
+		switch {
+		case variant == "Update":
+			field += ".optional()"
+		case variant == "Create" && kind != NoDefault:
+			if literal, ok := w.defaultValue(col, kind, value); ok {
+				field += ".default(" + literal + ")"
+			} else {
+				// The database fills in the value when the field is left out.
+				field += ".optional()"
+			}
+		case variant == "Create" && col.Nullable():
+			field += ".optional()"
+		}
+		if col.Comment != "" {
+			fmt.Fprintf(&b, "  /** %s */\n", oneLine(col.Comment))
+		}
+		fmt.Fprintf(&b, "  %s: %s,\n", tsKey(col.ColumnName), field)
+	}
+	b.WriteString("});\n")
+	fmt.Fprintf(&b, "export type %s = z.infer<typeof %sSchema>;\n", name, name)
+	return b.String()
+}
+
+// fieldSchema returns the schema of a column's values, with the rules Zod
+// checks them by.
+func (w *zodWriter) fieldSchema(t *Table, col Column) string {
+	if col.IsArray() {
+		return "z.array(" + w.fieldSchema(t, elementColumn(col)) + ")"
+	}
+	if enum, ok := w.enums[col.UDTName]; ok {
+		return enum + "Schema"
+	}
+	rules := columnRules(t, col)
+	if len(rules.values) > 0 {
+		if schema, ok := zodLiterals(col, rules.values); ok {
+			return schema
+		}
+	}
+
+	switch col.UDTName {
+	case "int2", "int4", "float4", "float8":
+		schema := "z.number()"
+		if col.UDTName == "int2" || col.UDTName == "int4" {
+			schema += ".int()"
+		}
+		if rules.min != nil {
+			schema += fmt.Sprintf(".%s(%s)", map[bool]string{false: "gte", true: "gt"}[rules.min.exclusive], rules.min.value)
+		}
+		if rules.max != nil {
+			schema += fmt.Sprintf(".%s(%s)", map[bool]string{false: "lte", true: "lt"}[rules.max.exclusive], rules.max.value)
+		}
+		return schema
+	case "int8", "numeric", "money":
+		// These come back as strings, checked by their digits and by their
+		// value.
+		pattern := `/^-?\d+(\.\d+)?$/`
+		if col.UDTName == "int8" {
+			pattern = `/^-?\d+$/`
+		} else if precision, scale, ok := columnModifiers(col); ok && col.UDTName == "numeric" {
+			pattern = fmt.Sprintf(`/^-?\d{1,%d}(\.\d{1,%d})?$/`, max(precision-scale, 1), scale)
+			if scale == 0 {
+				pattern = fmt.Sprintf(`/^-?\d{1,%d}$/`, precision)
+			}
+		}
+		schema := "z.string().regex(" + pattern + ")"
+		if rules.min != nil {
+			op := map[bool]string{false: ">=", true: ">"}[rules.min.exclusive]
+			schema += fmt.Sprintf(".refine((v) => Number(v) %s %s, { message: %s })", op, rules.min.value, strconv.Quote("must be "+op+" "+rules.min.value))
+		}
+		if rules.max != nil {
+			op := map[bool]string{false: "<=", true: "<"}[rules.max.exclusive]
+			schema += fmt.Sprintf(".refine((v) => Number(v) %s %s, { message: %s })", op, rules.max.value, strconv.Quote("must be "+op+" "+rules.max.value))
+		}
+		return schema
+	case "bool":
+		return "z.boolean()"
+	case "uuid":
+		return "z.string().uuid()"
+	case "json", "jsonb":
+		return "z.unknown()"
+	case "date":
+		return "z.string().date()"
+	case "timestamp", "timestamptz":
+		return "z.coerce.date()"
+	case "time":
+		return "z.string().time()"
+	case "bytea":
+		return "z.instanceof(Buffer)"
+	case "inet":
+		return "z.string().ip()"
+	case "varchar", "bpchar", "text", "citext":
+		schema := "z.string()"
+		if rules.minLength > 0 {
+			schema += fmt.Sprintf(".min(%d)", rules.minLength)
+		}
+		if rules.maxLength > 0 {
+			schema += fmt.Sprintf(".max(%d)", rules.maxLength)
+		}
+		return schema
+	}
+	return "z.string()"
+}
+
+// zodLiterals returns the schema allowing only the values of an IN list.
+func zodLiterals(col Column, values []literal) (string, bool) {
+	var quoted []string
+	strs := tsType(col.UDTName) == "string"
+	for _, v := range values {
+		switch {
+		case strs:
+			quoted = append(quoted, strconv.Quote(v.value))
+		case v.string:
+			return "", false
+		default:
+			quoted = append(quoted, v.value)
+		}
+	}
+	switch {
+	case strs && len(quoted) > 1:
+		return "z.enum([" + strings.Join(quoted, ", ") + "])", true
+	case len(quoted) == 1:
+		return "z.literal(" + quoted[0] + ")", true
+	}
+	for i, q := range quoted {
+		quoted[i] = "z.literal(" + q + ")"
+	}
+	return "z.union([" + strings.Join(quoted, ", ") + "])", true
+}
+
+// defaultValue returns a column default as a TypeScript literal of the
+// column's type.
+func (w *zodWriter) defaultValue(col Column, kind DefaultKind, value string) (string, bool) {
+	if col.IsArray() {
+		return "", false
+	}
+	_, isEnum := w.enums[col.UDTName]
+	switch {
+	case kind == BoolDefault && col.UDTName == "bool":
+		return value, true
+	case kind == NumberDefault && tsType(col.UDTName) == "number":
+		return value, true
+	case kind == NumberDefault && (col.UDTName == "int8" || col.UDTName == "numeric"):
+		return strconv.Quote(value), true
+	case kind == StringDefault && isEnum:
+		return strconv.Quote(value), true
+	case kind == StringDefault:
+		switch col.UDTName {
+		case "varchar", "bpchar", "text", "citext":
+			return strconv.Quote(value), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,7 @@
+package internal
+
+import "testing"
+
+func TestGenerateZod(t *testing.T) {
+	generateGolden(t, generateZod, "zod", "schemas.ts")
+}
@@ -0,0 +1,247 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// graphQLScalars maps udt names to the GraphQL scalars of their values.
+// Those that aren't built in are declared as custom scalars when used.
+var graphQLScalars = map[string]string{
+	"int2": "Int", "int4": "Int", "int8": "BigInt", "float4": "Float", "float8": "Float",
+	"numeric": "Decimal", "money": "Decimal", "bool": "Boolean", "uuid": "UUID",
+	"date": "Date", "timestamp": "DateTime", "timestamptz": "DateTime", "time": "Time",
+	"timetz": "Time", "interval": "Interval", "json": "JSON", "jsonb": "JSON", "bytea": "Bytes",
+}
+
+var graphQLBuiltins = map[string]bool{"Int": true, "Float": true, "String": true, "Boolean": true, "ID": true}
+
+var graphQLName = regexp.MustCompile(`^[_A-Za-z][_0-9A-Za-z]*$`)
+
+// graphQLDescription renders a comment as a block string description.
+func graphQLDescription(comment, indent string) string {
+	return indent + `"""` + strings.ReplaceAll(oneLine(comment), `"""`, `\"""`) + `"""` + "\n"
+}
+
+// graphQLEnumValue turns an enum value into an upper case GraphQL enum
+// value.
+func graphQLEnumValue(value string) string {
+	name := pythonConstant(value)
+	if name == "TRUE" || name == "FALSE" || name == "NULL" {
+		name = "V_" + name
+	}
+	return name
+}
+
+type graphQLWriter struct {
+	schema *Schema
+	// types maps tables to their object types, enums the enum types to
+	// their GraphQL enums.
+	types   map[string]string
+	enums   map[string]string
+	scalars map[string]bool
+	// fields maps each table's columns to their field names, relations
+	// its relations by relationKey to theirs.
+	fields    map[string]map[string]string
+	relations map[string]map[string]string
+	// connections lists the object types listed by a connection.
+	connections map[string]bool
+}
+
+// GraphQLSchema renders the schema as GraphQL SDL: an object type per
+// table with a field per column, non-null unless the column is nullable,
+// and a field per relation, a Relay connection for the lists of rows
+// referencing the table; an enum per enum type; and per table the input
+// types to create rows with, without the columns the database generates,
+// and to update them, with every field optional.
+func GraphQLSchema(schema *Schema) []byte {
+	w := &graphQLWriter{
+		schema:      schema,
+		types:       make(map[string]string),
+		enums:       make(map[string]string),
+		scalars:     make(map[string]bool),
+		fields:      make(map[string]map[string]string),
+		relations:   make(map[string]map[string]string),
+		connections: make(map[string]bool),
+	}
+	taken := map[string]bool{"PageInfo": true, "Query": true, "Mutation": true, "Subscription": true}
+	for name := range graphQLBuiltins {
+		taken[name] = true
+	}
+	for _, scalar := range graphQLScalars {
+		taken[scalar] = true
+	}
+	reserve := func(name, suffix string) string {
+		if !graphQLName.MatchString(name) {
+			name = "T" + name
+		}
+		for taken[name] || taken[name+"Connection"] || taken[name+"Edge"] {
+			name += suffix
+		}
+		taken[name] = true
+		return name
+	}
+	for _, t := range schema.Tables {
+		w.types[t.Name] = reserve(className(t.Name), "Type")
+	}
+	for _, t := range schema.Tables {
+		taken["Create"+w.types[t.Name]+"Input"] = true
+		taken["Update"+w.types[t.Name]+"Input"] = true
+		taken[w.types[t.Name]+"Connection"] = true
+		taken[w.types[t.Name]+"Edge"] = true
+	}
+	for _, e := range schema.Enums {
+		w.enums[e.Name] = reserve(pascalCase(e.Name), "Enum")
+	}
+	for _, t := range schema.Tables {
+		used := make(map[string]bool)
+		field := func(base string) string {
+			name := tsProperty(base)
+			for used[name] {
+				name += "_"
+			}
+			used[name] = true
+			return name
+		}
+		w.fields[t.Name] = make(map[string]string)
+		for _, col := range t.Columns {
+			w.fields[t.Name][col.ColumnName] = field(col.ColumnName)
+		}
+		w.relations[t.Name] = make(map[string]string)
+		for _, rel := range schema.Relations(t.Name) {
+			w.relations[t.Name][relationKey(rel.Owner, rel.Other, rel.ForeignKey.ConstraintName)] = field(rel.Name)
+			if rel.Many {
+				w.connections[rel.Other] = true
+			}
+		}
+	}
+
+	var body strings.Builder
+	for _, e := range schema.Enums {
+		fmt.Fprintf(&body, "\nenum %s {\n", w.enums[e.Name])
+		used := make(map[string]bool)
+		for _, v := range e.Values {
+			name := graphQLEnumValue(v)
+			for used[name] {
+				name += "_"
+			}
+			used[name] = true
+			if name != strings.ToUpper(v) {
+				body.WriteString(graphQLDescription(v, "  "))
+			}
+			fmt.Fprintf(&body, "  %s\n", name)
+		}
+		body.WriteString("}\n")
+	}
+	for i := range schema.Tables {
+		body.WriteString(w.object(&schema.Tables[i]))
+	}
+	if len(w.connections) > 0 {
+		body.WriteString("\ntype PageInfo {\n  hasNextPage: Boolean!\n  hasPreviousPage: Boolean!\n  startCursor: String\n  endCursor: String\n}\n")
+	}
+	for _, t := range schema.Tables {
+		if !w.connections[t.Name] {
+			continue
+		}
+		typ := w.types[t.Name]
+		fmt.Fprintf(&body, "\ntype %sConnection {\n  edges: [%sEdge!]!\n  nodes: [%s!]!\n  pageInfo: PageInfo!\n  totalCount: Int!\n}\n", typ, typ, typ)
+		fmt.Fprintf(&body, "\ntype %sEdge {\n  cursor: String!\n  node: %s!\n}\n", typ, typ)
+	}
+	for i := range schema.Tables {
+		body.WriteString(w.input(&schema.Tables[i], "Create"))
+		body.WriteString(w.input(&schema.Tables[i], "Update"))
+	}
+
+	var b strings.Builder
+	b.WriteString("# Generated by schema transform. Do not edit.\n")
+	for _, scalar := range []string{"BigInt", "Bytes", "Date", "DateTime", "Decimal", "Interval", "JSON", "Time", "UUID"} {
+		if w.scalars[scalar] {
+			fmt.Fprintf(&b, "\nscalar %s", scalar)
+		}
+	}
+	if len(w.scalars) > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString(body.String())
+	return []byte(b.String())
+}
+
+// fieldType returns the GraphQL type of a column, without its non-null
+// marker.
+func (w *graphQLWriter) fieldType(col Column) string {
+	udt := col.ElementType()
+	typ, ok := graphQLScalars[udt]
+	if !ok {
+		typ = "String"
+	}
+	if enum, isEnum := w.enums[udt]; isEnum {
+		typ = enum
+	} else if !graphQLBuiltins[typ] {
+		w.scalars[typ] = true
+	}
+	if col.IsArray() {
+		typ = "[" + typ + "!]"
+	}
+	return typ
+}
+
+func (w *graphQLWriter) object(t *Table) string {
+	var b strings.Builder
+	b.WriteString("\n")
+	if t.Comment != "" {
+		b.WriteString(graphQLDescription(t.Comment, ""))
+	}
+	fmt.Fprintf(&b, "type %s {\n", w.types[t.Name])
+	for _, col := range t.Columns {
+		if col.Comment != "" {
+			b.WriteString(graphQLDescription(col.Comment, "  "))
+		}
+		typ := w.fieldType(col)
+		if !col.Nullable() {
+			typ += "!"
+		}
+		fmt.Fprintf(&b, "  %s: %s\n", w.fields[t.Name][col.ColumnName], typ)
+	}
+	for _, rel := range w.schema.Relations(t.Name) {
+		name := w.relations[t.Name][relationKey(rel.Owner, rel.Other, rel.ForeignKey.ConstraintName)]
+		other := w.types[rel.Other]
+		switch {
+		case rel.Many:
+			fmt.Fprintf(&b, "  %s(first: Int, after: String, last: Int, before: String): %sConnection!\n", name, other)
+		case rel.Owner && !anyNullable(t, rel.ForeignKey.SourceColumns):
+			fmt.Fprintf(&b, "  %s: %s!\n", name, other)
+		default:
+			fmt.Fprintf(&b, "  %s: %s\n", name, other)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// input renders the input type to create or update rows of a table. Both
+// leave out the columns the database generates, and updates the primary
+// key too; creating rows needs the values of the non-null columns without
+// a default.
+func (w *graphQLWriter) input(t *Table, variant string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\ninput %s%sInput {\n", variant, w.types[t.Name])
+	fields := 0
+	for _, col := range t.Columns {
+		if col.IsGenerated() || variant == "Update" && t.IsPrimaryKey(col.ColumnName) {
+			continue
+		}
+		typ := w.fieldType(col)
+		if variant == "Create" && !col.Nullable() && col.Default == "" {
+			typ += "!"
+		}
+		fmt.Fprintf(&b, "  %s: %s\n", w.fields[t.Name][col.ColumnName], typ)
+		fields++
+	}
+	b.WriteString("}\n")
+	// Input types need a field.
+	if fields == 0 {
+		return ""
+	}
+	return b.String()
+}
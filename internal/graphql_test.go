@@ -0,0 +1,41 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGraphQLSchema(t *testing.T) {
+	golden(t, "graphql/schema.graphql", GraphQLSchema(blogSchema()))
+}
+
+func TestGraphQLSchemaNames(t *testing.T) {
+	schema := blogSchemaWith(
+		addTable(Table{
+			Name:       "page_info",
+			Columns:    []Column{column("id", "integer", false)},
+			PrimaryKey: &PrimaryKey{ConstraintName: "page_info_pkey", Columns: []string{"id"}},
+		}),
+		addTable(Table{Name: "user", Columns: []Column{column("id", "integer", false)}}),
+		addEnumValues("post_status", "true", "Draft"),
+		func(s *Schema) { s.Enums = append(s.Enums, Enum{Name: "tag", Values: []string{"a"}}) },
+	)
+	sdl := string(GraphQLSchema(schema))
+	for _, line := range []string{
+		"type PageInfoType {",
+		"type User {",
+		"type UserType {",
+		"enum TagEnum {",
+		"  V_TRUE",
+		"  DRAFT_",
+		"  DRAFT",
+		"input CreatePageInfoTypeInput {",
+	} {
+		if !containsLine(sdl, line+"\n") {
+			t.Errorf("schema doesn't contain %q:\n%s", line, sdl)
+		}
+	}
+	if strings.Contains(sdl, "input UpdatePageInfoTypeInput") {
+		t.Errorf("schema has an update input without fields:\n%s", sdl)
+	}
+}
@@ -0,0 +1,321 @@
+package internal
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// DefaultSchema is the Postgres schema every command introspects.
+const DefaultSchema = "public"
+
+// Queryer runs read queries; both *sql.DB and *sql.Tx satisfy it, which lets
+// a schema be introspected inside a transaction.
+type Queryer interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// IntrospectPostgres reads tables, columns, constraints, indexes, enum types,
+// views and functions of the given schema into a Schema model.
+func IntrospectPostgres(db Queryer, schemaName string) (*Schema, error) {
+	schema := &Schema{Name: schemaName}
+	var err error
+
+	if schema.Enums, err = introspectEnums(db, schemaName); err != nil {
+		return nil, fmt.Errorf("failed to read enum types: %w", err)
+	}
+	if schema.Tables, err = introspectTables(db, schemaName); err != nil {
+		return nil, fmt.Errorf("failed to read tables: %w", err)
+	}
+	if err = introspectConstraints(db, schemaName, schema); err != nil {
+		return nil, fmt.Errorf("failed to read constraints: %w", err)
+	}
+	if err = introspectIndexes(db, schemaName, schema); err != nil {
+		return nil, fmt.Errorf("failed to read indexes: %w", err)
+	}
+	if schema.Views, err = introspectViews(db, schemaName); err != nil {
+		return nil, fmt.Errorf("failed to read views: %w", err)
+	}
+	if schema.Functions, err = introspectFunctions(db, schemaName); err != nil {
+		return nil, fmt.Errorf("failed to read functions: %w", err)
+	}
+	return schema, nil
+}
+
+func introspectEnums(db Queryer, schemaName string) ([]Enum, error) {
+	rows, err := db.Query(`
+        SELECT t.typname, array_agg(e.enumlabel ORDER BY e.enumsortorder)::text[]
+        FROM pg_type t
+        JOIN pg_enum e ON e.enumtypid = t.oid
+        JOIN pg_namespace n ON n.oid = t.typnamespace
+        WHERE n.nspname = $1
+        GROUP BY t.typname
+        ORDER BY t.typname;
+    `, schemaName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var enums []Enum
+	for rows.Next() {
+		var e Enum
+		if err := rows.Scan(&e.Name, pq.Array(&e.Values)); err != nil {
+			return nil, err
+		}
+		enums = append(enums, e)
+	}
+	return enums, rows.Err()
+}
+
+func introspectTables(db Queryer, schemaName string) ([]Table, error) {
+	rows, err := db.Query(`
+        SELECT c.table_name, c.column_name, c.data_type, c.udt_name,
+               format_type(a.atttypid, a.atttypmod), c.is_nullable,
+               c.character_maximum_length, c.numeric_precision, c.numeric_scale,
+               COALESCE(c.column_default, ''), c.is_identity = 'YES',
+               COALESCE(c.identity_generation, ''),
+               COALESCE(col_description(a.attrelid, a.attnum), ''),
+               COALESCE(obj_description(a.attrelid, 'pg_class'), '')
+        FROM information_schema.columns AS c
+        JOIN information_schema.tables AS t
+            ON t.table_schema = c.table_schema
+            AND t.table_name = c.table_name
+        JOIN pg_attribute AS a
+            ON a.attrelid = format('%I.%I', c.table_schema, c.table_name)::regclass
+            AND a.attname = c.column_name
+        WHERE c.table_schema = $1
+          AND t.table_type = 'BASE TABLE'
+        ORDER BY c.table_name, c.ordinal_position;
+    `, schemaName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []Table
+	for rows.Next() {
+		var table, tableComment string
+		var col Column
+		var charLen, precision, scale sql.NullInt64
+		err := rows.Scan(&table, &col.ColumnName, &col.DataType, &col.UDTName,
+			&col.FullType, &col.IsNullable, &charLen, &precision, &scale,
+			&col.Default, &col.IsIdentity, &col.IdentityGeneration,
+			&col.Comment, &tableComment)
+		if err != nil {
+			return nil, err
+		}
+		col.CharMaxLength = charLen.Int64
+		col.NumericPrecision = precision.Int64
+		col.NumericScale = scale.Int64
+
+		if len(tables) == 0 || tables[len(tables)-1].Name != table {
+			tables = append(tables, Table{Name: table, Comment: tableComment})
+		}
+		t := &tables[len(tables)-1]
+		t.Columns = append(t.Columns, col)
+	}
+	return tables, rows.Err()
+}
+
+// referentialActions maps pg_constraint.confdeltype/confupdtype codes.
+var referentialActions = map[string]string{
+	"a": "NO ACTION",
+	"r": "RESTRICT",
+	"c": "CASCADE",
+	"n": "SET NULL",
+	"d": "SET DEFAULT",
+}
+
+func introspectConstraints(db Queryer, schemaName string, schema *Schema) error {
+	rows, err := db.Query(`
+        SELECT con.conname, rel.relname, con.contype,
+               ARRAY(
+                   SELECT a.attname
+                   FROM unnest(con.conkey) WITH ORDINALITY AS k(attnum, ord)
+                   JOIN pg_attribute AS a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+                   ORDER BY k.ord
+               )::text[],
+               COALESCE(frel.relname, ''),
+               ARRAY(
+                   SELECT a.attname
+                   FROM unnest(con.confkey) WITH ORDINALITY AS k(attnum, ord)
+                   JOIN pg_attribute AS a ON a.attrelid = con.confrelid AND a.attnum = k.attnum
+                   ORDER BY k.ord
+               )::text[],
+               con.confdeltype, con.confupdtype,
+               COALESCE(pg_get_expr(con.conbin, con.conrelid), '')
+        FROM pg_constraint AS con
+        JOIN pg_class AS rel ON rel.oid = con.conrelid
+        JOIN pg_namespace AS n ON n.oid = rel.relnamespace
+        LEFT JOIN pg_class AS frel ON frel.oid = con.confrelid
+        WHERE n.nspname = $1
+          AND con.contype IN ('p', 'u', 'f', 'c')
+        ORDER BY rel.relname, con.conname;
+    `, schemaName)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name, table, kind, target, onDelete, onUpdate, expr string
+		var columns, targetColumns []string
+		err := rows.Scan(&name, &table, &kind, pq.Array(&columns), &target,
+			pq.Array(&targetColumns), &onDelete, &onUpdate, &expr)
+		if err != nil {
+			return err
+		}
+		t := schema.Table(table)
+		if t == nil {
+			continue
+		}
+		switch kind {
+		case "p":
+			t.PrimaryKey = &PrimaryKey{ConstraintName: name, Columns: columns}
+		case "u":
+			t.Uniques = append(t.Uniques, UniqueConstraint{ConstraintName: name, Columns: columns})
+		case "f":
+			t.ForeignKeys = append(t.ForeignKeys, ForeignKey{
+				ConstraintName: name,
+				SourceTable:    table,
+				SourceColumns:  columns,
+				TargetTable:    target,
+				TargetColumns:  targetColumns,
+				OnDelete:       referentialActions[onDelete],
+				OnUpdate:       referentialActions[onUpdate],
+			})
+		case "c":
+			t.Checks = append(t.Checks, CheckConstraint{ConstraintName: name, Expression: expr})
+		}
+	}
+	return rows.Err()
+}
+
+func introspectIndexes(db Queryer, schemaName string, schema *Schema) error {
+	// Indexes backing primary key and unique constraints are skipped, they
+	// are already part of the constraint model.
+	rows, err := db.Query(`
+        SELECT t.relname, i.relname, ix.indisunique, am.amname,
+               ARRAY(
+                   SELECT pg_get_indexdef(ix.indexrelid, k, true)
+                   FROM generate_series(1, ix.indnkeyatts) AS k
+                   ORDER BY k
+               )::text[],
+               COALESCE(pg_get_expr(ix.indpred, ix.indrelid), ''),
+               pg_get_indexdef(ix.indexrelid)
+        FROM pg_index AS ix
+        JOIN pg_class AS t ON t.oid = ix.indrelid
+        JOIN pg_class AS i ON i.oid = ix.indexrelid
+        JOIN pg_namespace AS n ON n.oid = t.relnamespace
+        JOIN pg_am AS am ON am.oid = i.relam
+        WHERE n.nspname = $1
+          AND t.relkind IN ('r', 'p')
+          AND NOT EXISTS (
+              SELECT 1 FROM pg_constraint AS c
+              WHERE c.conindid = ix.indexrelid AND c.contype IN ('p', 'u', 'x')
+          )
+        ORDER BY t.relname, i.relname;
+    `, schemaName)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var table string
+		var idx Index
+		err := rows.Scan(&table, &idx.Name, &idx.Unique, &idx.Method,
+			pq.Array(&idx.Columns), &idx.Where, &idx.Definition)
+		if err != nil {
+			return err
+		}
+		if t := schema.Table(table); t != nil {
+			t.Indexes = append(t.Indexes, idx)
+		}
+	}
+	return rows.Err()
+}
+
+func introspectViews(db Queryer, schemaName string) ([]View, error) {
+	rows, err := db.Query(`
+        SELECT c.relname, c.relkind = 'm', pg_get_viewdef(c.oid, true),
+               ARRAY(
+                   SELECT DISTINCT d.relname
+                   FROM pg_rewrite AS r
+                   JOIN pg_depend AS dep
+                       ON dep.objid = r.oid
+                       AND dep.classid = 'pg_rewrite'::regclass
+                       AND dep.refclassid = 'pg_class'::regclass
+                   JOIN pg_class AS d ON d.oid = dep.refobjid
+                   WHERE r.ev_class = c.oid AND d.oid <> c.oid
+                   ORDER BY d.relname
+               )::text[]
+        FROM pg_class AS c
+        JOIN pg_namespace AS n ON n.oid = c.relnamespace
+        WHERE n.nspname = $1
+          AND c.relkind IN ('v', 'm')
+        ORDER BY c.relname;
+    `, schemaName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var views []View
+	for rows.Next() {
+		var v View
+		if err := rows.Scan(&v.Name, &v.Materialized, &v.Definition, pq.Array(&v.DependsOn)); err != nil {
+			return nil, err
+		}
+		v.Definition = strings.TrimSpace(v.Definition)
+		views = append(views, v)
+	}
+	return views, rows.Err()
+}
+
+func introspectFunctions(db Queryer, schemaName string) ([]Function, error) {
+	// Functions owned by extensions are recreated by CREATE EXTENSION and
+	// are left out of the dump.
+	rows, err := db.Query(`
+        SELECT p.proname, pg_get_function_identity_arguments(p.oid),
+               pg_get_functiondef(p.oid),
+               ARRAY(
+                   SELECT DISTINCT t.typname
+                   FROM pg_depend AS dep
+                   JOIN pg_type AS t ON t.oid = dep.refobjid
+                   JOIN pg_namespace AS tn ON tn.oid = t.typnamespace
+                   WHERE dep.objid = p.oid
+                     AND dep.classid = 'pg_proc'::regclass
+                     AND dep.refclassid = 'pg_type'::regclass
+                     AND tn.nspname = n.nspname
+                   ORDER BY t.typname
+               )::text[]
+        FROM pg_proc AS p
+        JOIN pg_namespace AS n ON n.oid = p.pronamespace
+        WHERE n.nspname = $1
+          AND p.prokind IN ('f', 'p')
+          AND NOT EXISTS (
+              SELECT 1 FROM pg_depend AS d
+              WHERE d.objid = p.oid AND d.deptype = 'e'
+          )
+        ORDER BY p.proname, 2;
+    `, schemaName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var functions []Function
+	for rows.Next() {
+		var f Function
+		if err := rows.Scan(&f.Name, &f.Arguments, &f.Definition, pq.Array(&f.DependsOn)); err != nil {
+			return nil, err
+		}
+		f.Definition = strings.TrimSpace(f.Definition)
+		functions = append(functions, f)
+	}
+	return functions, rows.Err()
+}
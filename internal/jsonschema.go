@@ -0,0 +1,275 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// jsonSchema is a JSON Schema, as far as tables need one. OpenAPI 3.1
+// schema objects are JSON Schemas too.
+type jsonSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	ID                   string                 `json:"$id,omitempty"`
+	Ref                  string                 `json:"$ref,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 any                    `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	ContentEncoding      string                 `json:"contentEncoding,omitempty"`
+	MaxLength            int64                  `json:"maxLength,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	AnyOf                []*jsonSchema          `json:"anyOf,omitempty"`
+	Default              any                    `json:"default,omitempty"`
+	Properties           jsonProperties         `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"`
+	Defs                 map[string]*jsonSchema `json:"$defs,omitempty"`
+}
+
+// jsonProperties are the properties of an object schema, kept in column
+// order.
+type jsonProperties []jsonProperty
+
+type jsonProperty struct {
+	Name   string
+	Schema *jsonSchema
+}
+
+func (p jsonProperties) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, property := range p {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		name, err := marshalJSONSchema(property.Name)
+		if err != nil {
+			return nil, err
+		}
+		schema, err := marshalJSONSchema(property.Schema)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(bytes.TrimSpace(name))
+		b.WriteByte(':')
+		b.Write(bytes.TrimSpace(schema))
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// jsonSchemaFormats maps udt names to the JSON type and format of their
+// values.
+var jsonSchemaFormats = map[string][2]string{
+	"int2": {"integer", "int32"}, "int4": {"integer", "int32"}, "int8": {"integer", "int64"},
+	"float4": {"number", "float"}, "float8": {"number", "double"}, "numeric": {"number", ""},
+	"money": {"number", ""}, "bool": {"boolean", ""}, "uuid": {"string", "uuid"},
+	"date": {"string", "date"}, "timestamp": {"string", "date-time"}, "timestamptz": {"string", "date-time"},
+	"time": {"string", "time"}, "timetz": {"string", "time"}, "interval": {"string", "duration"},
+	"bytea": {"string", ""},
+}
+
+// tableSchemas builds the schemas of a table's rows. enumRef and columnRef
+// return the $ref of an enum type and of the column a foreign key
+// references, the two places the formats differ.
+type tableSchemas struct {
+	schema    *Schema
+	enumRef   func(enum string) string
+	columnRef func(table, column string) string
+}
+
+// object returns the schema of a table's rows: every column is present,
+// nullable ones may be null.
+func (s tableSchemas) object(t *Table) *jsonSchema {
+	closed := false
+	object := &jsonSchema{Type: "object", Description: t.Comment, AdditionalProperties: &closed}
+	for _, col := range t.Columns {
+		object.Properties = append(object.Properties, jsonProperty{Name: col.ColumnName, Schema: s.column(t, col)})
+		object.Required = append(object.Required, col.ColumnName)
+	}
+	return object
+}
+
+// column returns the schema of a column's values. Foreign key columns
+// reference the schema of the column they point at.
+func (s tableSchemas) column(t *Table, col Column) *jsonSchema {
+	var schema *jsonSchema
+	if fk := t.ForeignKeyFor(col.ColumnName); fk != nil {
+		target := fk.TargetColumns[slices.Index(fk.SourceColumns, col.ColumnName)]
+		schema = &jsonSchema{Ref: s.columnRef(fk.TargetTable, target)}
+	} else {
+		schema = s.values(col)
+	}
+	if col.Nullable() {
+		if schema.Ref != "" {
+			schema = &jsonSchema{AnyOf: []*jsonSchema{schema, {Type: "null"}}}
+		} else if typ, ok := schema.Type.(string); ok {
+			schema.Type = []string{typ, "null"}
+		}
+	}
+	schema.Description = col.Comment
+	schema.Default = s.defaultValue(col)
+	return schema
+}
+
+// values returns the schema of a column's values, or of its elements'
+// values for arrays.
+func (s tableSchemas) values(col Column) *jsonSchema {
+	if col.IsArray() {
+		return &jsonSchema{Type: "array", Items: s.values(elementColumn(col))}
+	}
+	if s.schema.Enum(col.UDTName) != nil {
+		return &jsonSchema{Ref: s.enumRef(col.UDTName)}
+	}
+	switch col.UDTName {
+	case "json", "jsonb":
+		// Any JSON value.
+		return &jsonSchema{}
+	case "bytea":
+		return &jsonSchema{Type: "string", ContentEncoding: "base64"}
+	case "varchar", "bpchar":
+		schema := &jsonSchema{Type: "string"}
+		if length, _, ok := columnModifiers(col); ok {
+			schema.MaxLength = length
+		}
+		return schema
+	}
+	if format, ok := jsonSchemaFormats[col.UDTName]; ok {
+		return &jsonSchema{Type: format[0], Format: format[1]}
+	}
+	return &jsonSchema{Type: "string"}
+}
+
+// defaultValue returns the default of a column as a JSON value, or nil when
+// the database computes it.
+func (s tableSchemas) defaultValue(col Column) any {
+	if col.IsArray() {
+		return nil
+	}
+	kind, value := parseDefault(col.Default)
+	typ := jsonSchemaFormats[col.UDTName][0]
+	switch {
+	case kind == BoolDefault && typ == "boolean":
+		return value == "true"
+	case kind == NumberDefault && (typ == "integer" || typ == "number"):
+		return json.Number(value)
+	case kind == StringDefault && (typ == "" || typ == "string") && col.UDTName != "json" && col.UDTName != "jsonb":
+		return value
+	}
+	return nil
+}
+
+// jsonPointer returns a JSON pointer to a property, escaped for a URI
+// fragment.
+func jsonPointer(property string) string {
+	token := strings.NewReplacer("~", "~0", "/", "~1").Replace(property)
+	return "/properties/" + url.PathEscape(token)
+}
+
+// JSONSchemaFiles renders a JSON Schema (draft 2020-12) of each table's
+// rows, one <table>.schema.json file per table. Foreign key columns $ref
+// the column they point at in the other table's file, and enum types are
+// kept in $defs.
+func JSONSchemaFiles(schema *Schema) ([]File, error) {
+	var files []File
+	for i := range schema.Tables {
+		t := &schema.Tables[i]
+		s := tableSchemas{
+			schema:  schema,
+			enumRef: func(enum string) string { return "#/$defs/" + url.PathEscape(enum) },
+			columnRef: func(table, column string) string {
+				return url.PathEscape(table) + ".schema.json#" + jsonPointer(column)
+			},
+		}
+		object := s.object(t)
+		object.Schema = "https://json-schema.org/draft/2020-12/schema"
+		object.ID = url.PathEscape(t.Name) + ".schema.json"
+		object.Title = t.Name
+		for _, col := range t.Columns {
+			if e := schema.Enum(col.ElementType()); e != nil && t.ForeignKeyFor(col.ColumnName) == nil {
+				if object.Defs == nil {
+					object.Defs = make(map[string]*jsonSchema)
+				}
+				object.Defs[e.Name] = &jsonSchema{Type: "string", Enum: e.Values}
+			}
+		}
+		data, err := marshalJSONSchema(object)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, File{Name: t.Name + ".schema.json", Content: data})
+	}
+	return files, nil
+}
+
+// openAPIDocument is an OpenAPI 3.1 document with only components.
+type openAPIDocument struct {
+	OpenAPI string `json:"openapi"`
+	Info    struct {
+		Title   string `json:"title"`
+		Version string `json:"version"`
+	} `json:"info"`
+	Components struct {
+		Schemas jsonProperties `json:"schemas"`
+	} `json:"components"`
+}
+
+// OpenAPIComponents renders an OpenAPI 3.1 document whose components/schemas
+// hold a schema per table, named like the table's model, and per enum type,
+// to merge into an API's document. Foreign key columns $ref the column they
+// point at.
+func OpenAPIComponents(schema *Schema) ([]byte, error) {
+	names := make(map[string]string)
+	taken := make(map[string]bool)
+	for _, t := range schema.Tables {
+		names[t.Name] = className(t.Name)
+		taken[names[t.Name]] = true
+	}
+	enums := make(map[string]string)
+	for _, e := range schema.Enums {
+		name := pascalCase(e.Name)
+		for taken[name] {
+			name += "Enum"
+		}
+		taken[name] = true
+		enums[e.Name] = name
+	}
+	s := tableSchemas{
+		schema:  schema,
+		enumRef: func(enum string) string { return "#/components/schemas/" + url.PathEscape(enums[enum]) },
+		columnRef: func(table, column string) string {
+			return "#/components/schemas/" + url.PathEscape(names[table]) + jsonPointer(column)
+		},
+	}
+
+	var doc openAPIDocument
+	doc.OpenAPI = "3.1.0"
+	doc.Info.Title = "Schema components"
+	doc.Info.Version = "1.0.0"
+	for _, e := range schema.Enums {
+		doc.Components.Schemas = append(doc.Components.Schemas, jsonProperty{Name: enums[e.Name], Schema: &jsonSchema{Type: "string", Enum: e.Values}})
+	}
+	for i := range schema.Tables {
+		t := &schema.Tables[i]
+		object := s.object(t)
+		object.Title = t.Name
+		doc.Components.Schemas = append(doc.Components.Schemas, jsonProperty{Name: names[t.Name], Schema: object})
+	}
+	return marshalJSONSchema(doc)
+}
+
+func marshalJSONSchema(v any) ([]byte, error) {
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return nil, fmt.Errorf("failed to encode schema: %w", err)
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,94 @@
+package internal
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// resolveRefs checks that every $ref in the documents, keyed by file name,
+// points at an existing location.
+func resolveRefs(t *testing.T, docs map[string]any) {
+	t.Helper()
+	var walk func(file string, v any)
+	walk = func(file string, v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			if ref, ok := v["$ref"].(string); ok {
+				target, pointer, _ := strings.Cut(ref, "#")
+				if target == "" {
+					target = file
+				}
+				if target, err := url.PathUnescape(target); err != nil || !resolvePointer(docs[target], pointer) {
+					t.Errorf("%s: $ref %s doesn't resolve", file, ref)
+				}
+			}
+			for _, child := range v {
+				walk(file, child)
+			}
+		case []any:
+			for _, child := range v {
+				walk(file, child)
+			}
+		}
+	}
+	for file, doc := range docs {
+		walk(file, doc)
+	}
+}
+
+func resolvePointer(doc any, pointer string) bool {
+	if doc == nil {
+		return false
+	}
+	if pointer == "" {
+		return true
+	}
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token, err := url.PathUnescape(token)
+		if err != nil {
+			return false
+		}
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		object, ok := doc.(map[string]any)
+		if !ok {
+			return false
+		}
+		if doc, ok = object[token]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func decodeJSON(t *testing.T, data []byte) any {
+	t.Helper()
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestJSONSchemaFiles(t *testing.T) {
+	files, err := JSONSchemaFiles(blogSchema())
+	if err != nil {
+		t.Fatal(err)
+	}
+	goldenFiles(t, "jsonschema", files)
+	docs := make(map[string]any)
+	for _, f := range files {
+		docs[f.Name] = decodeJSON(t, f.Content)
+	}
+	resolveRefs(t, docs)
+}
+
+func TestOpenAPIComponents(t *testing.T) {
+	data, err := OpenAPIComponents(blogSchema())
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden(t, "openapi/components.json", data)
+	resolveRefs(t, map[string]any{"": decodeJSON(t, data)})
+}
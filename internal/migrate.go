@@ -0,0 +1,732 @@
+package internal
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// MigrationPlan holds the statements migrating a schema up to a new state
+// and back down again.
+type MigrationPlan struct {
+	Up       []string
+	Down     []string
+	Warnings []string
+}
+
+// PlanMigration derives the statements turning from into to, and the
+// reverse. Both directions are planned from the same diff logic so that
+// applying up and then down returns to the starting schema.
+func PlanMigration(from, to *Schema) *MigrationPlan {
+	up := newMigrationPlanner(from, to)
+	down := newMigrationPlanner(to, from)
+	plan := &MigrationPlan{Up: up.plan(), Down: down.plan()}
+	for _, w := range up.warnings {
+		plan.Warnings = append(plan.Warnings, "up: "+w)
+	}
+	for _, w := range down.warnings {
+		plan.Warnings = append(plan.Warnings, "down: "+w)
+	}
+	return plan
+}
+
+// UpSQL returns the up migration as a single transactional script.
+func (p *MigrationPlan) UpSQL() string {
+	return transactionScript(p.Up)
+}
+
+// DownSQL returns the down migration as a single transactional script.
+func (p *MigrationPlan) DownSQL() string {
+	return transactionScript(p.Down)
+}
+
+// Empty reports whether there is nothing to migrate.
+func (p *MigrationPlan) Empty() bool {
+	return len(p.Up) == 0 && len(p.Down) == 0
+}
+
+// enumAddValue matches the statements adding a value to an enum type.
+var enumAddValue = regexp.MustCompile(`(?i)^ALTER\s+TYPE\s+\S+\s+ADD\s+VALUE\b`)
+
+// splitEnumValues separates the enum values added at the start of a
+// migration from the statements after them. The values stay outside the
+// transaction: ALTER TYPE ... ADD VALUE can't run in a transaction block
+// before PostgreSQL 12, and later statements couldn't use the new values
+// before it commits.
+func splitEnumValues(statements []string) (values, rest []string) {
+	i := 0
+	for i < len(statements) && enumAddValue.MatchString(statements[i]) {
+		i++
+	}
+	return statements[:i], statements[i:]
+}
+
+// transactionScript wraps the statements in a transaction, after the enum
+// values they add.
+func transactionScript(statements []string) string {
+	var b strings.Builder
+	values, statements := splitEnumValues(statements)
+	for _, stmt := range values {
+		b.WriteString(stmt)
+		b.WriteString("\n")
+	}
+	if len(values) > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString("BEGIN;\n\n")
+	for _, stmt := range statements {
+		b.WriteString(stmt)
+		b.WriteString("\n")
+	}
+	if len(statements) > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString("COMMIT;\n")
+	return b.String()
+}
+
+type migrationPlanner struct {
+	from, to   *Schema
+	diff       *SchemaDiff
+	statements []string
+	warnings   []string
+	// rebuiltForeignKeys reference a primary key or unique constraint that
+	// is dropped and added again. They are dropped before it and added back
+	// with the others.
+	rebuiltForeignKeys []ForeignKey
+}
+
+func newMigrationPlanner(from, to *Schema) *migrationPlanner {
+	return &migrationPlanner{from: from, to: to, diff: DiffSchemas(from, to)}
+}
+
+func (p *migrationPlanner) add(format string, args ...any) {
+	p.statements = append(p.statements, fmt.Sprintf(format, args...))
+}
+
+func (p *migrationPlanner) warn(format string, args ...any) {
+	p.warnings = append(p.warnings, fmt.Sprintf(format, args...))
+}
+
+// plan emits the statements in phases: objects that others depend on are
+// created first, dependants are dropped before what they depend on.
+func (p *migrationPlanner) plan() []string {
+	recreatedEnums := p.addEnums()
+	replacedFunctions := p.replacedFunctions()
+	droppedViews := p.dropViews(recreatedEnums, replacedFunctions)
+	p.createFunctions(replacedFunctions)
+	p.dropForeignKeys()
+	p.dropConstraints()
+	p.dropTables()
+	p.dropColumns()
+	p.recreateEnums(recreatedEnums)
+	p.createTables()
+	p.alterColumns()
+	p.addConstraints()
+	p.addForeignKeys()
+	p.createViews(droppedViews)
+	p.dropFunctionsAndEnums()
+	return p.statements
+}
+
+// addEnums adds values to existing enum types, ahead of everything else so
+// they can run outside the transaction, and creates new ones. Enums losing
+// or reordering values can't be altered in place; they are returned to be
+// recreated once the columns using them are settled.
+func (p *migrationPlanner) addEnums() []EnumChange {
+	var recreate []EnumChange
+	var created []EnumChange
+	for _, e := range p.diff.Enums {
+		switch e.Kind {
+		case Added:
+			created = append(created, e)
+		case Changed:
+			kept := slices.DeleteFunc(slices.Clone(e.To.Values), func(v string) bool {
+				return slices.Contains(e.AddedValues, v)
+			})
+			if len(e.RemovedValues) > 0 || !slices.Equal(kept, e.From.Values) {
+				recreate = append(recreate, e)
+				continue
+			}
+			for i, v := range e.To.Values {
+				if !slices.Contains(e.AddedValues, v) {
+					continue
+				}
+				// Place the value where it sits in the new type.
+				position := ""
+				if i > 0 {
+					position = " AFTER " + quoteLiteral(e.To.Values[i-1])
+				} else if len(e.To.Values) > 1 {
+					position = " BEFORE " + quoteLiteral(e.To.Values[1])
+				}
+				p.add("ALTER TYPE %s ADD VALUE %s%s;", quoteIdent(e.Name), quoteLiteral(v), position)
+			}
+			p.warn("enum %s: values are added before the transaction and stay when the rest of the migration fails", e.Name)
+		}
+	}
+	for _, e := range created {
+		p.add("%s", strings.TrimSuffix(renderEnum(*e.To), "\n"))
+	}
+	return recreate
+}
+
+// functionReturns matches the return type of a function definition.
+var functionReturns = regexp.MustCompile(`(?is)\bRETURNS\s+(.*?)\s*(?:\bLANGUAGE\b|\bAS\b|\bIMMUTABLE\b|\bSTABLE\b|\bVOLATILE\b|\bSTRICT\b|\bSECURITY\b|\bCOST\b|\bROWS\b|\bPARALLEL\b|\bWINDOW\b|\bCALLED\b|\bSET\b|$)`)
+
+func functionReturnType(definition string) string {
+	if m := functionReturns.FindStringSubmatch(definition); m != nil {
+		return strings.ToLower(strings.Join(strings.Fields(m[1]), " "))
+	}
+	return ""
+}
+
+// replacedFunctions returns the changed functions whose return type
+// changes, which CREATE OR REPLACE refuses. They are dropped and created
+// again.
+func (p *migrationPlanner) replacedFunctions() map[string]bool {
+	replaced := make(map[string]bool)
+	for _, f := range p.diff.Functions {
+		if f.Kind == Changed && functionReturnType(f.From.Definition) != functionReturnType(f.To.Definition) {
+			replaced[f.Name] = true
+		}
+	}
+	return replaced
+}
+
+func (p *migrationPlanner) createFunctions(replaced map[string]bool) {
+	for _, f := range p.diff.Functions {
+		if f.Kind == Removed {
+			continue
+		}
+		if replaced[f.Name] {
+			p.add("DROP FUNCTION %s(%s);", quoteIdent(f.From.Name), f.From.Arguments)
+			p.warnFunctionUsers(*f.From)
+		}
+		p.add("%s;", f.To.Definition)
+	}
+}
+
+// warnFunctionUsers warns about the defaults and checks calling a function
+// that is dropped, which make DROP FUNCTION fail.
+func (p *migrationPlanner) warnFunctionUsers(f Function) {
+	for _, t := range p.from.Tables {
+		for _, col := range t.Columns {
+			if referencesFunction(col.Default, f.Name) {
+				p.warn("%s.%s: default calls %s, whose return type changes; DROP FUNCTION fails until the default is dropped", t.Name, col.ColumnName, f.Name)
+			}
+		}
+		for _, c := range t.Checks {
+			if referencesFunction(c.Expression, f.Name) {
+				p.warn("check constraint %s on %s calls %s, whose return type changes; DROP FUNCTION fails until the check is dropped", c.ConstraintName, t.Name, f.Name)
+			}
+		}
+	}
+}
+
+// dropViews drops removed and changed views, and every view depending on a
+// table that changes, since Postgres refuses to alter columns a view uses.
+// Tables with a column of a recreated enum count as changed, and views
+// calling a replaced function are dropped too. It returns the names of the
+// dropped views.
+func (p *migrationPlanner) dropViews(recreatedEnums []EnumChange, replacedFunctions map[string]bool) map[string]bool {
+	dropped := make(map[string]bool)
+	for _, v := range p.diff.Views {
+		if v.Kind != Added {
+			dropped[v.Name] = true
+		}
+	}
+	for _, v := range p.from.Views {
+		for _, f := range p.diff.Functions {
+			if replacedFunctions[f.Name] && referencesFunction(v.Definition, f.From.Name) {
+				dropped[v.Name] = true
+			}
+		}
+	}
+	changedTables := make(map[string]bool)
+	for _, t := range p.diff.Tables {
+		changedTables[t.Name] = true
+	}
+	for _, e := range recreatedEnums {
+		for _, t := range p.from.Tables {
+			for _, col := range t.Columns {
+				if col.ElementType() == e.Name {
+					changedTables[t.Name] = true
+				}
+			}
+		}
+	}
+	for grew := true; grew; {
+		grew = false
+		for _, v := range p.from.Views {
+			if dropped[v.Name] {
+				continue
+			}
+			for _, dep := range v.DependsOn {
+				if changedTables[dep] || dropped[dep] {
+					dropped[v.Name] = true
+					grew = true
+					break
+				}
+			}
+		}
+	}
+
+	// Drop dependants first, the reverse of the creation order.
+	order := OrderSchema(p.from)
+	for i := len(order.Objects) - 1; i >= 0; i-- {
+		obj := order.Objects[i]
+		if obj.Kind != KindView || !dropped[p.from.Views[obj.Index].Name] {
+			continue
+		}
+		v := p.from.Views[obj.Index]
+		kind := "VIEW"
+		if v.Materialized {
+			kind = "MATERIALIZED VIEW"
+		}
+		p.add("DROP %s %s;", kind, quoteIdent(v.Name))
+	}
+	return dropped
+}
+
+func (p *migrationPlanner) dropForeignKeys() {
+	for _, t := range p.diff.Tables {
+		for _, fk := range t.ForeignKeys {
+			if fk.Kind != Added {
+				p.add("ALTER TABLE %s DROP CONSTRAINT %s;", quoteIdent(t.Name), quoteIdent(fk.Name))
+			}
+		}
+	}
+	p.rebuiltForeignKeys = p.foreignKeysOnRebuiltKeys()
+	for _, fk := range p.rebuiltForeignKeys {
+		p.add("ALTER TABLE %s DROP CONSTRAINT %s;", quoteIdent(fk.SourceTable), quoteIdent(fk.ConstraintName))
+	}
+}
+
+// foreignKeysOnRebuiltKeys returns the unchanged foreign keys referencing a
+// primary key, unique constraint or unique index that is dropped. Postgres
+// won't drop a key a foreign key depends on, so they are dropped first and
+// added again once the key is back.
+func (p *migrationPlanner) foreignKeysOnRebuiltKeys() []ForeignKey {
+	// dropped holds the column lists of the dropped keys, by table.
+	dropped := make(map[string][][]string)
+	for _, t := range p.diff.Tables {
+		if t.Kind != Changed {
+			continue
+		}
+		if pk := t.PrimaryKey; pk != nil && pk.Kind != Added {
+			dropped[t.Name] = append(dropped[t.Name], pk.From.Columns)
+		}
+		for _, u := range t.Uniques {
+			if u.Kind != Added {
+				dropped[t.Name] = append(dropped[t.Name], u.From.Columns)
+			}
+		}
+		for _, idx := range t.Indexes {
+			if idx.Kind != Added && idx.From.Unique {
+				dropped[t.Name] = append(dropped[t.Name], idx.From.Columns)
+			}
+		}
+	}
+	var fks []ForeignKey
+	for _, t := range p.from.Tables {
+		change := p.tableChange(t.Name)
+		if change != nil && change.Kind == Removed {
+			continue
+		}
+		for _, fk := range t.ForeignKeys {
+			if change != nil && slices.ContainsFunc(change.ForeignKeys, func(c Change[ForeignKey]) bool { return c.Name == fk.ConstraintName }) {
+				// Dropped anyway as a changed or removed foreign key.
+				continue
+			}
+			if slices.ContainsFunc(dropped[fk.TargetTable], func(columns []string) bool { return sameColumns(columns, fk.TargetColumns) }) {
+				fks = append(fks, fk)
+			}
+		}
+	}
+	return fks
+}
+
+func (p *migrationPlanner) tableChange(name string) *TableChange {
+	for i := range p.diff.Tables {
+		if p.diff.Tables[i].Name == name {
+			return &p.diff.Tables[i]
+		}
+	}
+	return nil
+}
+
+// sameColumns reports whether both lists hold the same columns, in any
+// order, as a key matches a foreign key.
+func sameColumns(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, col := range a {
+		if !slices.Contains(b, col) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *migrationPlanner) dropConstraints() {
+	for _, t := range p.diff.Tables {
+		table := quoteIdent(t.Name)
+		for _, idx := range t.Indexes {
+			if idx.Kind != Added {
+				p.add("DROP INDEX %s;", quoteIdent(idx.Name))
+			}
+		}
+		for _, c := range t.Checks {
+			if c.Kind != Added {
+				p.add("ALTER TABLE %s DROP CONSTRAINT %s;", table, quoteIdent(c.Name))
+			}
+		}
+		for _, u := range t.Uniques {
+			if u.Kind != Added {
+				p.add("ALTER TABLE %s DROP CONSTRAINT %s;", table, quoteIdent(u.Name))
+			}
+		}
+		if pk := t.PrimaryKey; pk != nil && pk.Kind != Added {
+			p.add("ALTER TABLE %s DROP CONSTRAINT %s;", table, quoteIdent(pk.From.ConstraintName))
+		}
+	}
+}
+
+func (p *migrationPlanner) dropTables() {
+	var names []string
+	for _, t := range p.diff.Tables {
+		if t.Kind == Removed {
+			names = append(names, quoteIdent(t.Name))
+		}
+	}
+	// Dropping them together lets Postgres resolve the references between
+	// the dropped tables.
+	if len(names) > 0 {
+		p.add("DROP TABLE %s;", strings.Join(names, ", "))
+	}
+}
+
+func (p *migrationPlanner) dropColumns() {
+	for _, t := range p.diff.Tables {
+		for _, c := range t.Columns {
+			if c.Kind == Removed {
+				p.add("ALTER TABLE %s DROP COLUMN %s;", quoteIdent(t.Name), quoteIdent(c.Name))
+			}
+		}
+	}
+}
+
+// recreateEnums swaps an enum type for a new one: the old type is renamed,
+// the new one created, the remaining columns cast over through text and the
+// old type dropped.
+func (p *migrationPlanner) recreateEnums(enums []EnumChange) {
+	for _, e := range enums {
+		old := e.Name + "_old"
+		p.add("ALTER TYPE %s RENAME TO %s;", quoteIdent(e.Name), quoteIdent(old))
+		p.add("%s", strings.TrimSuffix(renderEnum(*e.To), "\n"))
+		for _, t := range p.to.Tables {
+			fromTable := p.from.Table(t.Name)
+			if fromTable == nil {
+				continue
+			}
+			for _, col := range t.Columns {
+				fromCol := fromTable.Column(col.ColumnName)
+				if fromCol == nil || col.ElementType() != e.Name || fromCol.ElementType() != e.Name {
+					continue
+				}
+				table, column := quoteIdent(t.Name), quoteIdent(col.ColumnName)
+				if fromCol.Default != "" {
+					p.add("ALTER TABLE %s ALTER COLUMN %s DROP DEFAULT;", table, column)
+				}
+				textType := "text"
+				if col.IsArray() {
+					textType = "text[]"
+				}
+				for _, v := range e.RemovedValues {
+					p.warn("%s.%s: rows holding the removed value %s will fail the cast", t.Name, col.ColumnName, v)
+				}
+				p.add("ALTER TABLE %s ALTER COLUMN %s TYPE %s USING %s::%s::%s;",
+					table, column, col.FullType, column, textType, col.FullType)
+				if col.Default != "" {
+					p.add("ALTER TABLE %s ALTER COLUMN %s SET DEFAULT %s;", table, column, col.Default)
+				}
+			}
+		}
+		p.add("DROP TYPE %s;", quoteIdent(old))
+	}
+}
+
+// createTables creates the added tables without their foreign keys, which
+// are added with the others once every table exists.
+func (p *migrationPlanner) createTables() {
+	for _, t := range p.diff.Tables {
+		if t.Kind != Added {
+			continue
+		}
+		order := &CreationOrder{DeferredForeignKeys: t.To.ForeignKeys}
+		p.add("%s", strings.TrimSuffix(renderTable(*t.To, order), "\n"))
+	}
+}
+
+func (p *migrationPlanner) alterColumns() {
+	for _, t := range p.diff.Tables {
+		table := quoteIdent(t.Name)
+		if t.Kind == Changed && t.CommentChanged {
+			p.add("COMMENT ON TABLE %s IS %s;", table, commentValue(t.To.Comment))
+		}
+		for _, c := range t.Columns {
+			switch c.Kind {
+			case Added:
+				if !c.To.Nullable() && c.To.Default == "" && !c.To.IsGenerated() {
+					p.warn("%s.%s: adding a NOT NULL column without a default fails on a table with rows", t.Name, c.Name)
+				}
+				p.add("ALTER TABLE %s ADD COLUMN %s;", table, renderColumn(*c.To))
+				if c.To.Comment != "" {
+					p.add("COMMENT ON COLUMN %s.%s IS %s;", table, quoteIdent(c.Name), quoteLiteral(c.To.Comment))
+				}
+			case Changed:
+				p.alterColumn(t.Name, c)
+			}
+		}
+	}
+}
+
+func (p *migrationPlanner) alterColumn(tableName string, c ColumnChange) {
+	from, to := c.From, c.To
+	prefix := fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s", quoteIdent(tableName), quoteIdent(c.Name))
+	changed := func(field string) bool { return slices.Contains(c.Fields, field) }
+
+	typeChanged := changed("type")
+	// A default typed for the old column type has to go before the type
+	// changes, it is set again afterwards.
+	resetDefault := changed("default") || typeChanged && from.Default != ""
+
+	if resetDefault && from.Default != "" {
+		p.add("%s DROP DEFAULT;", prefix)
+	}
+	if changed("identity") && from.IsIdentity {
+		if to.IsIdentity {
+			p.add("%s SET GENERATED %s;", prefix, to.IdentityGeneration)
+		} else {
+			p.add("%s DROP IDENTITY;", prefix)
+		}
+	}
+	if typeChanged {
+		using := fmt.Sprintf("%s::%s", quoteIdent(c.Name), to.FullType)
+		if e := p.to.Enum(to.ElementType()); e != nil && to.ElementType() != from.ElementType() {
+			textType := "text"
+			if to.IsArray() {
+				textType = "text[]"
+			}
+			using = fmt.Sprintf("%s::%s::%s", quoteIdent(c.Name), textType, to.FullType)
+		}
+		p.add("%s TYPE %s USING %s;", prefix, to.FullType, using)
+	}
+	if changed("identity") && !from.IsIdentity && to.IsIdentity {
+		p.add("%s ADD GENERATED %s AS IDENTITY;", prefix, to.IdentityGeneration)
+	}
+	if changed("nullable") {
+		if to.Nullable() {
+			p.add("%s DROP NOT NULL;", prefix)
+		} else {
+			p.add("%s SET NOT NULL;", prefix)
+		}
+	}
+	if resetDefault && to.Default != "" {
+		p.add("%s SET DEFAULT %s;", prefix, to.Default)
+	}
+	if changed("comment") {
+		p.add("COMMENT ON COLUMN %s.%s IS %s;", quoteIdent(tableName), quoteIdent(c.Name), commentValue(to.Comment))
+	}
+}
+
+func (p *migrationPlanner) addConstraints() {
+	for _, t := range p.diff.Tables {
+		if t.Kind != Changed {
+			continue
+		}
+		table := quoteIdent(t.Name)
+		if pk := t.PrimaryKey; pk != nil && pk.Kind != Removed {
+			p.add("ALTER TABLE %s ADD CONSTRAINT %s PRIMARY KEY (%s);", table,
+				quoteIdent(pk.To.ConstraintName), quoteIdents(pk.To.Columns))
+		}
+		for _, u := range t.Uniques {
+			if u.Kind != Removed {
+				p.add("ALTER TABLE %s ADD CONSTRAINT %s UNIQUE (%s);", table, quoteIdent(u.Name), quoteIdents(u.To.Columns))
+			}
+		}
+		for _, c := range t.Checks {
+			if c.Kind != Removed {
+				p.add("ALTER TABLE %s ADD CONSTRAINT %s %s;", table, quoteIdent(c.Name), checkClause(c.To.Expression))
+			}
+		}
+		for _, idx := range t.Indexes {
+			if idx.Kind != Removed {
+				p.add("%s;", idx.To.Definition)
+			}
+		}
+	}
+}
+
+func (p *migrationPlanner) addForeignKeys() {
+	for _, t := range p.diff.Tables {
+		switch t.Kind {
+		case Added:
+			for _, fk := range t.To.ForeignKeys {
+				p.add("ALTER TABLE %s ADD %s;", quoteIdent(t.Name), renderForeignKey(fk))
+			}
+		case Changed:
+			for _, fk := range t.ForeignKeys {
+				if fk.Kind != Removed {
+					p.add("ALTER TABLE %s ADD %s;", quoteIdent(t.Name), renderForeignKey(*fk.To))
+				}
+			}
+		}
+	}
+	for _, fk := range p.rebuiltForeignKeys {
+		p.add("ALTER TABLE %s ADD %s;", quoteIdent(fk.SourceTable), renderForeignKey(fk))
+	}
+}
+
+// createViews creates added and changed views and recreates the ones
+// dropped because a table below them changed.
+func (p *migrationPlanner) createViews(dropped map[string]bool) {
+	order := OrderSchema(p.to)
+	for _, obj := range order.Objects {
+		if obj.Kind != KindView {
+			continue
+		}
+		v := p.to.Views[obj.Index]
+		existed := slices.ContainsFunc(p.from.Views, func(old View) bool { return old.Name == v.Name })
+		if !existed || dropped[v.Name] {
+			p.add("%s", strings.TrimSuffix(renderView(v), "\n"))
+		}
+	}
+}
+
+func (p *migrationPlanner) dropFunctionsAndEnums() {
+	for _, f := range p.diff.Functions {
+		if f.Kind == Removed {
+			p.add("DROP FUNCTION %s(%s);", quoteIdent(f.From.Name), f.From.Arguments)
+		}
+	}
+	for _, e := range p.diff.Enums {
+		if e.Kind == Removed {
+			p.add("DROP TYPE %s;", quoteIdent(e.Name))
+		}
+	}
+}
+
+func commentValue(comment string) string {
+	if comment == "" {
+		return "NULL"
+	}
+	return quoteLiteral(comment)
+}
+
+// VerifyMigration checks a plan against a scratch database: the from schema
+// is created, the up migration applied and the result compared with to,
+// then the down migration applied and the result compared with from. Each
+// migration runs as its script does, the enum values it adds first and the
+// rest in a transaction, so the scratch database has to be empty and is
+// emptied again afterwards.
+func VerifyMigration(db *sql.DB, from, to *Schema, plan *MigrationPlan) (err error) {
+	existing, err := IntrospectPostgres(db, DefaultSchema)
+	if err != nil {
+		return err
+	}
+	if len(existing.Tables) > 0 || len(existing.Views) > 0 || len(existing.Enums) > 0 || len(existing.Functions) > 0 {
+		return fmt.Errorf("scratch database is not empty")
+	}
+	defer func() {
+		if cleanupErr := emptyScratch(db); err == nil {
+			err = cleanupErr
+		}
+	}()
+
+	var setup strings.Builder
+	if _, err := WriteDDL(&setup, from, "postgres"); err != nil {
+		return err
+	}
+	steps := []struct {
+		name       string
+		statements []string
+		expected   *Schema
+	}{
+		{"create starting schema", []string{setup.String()}, from},
+		{"apply up migration", plan.Up, to},
+		{"apply down migration", plan.Down, from},
+	}
+	for _, step := range steps {
+		if err := applyMigration(db, step.statements); err != nil {
+			return fmt.Errorf("failed to %s: %w", step.name, err)
+		}
+		actual, err := IntrospectPostgres(db, DefaultSchema)
+		if err != nil {
+			return err
+		}
+		if diff := DiffSchemas(step.expected, actual); !diff.Empty() {
+			return fmt.Errorf("schema after the step %q differs from the expected one:\n%s", step.name, diff.Report())
+		}
+	}
+	return nil
+}
+
+// applyMigration runs the enum values added first on their own, then the
+// remaining statements in a transaction, like the script of transactionScript.
+func applyMigration(db *sql.DB, statements []string) error {
+	values, rest := splitEnumValues(statements)
+	for _, stmt := range values {
+		if _, err := db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	if len(rest) == 0 {
+		return nil
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(strings.Join(rest, "\n")); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// emptyScratch drops everything VerifyMigration created in the scratch
+// database.
+func emptyScratch(db *sql.DB) error {
+	schema, err := IntrospectPostgres(db, DefaultSchema)
+	if err != nil {
+		return err
+	}
+	var statements []string
+	for _, v := range schema.Views {
+		kind := "VIEW"
+		if v.Materialized {
+			kind = "MATERIALIZED VIEW"
+		}
+		statements = append(statements, fmt.Sprintf("DROP %s IF EXISTS %s CASCADE;", kind, quoteIdent(v.Name)))
+	}
+	for _, t := range schema.Tables {
+		statements = append(statements, fmt.Sprintf("DROP TABLE IF EXISTS %s CASCADE;", quoteIdent(t.Name)))
+	}
+	for _, f := range schema.Functions {
+		statements = append(statements, fmt.Sprintf("DROP FUNCTION IF EXISTS %s(%s) CASCADE;", quoteIdent(f.Name), f.Arguments))
+	}
+	for _, e := range schema.Enums {
+		statements = append(statements, fmt.Sprintf("DROP TYPE IF EXISTS %s CASCADE;", quoteIdent(e.Name)))
+	}
+	if len(statements) == 0 {
+		return nil
+	}
+	if _, err := db.Exec(strings.Join(statements, "\n")); err != nil {
+		return fmt.Errorf("failed to empty the scratch database: %w", err)
+	}
+	return nil
+}
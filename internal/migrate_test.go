@@ -0,0 +1,230 @@
+package internal
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPlanMigration(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(s *Schema)
+		up     []string
+		down   []string
+	}{
+		{
+			name:   "identical",
+			change: func(s *Schema) {},
+		},
+		{
+			name:   "add nullable column",
+			change: addColumn("posts", column("note", "text", true)),
+			up:     []string{"ALTER TABLE posts ADD COLUMN note text NULL;"},
+			down:   []string{"ALTER TABLE posts DROP COLUMN note;"},
+		},
+		{
+			name: "widen column type",
+			change: alterColumn("posts", "rating", func(c *Column) {
+				c.DataType, c.UDTName, c.FullType = "bigint", "int8", "bigint"
+			}),
+			up:   []string{"ALTER TABLE posts ALTER COLUMN rating TYPE bigint USING rating::bigint;"},
+			down: []string{"ALTER TABLE posts ALTER COLUMN rating TYPE integer USING rating::integer;"},
+		},
+		{
+			name:   "make column required",
+			change: alterColumn("users", "name", func(c *Column) { c.IsNullable = "NO" }),
+			up:     []string{"ALTER TABLE users ALTER COLUMN name SET NOT NULL;"},
+			down:   []string{"ALTER TABLE users ALTER COLUMN name DROP NOT NULL;"},
+		},
+		{
+			name: "add function check",
+			change: alterTable("users", func(t *Table) {
+				t.Checks = append(t.Checks, CheckConstraint{ConstraintName: "users_bio_check", Expression: "valid_bio(bio)"})
+			}),
+			up:   []string{"ALTER TABLE users ADD CONSTRAINT users_bio_check CHECK (valid_bio(bio));"},
+			down: []string{"ALTER TABLE users DROP CONSTRAINT users_bio_check;"},
+		},
+		{
+			name: "add index",
+			change: alterTable("posts", func(t *Table) {
+				t.Indexes = append(t.Indexes, Index{Name: "posts_title_idx", Columns: []string{"title"}, Method: "btree",
+					Definition: "CREATE INDEX posts_title_idx ON public.posts USING btree (title)"})
+			}),
+			up:   []string{"CREATE INDEX posts_title_idx ON public.posts USING btree (title);"},
+			down: []string{"DROP INDEX posts_title_idx;"},
+		},
+		{
+			name:   "drop foreign key",
+			change: alterTable("posts", func(t *Table) { t.ForeignKeys = t.ForeignKeys[:1] }),
+			up:     []string{"ALTER TABLE posts DROP CONSTRAINT posts_editor_id_fkey;"},
+			down: []string{"ALTER TABLE posts ADD CONSTRAINT posts_editor_id_fkey FOREIGN KEY (editor_id) " +
+				"REFERENCES users(id) ON DELETE SET NULL;"},
+		},
+		{
+			name: "primary key moved to another column",
+			change: alterTable("users", func(t *Table) {
+				t.PrimaryKey = &PrimaryKey{ConstraintName: "users_pkey", Columns: []string{"email"}}
+				t.Uniques = []UniqueConstraint{{ConstraintName: "users_id_key", Columns: []string{"id"}}}
+			}),
+			up: []string{
+				"ALTER TABLE posts DROP CONSTRAINT posts_author_id_fkey;",
+				"ALTER TABLE posts DROP CONSTRAINT posts_editor_id_fkey;",
+				"ALTER TABLE users DROP CONSTRAINT users_email_key;",
+				"ALTER TABLE users DROP CONSTRAINT users_pkey;",
+				"ALTER TABLE users ADD CONSTRAINT users_pkey PRIMARY KEY (email);",
+				"ALTER TABLE users ADD CONSTRAINT users_id_key UNIQUE (id);",
+				"ALTER TABLE posts ADD CONSTRAINT posts_author_id_fkey FOREIGN KEY (author_id) REFERENCES users(id) ON DELETE CASCADE;",
+				"ALTER TABLE posts ADD CONSTRAINT posts_editor_id_fkey FOREIGN KEY (editor_id) REFERENCES users(id) ON DELETE SET NULL;",
+			},
+			down: []string{
+				"ALTER TABLE posts DROP CONSTRAINT posts_author_id_fkey;",
+				"ALTER TABLE posts DROP CONSTRAINT posts_editor_id_fkey;",
+				"ALTER TABLE users DROP CONSTRAINT users_id_key;",
+				"ALTER TABLE users DROP CONSTRAINT users_pkey;",
+				"ALTER TABLE users ADD CONSTRAINT users_pkey PRIMARY KEY (id);",
+				"ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);",
+				"ALTER TABLE posts ADD CONSTRAINT posts_author_id_fkey FOREIGN KEY (author_id) REFERENCES users(id) ON DELETE CASCADE;",
+				"ALTER TABLE posts ADD CONSTRAINT posts_editor_id_fkey FOREIGN KEY (editor_id) REFERENCES users(id) ON DELETE SET NULL;",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := PlanMigration(blogSchema(), blogSchemaWith(tt.change))
+			if !slices.Equal(plan.Up, tt.up) {
+				t.Errorf("up = %q, want %q", plan.Up, tt.up)
+			}
+			if !slices.Equal(plan.Down, tt.down) {
+				t.Errorf("down = %q, want %q", plan.Down, tt.down)
+			}
+			if plan.Empty() != (len(tt.up) == 0) {
+				t.Errorf("Empty() = %v", plan.Empty())
+			}
+		})
+	}
+}
+
+func TestPlanMigrationOrder(t *testing.T) {
+	plan := PlanMigration(blogSchema(), blogSchemaWith(addTable(commentsTable())))
+	if len(plan.Up) != 2 || !strings.HasPrefix(plan.Up[0], "CREATE TABLE comments") ||
+		plan.Up[1] != "ALTER TABLE comments ADD CONSTRAINT comments_post_id_fkey FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE;" {
+		t.Errorf("up = %q, want the table created before its foreign key", plan.Up)
+	}
+	if !slices.Equal(plan.Down, []string{"DROP TABLE comments;"}) {
+		t.Errorf("down = %q", plan.Down)
+	}
+	if script := plan.UpSQL(); !strings.HasPrefix(script, "BEGIN;\n") || !strings.HasSuffix(script, "COMMIT;\n") {
+		t.Errorf("UpSQL() is not a single transaction:\n%s", script)
+	}
+}
+
+func TestPlanMigrationEnumValues(t *testing.T) {
+	plan := PlanMigration(blogSchema(), blogSchemaWith(
+		addEnumValues("post_status", "archived"),
+		func(s *Schema) {
+			s.Enums = append(s.Enums, Enum{Name: "visibility", Values: []string{"public", "private"}})
+		},
+	))
+	want := "ALTER TYPE post_status ADD VALUE 'archived' AFTER 'published';\n\n" +
+		"BEGIN;\n\nCREATE TYPE visibility AS ENUM ('public', 'private');\n\nCOMMIT;\n"
+	if got := plan.UpSQL(); got != want {
+		t.Errorf("UpSQL() = %q, want %q", got, want)
+	}
+	if !slices.ContainsFunc(plan.Warnings, func(w string) bool { return strings.Contains(w, "added before the transaction") }) {
+		t.Errorf("warnings %q don't mention the values added outside the transaction", plan.Warnings)
+	}
+}
+
+func TestPlanMigrationRebuildsForeignKeys(t *testing.T) {
+	// The unique constraint on users.email becomes a unique index, while an
+	// unchanged foreign key references the column.
+	withEmailReference := func(s *Schema) {
+		addColumn("posts", column("author_email", "text", true))(s)
+		alterTable("posts", func(t *Table) {
+			t.ForeignKeys = append(t.ForeignKeys, ForeignKey{ConstraintName: "posts_author_email_fkey", SourceTable: "posts",
+				SourceColumns: []string{"author_email"}, TargetTable: "users", TargetColumns: []string{"email"}})
+		})(s)
+	}
+	from := blogSchemaWith(withEmailReference)
+	to := blogSchemaWith(withEmailReference, alterTable("users", func(t *Table) {
+		t.Uniques = nil
+		t.Indexes = append(t.Indexes, Index{Name: "users_email_idx", Columns: []string{"email"}, Unique: true, Method: "btree",
+			Definition: "CREATE UNIQUE INDEX users_email_idx ON public.users USING btree (email)"})
+	}))
+	plan := PlanMigration(from, to)
+	want := []string{
+		"ALTER TABLE posts DROP CONSTRAINT posts_author_email_fkey;",
+		"ALTER TABLE users DROP CONSTRAINT users_email_key;",
+		"CREATE UNIQUE INDEX users_email_idx ON public.users USING btree (email);",
+		"ALTER TABLE posts ADD CONSTRAINT posts_author_email_fkey FOREIGN KEY (author_email) REFERENCES users(email);",
+	}
+	if !slices.Equal(plan.Up, want) {
+		t.Errorf("up = %q, want %q", plan.Up, want)
+	}
+	if plan.Down[0] != "ALTER TABLE posts DROP CONSTRAINT posts_author_email_fkey;" ||
+		plan.Down[len(plan.Down)-1] != "ALTER TABLE posts ADD CONSTRAINT posts_author_email_fkey FOREIGN KEY (author_email) REFERENCES users(email);" {
+		t.Errorf("down = %q, want the foreign key dropped first and added last", plan.Down)
+	}
+}
+
+func TestPlanMigrationRecreatedEnumViews(t *testing.T) {
+	withView := func(s *Schema) {
+		s.Views = []View{{Name: "drafts", Definition: " SELECT id, status FROM posts WHERE status = 'draft'::post_status", DependsOn: []string{"posts"}}}
+	}
+	from := blogSchemaWith(withView)
+	to := blogSchemaWith(withView, func(s *Schema) { s.Enum("post_status").Values = []string{"published", "draft"} })
+	plan := PlanMigration(from, to)
+	want := []string{
+		"DROP VIEW drafts;",
+		"ALTER TYPE post_status RENAME TO post_status_old;",
+		"CREATE TYPE post_status AS ENUM ('published', 'draft');",
+		"ALTER TABLE posts ALTER COLUMN status DROP DEFAULT;",
+		"ALTER TABLE posts ALTER COLUMN status TYPE post_status USING status::text::post_status;",
+		"ALTER TABLE posts ALTER COLUMN status SET DEFAULT 'draft'::post_status;",
+		"DROP TYPE post_status_old;",
+		"CREATE VIEW drafts AS\nSELECT id, status FROM posts WHERE status = 'draft'::post_status;",
+	}
+	if !slices.Equal(plan.Up, want) {
+		t.Errorf("up = %q, want %q", plan.Up, want)
+	}
+}
+
+func TestPlanMigrationFunctionReturnType(t *testing.T) {
+	withFunction := func(returns string) func(s *Schema) {
+		return func(s *Schema) {
+			s.Functions = []Function{{Name: "post_count", Arguments: "author bigint",
+				Definition: "CREATE OR REPLACE FUNCTION public.post_count(author bigint)\n RETURNS " + returns + "\n LANGUAGE sql\nAS $function$ SELECT count(*) FROM posts WHERE author_id = author $function$"}}
+			s.Views = []View{{Name: "authors", Definition: " SELECT id, post_count(id) AS posts FROM users", DependsOn: []string{"users"}}}
+		}
+	}
+	from := blogSchemaWith(withFunction("integer"))
+	plan := PlanMigration(from, blogSchemaWith(withFunction("bigint")))
+	if len(plan.Up) != 4 || plan.Up[0] != "DROP VIEW authors;" || plan.Up[1] != "DROP FUNCTION post_count(author bigint);" ||
+		!strings.HasPrefix(plan.Up[2], "CREATE OR REPLACE FUNCTION public.post_count(author bigint)\n RETURNS bigint") ||
+		!strings.HasPrefix(plan.Up[3], "CREATE VIEW authors AS") {
+		t.Errorf("up = %q, want the view and function dropped before the function is created", plan.Up)
+	}
+
+	// Only the body changes: CREATE OR REPLACE is enough.
+	body := blogSchemaWith(withFunction("integer"), func(s *Schema) {
+		s.Functions[0].Definition = strings.Replace(s.Functions[0].Definition, "count(*)", "count(id)", 1)
+	})
+	plan = PlanMigration(from, body)
+	if len(plan.Up) != 1 || !strings.HasPrefix(plan.Up[0], "CREATE OR REPLACE FUNCTION") {
+		t.Errorf("up = %q, want only CREATE OR REPLACE", plan.Up)
+	}
+}
+
+func TestSplitEnumValues(t *testing.T) {
+	statements := []string{
+		"ALTER TYPE post_status ADD VALUE 'archived';",
+		"alter type mood add value 'calm' BEFORE 'happy';",
+		"ALTER TABLE posts ADD COLUMN note text NULL;",
+		"ALTER TYPE post_status ADD VALUE 'hidden';",
+	}
+	values, rest := splitEnumValues(statements)
+	if !slices.Equal(values, statements[:2]) || !slices.Equal(rest, statements[2:]) {
+		t.Errorf("splitEnumValues() = %q, %q", values, rest)
+	}
+}
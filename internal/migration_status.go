@@ -0,0 +1,437 @@
+package internal
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// AppliedMigration is one row of a migration history table.
+type AppliedMigration struct {
+	Version   string     `json:"version"`
+	Name      string     `json:"name,omitempty"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// MigrationStatus compares the history table of a migration tool with a
+// local migrations directory.
+type MigrationStatus struct {
+	Tool    string             `json:"tool"`
+	Table   string             `json:"table"`
+	Applied []AppliedMigration `json:"applied"`
+	// Dirty is set when golang-migrate failed half way through a migration.
+	Dirty bool `json:"dirty,omitempty"`
+	// Pending, Missing and OutOfOrder are only filled when a migrations
+	// directory is given. Missing migrations were applied but have no local
+	// file, out of order ones are not applied although a later version is.
+	Pending    []string `json:"pending,omitempty"`
+	Missing    []string `json:"missing,omitempty"`
+	OutOfOrder []string `json:"out_of_order,omitempty"`
+}
+
+// DetectMigrationTools returns the migration tools whose history tables
+// exist in the database, as tool names like "goose" or "django".
+func DetectMigrationTools(db Queryer) ([]string, error) {
+	var tables []string
+	for table := range migrationHistoryTables {
+		tables = append(tables, table)
+	}
+	rows, err := db.Query(`
+        SELECT t.table_name,
+               EXISTS (
+                   SELECT 1 FROM information_schema.columns AS c
+                   WHERE c.table_schema = t.table_schema
+                     AND c.table_name = t.table_name
+                     AND c.column_name = 'dirty'
+               )
+        FROM information_schema.tables AS t
+        WHERE t.table_schema = $1
+          AND t.table_name = ANY($2)
+        ORDER BY t.table_name;
+    `, DefaultSchema, pq.Array(tables))
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up migration tables: %w", err)
+	}
+	defer rows.Close()
+
+	var tools []string
+	for rows.Next() {
+		var table string
+		var dirty bool
+		if err := rows.Scan(&table, &dirty); err != nil {
+			return nil, fmt.Errorf("failed to look up migration tables: %w", err)
+		}
+		tool := migrationHistoryTables[table]
+		// Rails shares the schema_migrations name but has no dirty flag.
+		if table == "schema_migrations" && !dirty {
+			tool = "rails"
+		}
+		tools = append(tools, tool)
+	}
+	return tools, rows.Err()
+}
+
+// ReadMigrationStatus reads the applied migrations of a tool and, when dir
+// is not empty, compares them with the migrations in that directory.
+func ReadMigrationStatus(db Queryer, tool, dir string) (*MigrationStatus, error) {
+	status := &MigrationStatus{Tool: tool, Applied: []AppliedMigration{}}
+	var err error
+	switch tool {
+	case "golang-migrate":
+		status.Table = "schema_migrations"
+		err = readGolangMigrateHistory(db, status)
+	case "rails":
+		status.Table = "schema_migrations"
+		err = readHistory(db, status, `SELECT version, '', NULL::timestamptz FROM schema_migrations ORDER BY version`)
+	case "goose":
+		status.Table = "goose_db_version"
+		err = readGooseHistory(db, status)
+	case "flyway":
+		status.Table = "flyway_schema_history"
+		err = readFlywayHistory(db, status)
+	case "alembic":
+		status.Table = "alembic_version"
+		err = readHistory(db, status, `SELECT version_num, '', NULL::timestamptz FROM alembic_version ORDER BY version_num`)
+	case "django":
+		status.Table = "django_migrations"
+		err = readHistory(db, status, `SELECT app || '/' || name, '', applied FROM django_migrations ORDER BY id`)
+	default:
+		return nil, fmt.Errorf("unsupported migration tool %s", tool)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", status.Table, err)
+	}
+	if dir == "" {
+		return status, nil
+	}
+	if tool == "alembic" {
+		err = compareAlembic(status, dir)
+	} else {
+		err = compareMigrations(status, dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+func readHistory(db Queryer, status *MigrationStatus, query string) error {
+	rows, err := db.Query(query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var m AppliedMigration
+		var appliedAt sql.NullTime
+		if err := rows.Scan(&m.Version, &m.Name, &appliedAt); err != nil {
+			return err
+		}
+		if appliedAt.Valid {
+			m.AppliedAt = &appliedAt.Time
+		}
+		status.Applied = append(status.Applied, m)
+	}
+	return rows.Err()
+}
+
+// readGolangMigrateHistory reads the single row golang-migrate keeps: the
+// current version and whether it failed half way.
+func readGolangMigrateHistory(db Queryer, status *MigrationStatus) error {
+	rows, err := db.Query(`SELECT version::text, dirty FROM schema_migrations`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var m AppliedMigration
+		if err := rows.Scan(&m.Version, &status.Dirty); err != nil {
+			return err
+		}
+		status.Applied = append(status.Applied, m)
+	}
+	return rows.Err()
+}
+
+// readGooseHistory replays goose's log of applied and rolled back versions;
+// the latest row of a version decides whether it is applied.
+func readGooseHistory(db Queryer, status *MigrationStatus) error {
+	rows, err := db.Query(`SELECT version_id::text, is_applied, tstamp FROM goose_db_version WHERE version_id <> 0 ORDER BY id`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var m AppliedMigration
+		var applied bool
+		var appliedAt sql.NullTime
+		if err := rows.Scan(&m.Version, &applied, &appliedAt); err != nil {
+			return err
+		}
+		status.Applied = slices.DeleteFunc(status.Applied, func(a AppliedMigration) bool { return a.Version == m.Version })
+		if applied {
+			if appliedAt.Valid {
+				m.AppliedAt = &appliedAt.Time
+			}
+			status.Applied = append(status.Applied, m)
+		}
+	}
+	return rows.Err()
+}
+
+// readFlywayHistory reads the successful versioned migrations; undo
+// migrations remove the version they undid.
+func readFlywayHistory(db Queryer, status *MigrationStatus) error {
+	rows, err := db.Query(`
+        SELECT version, description, type, installed_on
+        FROM flyway_schema_history
+        WHERE success AND version IS NOT NULL
+        ORDER BY installed_rank;
+    `)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var m AppliedMigration
+		var kind string
+		var appliedAt sql.NullTime
+		if err := rows.Scan(&m.Version, &m.Name, &kind, &appliedAt); err != nil {
+			return err
+		}
+		status.Applied = slices.DeleteFunc(status.Applied, func(a AppliedMigration) bool { return a.Version == m.Version })
+		if strings.HasPrefix(kind, "UNDO") {
+			continue
+		}
+		if appliedAt.Valid {
+			m.AppliedAt = &appliedAt.Time
+		}
+		status.Applied = append(status.Applied, m)
+	}
+	return rows.Err()
+}
+
+var (
+	railsFile          = regexp.MustCompile(`^(\d+)_.*\.rb$`)
+	gooseMigrationFile = regexp.MustCompile(`^(\d+)_.*\.(sql|go)$`)
+)
+
+// localMigration is a migration file of a migrations directory. Versions
+// are compared by group, the Django app, and the numbers of the version.
+type localMigration struct {
+	id    string
+	group string
+	order []int64
+}
+
+// localMigrations lists the migrations of dir in the version format the
+// tool stores in its history table.
+func localMigrations(tool, dir string) ([]localMigration, error) {
+	if tool == "django" {
+		return localDjangoMigrations(dir)
+	}
+	patterns := map[string]*regexp.Regexp{
+		"golang-migrate": golangMigrateFile,
+		"rails":          railsFile,
+		"goose":          gooseMigrationFile,
+		"flyway":         flywayVersion,
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+	var migrations []localMigration
+	for _, entry := range entries {
+		m := patterns[tool].FindStringSubmatch(entry.Name())
+		if m == nil {
+			continue
+		}
+		order := versionNumbers(m[1])
+		id := m[1]
+		switch tool {
+		case "flyway":
+			id = strings.ReplaceAll(id, "_", ".")
+		case "goose", "golang-migrate":
+			id = strconv.FormatInt(order[0], 10)
+		}
+		migrations = append(migrations, localMigration{id: id, order: order})
+	}
+	return migrations, nil
+}
+
+// localDjangoMigrations reads either the migrations package of one app or
+// a project directory holding <app>/migrations packages.
+func localDjangoMigrations(dir string) ([]localMigration, error) {
+	dirs, err := filepath.Glob(filepath.Join(dir, "*", "migrations"))
+	if err != nil {
+		return nil, err
+	}
+	if len(dirs) == 0 {
+		dirs = []string{dir}
+	}
+	var migrations []localMigration
+	for _, d := range dirs {
+		abs, err := filepath.Abs(d)
+		if err != nil {
+			return nil, err
+		}
+		app := filepath.Base(filepath.Dir(abs))
+		entries, err := os.ReadDir(d)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migrations directory: %w", err)
+		}
+		for _, entry := range entries {
+			if m := djangoFile.FindStringSubmatch(entry.Name()); m != nil {
+				migrations = append(migrations, localMigration{id: app + "/" + m[1], group: app, order: versionNumbers(m[2])})
+			}
+		}
+	}
+	return migrations, nil
+}
+
+// compareMigrations fills pending, missing and out-of-order migrations for
+// tools with ordered versions.
+func compareMigrations(status *MigrationStatus, dir string) error {
+	local, err := localMigrations(status.Tool, dir)
+	if err != nil {
+		return err
+	}
+	slices.SortFunc(local, func(a, b localMigration) int {
+		if c := strings.Compare(a.group, b.group); c != 0 {
+			return c
+		}
+		return slices.Compare(a.order, b.order)
+	})
+
+	applied := make(map[string]bool)
+	for _, m := range status.Applied {
+		applied[m.Version] = true
+	}
+	// golang-migrate only records the current version, everything up to
+	// it counts as applied.
+	if status.Tool == "golang-migrate" && len(status.Applied) == 1 {
+		current := versionNumbers(status.Applied[0].Version)
+		for _, m := range local {
+			if slices.Compare(m.order, current) <= 0 {
+				applied[m.id] = true
+			}
+		}
+	}
+
+	known := make(map[string]bool)
+	latest := make(map[string][]int64)
+	for _, m := range local {
+		known[m.id] = true
+		if applied[m.id] && slices.Compare(m.order, latest[m.group]) > 0 {
+			latest[m.group] = m.order
+		}
+	}
+	groups := make(map[string]bool)
+	for _, m := range local {
+		groups[m.group] = true
+	}
+	for _, m := range status.Applied {
+		group, _, _ := strings.Cut(m.Version, "/")
+		// Django apps without local migrations, like contrib apps, are
+		// not part of the directory.
+		if status.Tool == "django" && !groups[group] {
+			continue
+		}
+		if !known[m.Version] {
+			status.Missing = append(status.Missing, m.Version)
+		}
+	}
+	for _, m := range local {
+		switch {
+		case applied[m.id]:
+		case slices.Compare(m.order, latest[m.group]) < 0:
+			status.OutOfOrder = append(status.OutOfOrder, m.id)
+		default:
+			status.Pending = append(status.Pending, m.id)
+		}
+	}
+	return nil
+}
+
+// compareAlembic treats every ancestor of the recorded heads as applied.
+func compareAlembic(status *MigrationStatus, dir string) error {
+	revisions, err := alembicRevisions(dir)
+	if err != nil {
+		return err
+	}
+	applied := make(map[string]bool)
+	var visit func(rev string)
+	visit = func(rev string) {
+		if applied[rev] {
+			return
+		}
+		applied[rev] = true
+		for _, parent := range revisions[rev] {
+			visit(parent)
+		}
+	}
+	for _, m := range status.Applied {
+		if _, ok := revisions[m.Version]; !ok {
+			status.Missing = append(status.Missing, m.Version)
+			continue
+		}
+		visit(m.Version)
+	}
+	for rev := range revisions {
+		if !applied[rev] {
+			status.Pending = append(status.Pending, rev)
+		}
+	}
+	slices.Sort(status.Pending)
+	return nil
+}
+
+// Text returns the status as a human readable report.
+func (s *MigrationStatus) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%s): %d applied\n", s.Tool, s.Table, len(s.Applied))
+	if s.Dirty {
+		b.WriteString("  DIRTY: the last migration failed, fix the database and force the version\n")
+	}
+	for _, m := range s.Applied {
+		line := m.Version
+		if m.Name != "" {
+			line += " " + m.Name
+		}
+		if m.AppliedAt != nil {
+			line += "  " + m.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(&b, "  %-12s %s\n", "applied", line)
+	}
+	for _, list := range []struct {
+		label    string
+		versions []string
+	}{
+		{"pending", s.Pending},
+		{"missing", s.Missing},
+		{"out of order", s.OutOfOrder},
+	} {
+		for _, v := range list.versions {
+			fmt.Fprintf(&b, "  %-12s %s\n", list.label, v)
+		}
+	}
+	return b.String()
+}
+
+// MigrationStatusJSON returns the statuses as indented JSON.
+func MigrationStatusJSON(statuses []*MigrationStatus) ([]byte, error) {
+	data, err := json.MarshalIndent(statuses, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode migration status: %w", err)
+	}
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,144 @@
+package internal
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func appliedVersions(versions ...string) []AppliedMigration {
+	applied := []AppliedMigration{}
+	for _, v := range versions {
+		applied = append(applied, AppliedMigration{Version: v})
+	}
+	return applied
+}
+
+func TestCompareMigrations(t *testing.T) {
+	tests := []struct {
+		name       string
+		tool       string
+		files      map[string]string
+		applied    []string
+		pending    []string
+		missing    []string
+		outOfOrder []string
+	}{
+		{
+			name: "golang-migrate current version",
+			tool: "golang-migrate",
+			files: map[string]string{
+				"000001_init.up.sql": "", "000002_users.up.sql": "", "000003_posts.up.sql": "",
+			},
+			applied: []string{"2"},
+			pending: []string{"3"},
+		},
+		{
+			name:       "goose out of order",
+			tool:       "goose",
+			files:      map[string]string{"00001_init.sql": "", "00002_users.sql": "", "00003_posts.go": "", "00004_tags.sql": ""},
+			applied:    []string{"1", "3", "7"},
+			pending:    []string{"4"},
+			missing:    []string{"7"},
+			outOfOrder: []string{"2"},
+		},
+		{
+			name:    "flyway dotted versions",
+			tool:    "flyway",
+			files:   map[string]string{"V1__init.sql": "", "V1_1__users.sql": "", "V1_10__posts.sql": "", "U1_1__users.sql": ""},
+			applied: []string{"1", "1.1"},
+			pending: []string{"1.10"},
+		},
+		{
+			name: "django apps",
+			tool: "django",
+			files: map[string]string{
+				"blog/migrations/0001_initial.py": "", "blog/migrations/0002_posts.py": "",
+				"shop/migrations/0001_initial.py": "", "shop/migrations/__init__.py": "",
+			},
+			applied: []string{"auth/0001_initial", "blog/0001_initial", "blog/0002_posts", "blog/0003_gone"},
+			pending: []string{"shop/0001_initial"},
+			missing: []string{"blog/0003_gone"},
+		},
+		{
+			name: "alembic ancestors",
+			tool: "alembic",
+			files: map[string]string{
+				"1a2b3c_.py": alembicFixture("1a2b3c_.py"), "4d5e6f_1a2b3c.py": alembicFixture("4d5e6f_1a2b3c.py"),
+				"7a8b9c_4d5e6f.py": alembicFixture("7a8b9c_4d5e6f.py"),
+			},
+			applied: []string{"4d5e6f"},
+			pending: []string{"7a8b9c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := migrationsDir(t, tt.files)
+			status := &MigrationStatus{Tool: tt.tool, Applied: appliedVersions(tt.applied...)}
+			var err error
+			if tt.tool == "alembic" {
+				err = compareAlembic(status, dir)
+			} else {
+				err = compareMigrations(status, dir)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(status.Pending, tt.pending) {
+				t.Errorf("pending = %q, want %q", status.Pending, tt.pending)
+			}
+			if !slices.Equal(status.Missing, tt.missing) {
+				t.Errorf("missing = %q, want %q", status.Missing, tt.missing)
+			}
+			if !slices.Equal(status.OutOfOrder, tt.outOfOrder) {
+				t.Errorf("out of order = %q, want %q", status.OutOfOrder, tt.outOfOrder)
+			}
+		})
+	}
+}
+
+func TestCompareDjangoAppMigrations(t *testing.T) {
+	dir := migrationsDir(t, map[string]string{"blog/migrations/0001_initial.py": "", "blog/migrations/__init__.py": ""})
+	app := filepath.Join(dir, "blog", "migrations")
+	status := &MigrationStatus{Tool: "django", Applied: appliedVersions("blog/0001_initial")}
+	if err := compareMigrations(status, app); err != nil {
+		t.Fatal(err)
+	}
+	if status.Pending != nil || status.Missing != nil {
+		t.Errorf("pending = %q, missing = %q, want none", status.Pending, status.Missing)
+	}
+}
+
+func TestMigrationStatusReports(t *testing.T) {
+	applied := time.Date(2026, 9, 30, 8, 15, 0, 0, time.UTC)
+	statuses := []*MigrationStatus{
+		{
+			Tool:  "goose",
+			Table: "goose_db_version",
+			Applied: []AppliedMigration{
+				{Version: "1", AppliedAt: &applied},
+				{Version: "3", AppliedAt: &applied},
+			},
+			Pending:    []string{"4"},
+			OutOfOrder: []string{"2"},
+		},
+		{
+			Tool:    "golang-migrate",
+			Table:   "schema_migrations",
+			Applied: appliedVersions("5"),
+			Dirty:   true,
+			Missing: []string{"5"},
+		},
+	}
+	var text []byte
+	for _, status := range statuses {
+		text = append(text, status.Text()...)
+	}
+	golden(t, "migrations_status/status.txt", text)
+	data, err := MigrationStatusJSON(statuses)
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden(t, "migrations_status/status.json", data)
+}
@@ -0,0 +1,445 @@
+package internal
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// migrationWriters render a migration plan as the files of a migration
+// framework. dir is the framework's migrations directory, scanned for the
+// existing versions so the new files continue the sequence.
+var migrationWriters = map[string]func(dir, name string, plan *MigrationPlan, now time.Time) ([]File, error){
+	"sql":            writePlainMigration,
+	"golang-migrate": writeGolangMigrate,
+	"goose":          writeGoose,
+	"flyway":         writeFlyway,
+	"alembic":        writeAlembic,
+	"django":         writeDjango,
+}
+
+// MigrationFormats lists the values accepted by MigrationFiles.
+var MigrationFormats = []string{"sql", "golang-migrate", "goose", "flyway", "alembic", "django"}
+
+// MigrationFiles renders the plan in the given migration format. The
+// returned file names are relative to dir.
+func MigrationFiles(format, dir, name string, plan *MigrationPlan, now time.Time) ([]File, error) {
+	write, ok := migrationWriters[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported migration format %s", format)
+	}
+	return write(dir, migrationName(name), plan, now)
+}
+
+var nonWordChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// migrationName turns a free form description into the snake_case name
+// the frameworks use in file names.
+func migrationName(name string) string {
+	name = strings.Trim(nonWordChars.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return "schema_update"
+	}
+	return name
+}
+
+func writePlainMigration(dir, name string, plan *MigrationPlan, now time.Time) ([]File, error) {
+	return []File{
+		{Name: "up.sql", Content: []byte(plan.UpSQL())},
+		{Name: "down.sql", Content: []byte(plan.DownSQL())},
+	}, nil
+}
+
+// existingVersions returns the first capture group of pattern for every
+// file name in dir that matches. A missing directory has no versions.
+func existingVersions(dir string, pattern *regexp.Regexp) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+	var versions []string
+	for _, entry := range entries {
+		if m := pattern.FindStringSubmatch(entry.Name()); m != nil {
+			versions = append(versions, m[1])
+		}
+	}
+	return versions, nil
+}
+
+// nextVersion continues a numeric version sequence. Timestamp versions are
+// continued with the current time, sequential ones with the next number,
+// padded like the existing ones. Without existing versions defaultWidth
+// picks between a sequence (width > 0) and a timestamp (width 0).
+func nextVersion(versions []string, defaultWidth int, now time.Time) string {
+	timestamp := now.UTC().Format("20060102150405")
+	if len(versions) == 0 {
+		if defaultWidth == 0 {
+			return timestamp
+		}
+		return fmt.Sprintf("%0*d", defaultWidth, 1)
+	}
+	var highest int64
+	width := 0
+	for _, v := range versions {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			continue
+		}
+		highest = max(highest, n)
+		width = max(width, len(v))
+	}
+	if width >= len(timestamp) {
+		if timestamp > strconv.FormatInt(highest, 10) {
+			return timestamp
+		}
+		return strconv.FormatInt(highest+1, 10)
+	}
+	return fmt.Sprintf("%0*d", width, highest+1)
+}
+
+var golangMigrateFile = regexp.MustCompile(`^(\d+)_.*\.up\.sql$`)
+
+// writeGolangMigrate writes NNNNNN_name.up.sql and NNNNNN_name.down.sql as
+// created by `migrate create -seq -digits 6`.
+func writeGolangMigrate(dir, name string, plan *MigrationPlan, now time.Time) ([]File, error) {
+	versions, err := existingVersions(dir, golangMigrateFile)
+	if err != nil {
+		return nil, err
+	}
+	version := nextVersion(versions, 6, now)
+	return []File{
+		{Name: fmt.Sprintf("%s_%s.up.sql", version, name), Content: []byte(plan.UpSQL())},
+		{Name: fmt.Sprintf("%s_%s.down.sql", version, name), Content: []byte(plan.DownSQL())},
+	}, nil
+}
+
+var gooseFile = regexp.MustCompile(`^(\d+)_.*\.sql$`)
+
+// writeGoose writes a single annotated SQL file. Goose runs every
+// migration in a transaction of its own, so the statements are not wrapped
+// in BEGIN/COMMIT; statements with dollar quoted bodies are fenced so goose
+// doesn't split them on inner semicolons. Enum values can't be added in
+// goose's transaction, so a migration adding some runs with NO TRANSACTION:
+// the values are separate statements and the rest is fenced into a single
+// statement, which Postgres runs as one implicit transaction.
+func writeGoose(dir, name string, plan *MigrationPlan, now time.Time) ([]File, error) {
+	versions, err := existingVersions(dir, gooseFile)
+	if err != nil {
+		return nil, err
+	}
+	version := nextVersion(versions, 0, now)
+
+	upValues, _ := splitEnumValues(plan.Up)
+	downValues, _ := splitEnumValues(plan.Down)
+	noTransaction := len(upValues) > 0 || len(downValues) > 0
+
+	var b strings.Builder
+	section := func(annotation string, statements []string) {
+		fmt.Fprintf(&b, "-- +goose %s\n", annotation)
+		if noTransaction {
+			values, rest := splitEnumValues(statements)
+			for _, stmt := range values {
+				fmt.Fprintf(&b, "%s\n", stmt)
+			}
+			if len(rest) > 0 {
+				fmt.Fprintf(&b, "-- +goose StatementBegin\n%s\n-- +goose StatementEnd\n", strings.Join(rest, "\n"))
+			}
+			return
+		}
+		for _, stmt := range statements {
+			if strings.Contains(stmt, "$") {
+				fmt.Fprintf(&b, "-- +goose StatementBegin\n%s\n-- +goose StatementEnd\n", stmt)
+			} else {
+				fmt.Fprintf(&b, "%s\n", stmt)
+			}
+		}
+	}
+	if noTransaction {
+		b.WriteString("-- +goose NO TRANSACTION\n")
+	}
+	section("Up", plan.Up)
+	b.WriteString("\n")
+	section("Down", plan.Down)
+	return []File{{Name: fmt.Sprintf("%s_%s.sql", version, name), Content: []byte(b.String())}}, nil
+}
+
+var flywayFile = regexp.MustCompile(`^V(\d+)(?:[._]\d+)*__.*\.sql$`)
+
+// writeFlyway writes V<n>__name.sql and the matching U<n>__name.sql undo
+// migration. Flyway wraps each migration in a transaction on Postgres, so
+// enum values added by the up migration go into a versioned migration of
+// their own, configured to run outside a transaction. Values added back by
+// the undo migration come first in it, so it runs outside a transaction as
+// well and wraps the rest in BEGIN/COMMIT itself.
+func writeFlyway(dir, name string, plan *MigrationPlan, now time.Time) ([]File, error) {
+	versions, err := existingVersions(dir, flywayFile)
+	if err != nil {
+		return nil, err
+	}
+	var highest int64
+	for _, v := range versions {
+		n, _ := strconv.ParseInt(v, 10, 64)
+		highest = max(highest, n)
+	}
+	version := highest + 1
+
+	var files []File
+	values, up := splitEnumValues(plan.Up)
+	if len(values) > 0 {
+		script := fmt.Sprintf("V%d__%s_enum_values.sql", version, name)
+		files = append(files,
+			File{Name: script, Content: []byte(strings.Join(values, "\n") + "\n")},
+			File{Name: script + ".conf", Content: []byte(flywayNoTransaction)},
+		)
+		version++
+	}
+	files = append(files, File{Name: fmt.Sprintf("V%d__%s.sql", version, name), Content: []byte(strings.Join(up, "\n") + "\n")})
+	undo := fmt.Sprintf("U%d__%s.sql", version, name)
+	if values, _ := splitEnumValues(plan.Down); len(values) > 0 {
+		files = append(files,
+			File{Name: undo, Content: []byte(plan.DownSQL())},
+			File{Name: undo + ".conf", Content: []byte(flywayNoTransaction)},
+		)
+	} else {
+		files = append(files, File{Name: undo, Content: []byte(strings.Join(plan.Down, "\n") + "\n")})
+	}
+	return files, nil
+}
+
+// flywayNoTransaction is the script configuration running a migration
+// outside of Flyway's transaction.
+const flywayNoTransaction = "executeInTransaction=false\n"
+
+var (
+	alembicRevision     = regexp.MustCompile(`(?m)^revision\s*(?::\s*str\s*)?=\s*['"]([0-9a-zA-Z_]+)['"]`)
+	alembicDownRevision = regexp.MustCompile(`(?m)^down_revision\s*(?::[^=]*)?=\s*(.+)$`)
+	quotedRevision      = regexp.MustCompile(`['"]([0-9a-zA-Z_]+)['"]`)
+)
+
+// alembicRevisions reads the revision scripts of an alembic versions
+// directory and returns every revision with its down revisions.
+func alembicRevisions(dir string) (map[string][]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.py"))
+	if err != nil {
+		return nil, err
+	}
+	revisions := make(map[string][]string)
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		m := alembicRevision.FindSubmatch(data)
+		if m == nil {
+			continue
+		}
+		var parents []string
+		if down := alembicDownRevision.FindSubmatch(data); down != nil {
+			for _, parent := range quotedRevision.FindAllSubmatch(down[1], -1) {
+				parents = append(parents, string(parent[1]))
+			}
+		}
+		revisions[string(m[1])] = parents
+	}
+	return revisions, nil
+}
+
+// alembicHead finds the revision of the versions directory that no other
+// revision names as its down_revision.
+func alembicHead(dir string) (string, error) {
+	revisions, err := alembicRevisions(dir)
+	if err != nil {
+		return "", err
+	}
+	parents := make(map[string]bool)
+	for _, down := range revisions {
+		for _, parent := range down {
+			parents[parent] = true
+		}
+	}
+	var heads []string
+	for rev := range revisions {
+		if !parents[rev] {
+			heads = append(heads, rev)
+		}
+	}
+	if len(heads) > 1 {
+		sort.Strings(heads)
+		return "", fmt.Errorf("alembic history has multiple heads (%s), merge them first", strings.Join(heads, ", "))
+	}
+	if len(heads) == 0 {
+		return "", nil
+	}
+	return heads[0], nil
+}
+
+// writeAlembic writes a revision script in the layout of `alembic revision`,
+// chained to the current head of the versions directory.
+func writeAlembic(dir, name string, plan *MigrationPlan, now time.Time) ([]File, error) {
+	head, err := alembicHead(dir)
+	if err != nil {
+		return nil, err
+	}
+	id := make([]byte, 6)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("failed to generate revision id: %w", err)
+	}
+	revision := hex.EncodeToString(id)
+	downRevision := "None"
+	revises := ""
+	if head != "" {
+		downRevision = pythonString(head)
+		revises = head
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `"""%s
+
+Revision ID: %s
+Revises: %s
+Create Date: %s
+
+"""
+from alembic import op
+
+
+# revision identifiers, used by Alembic.
+revision = %s
+down_revision = %s
+branch_labels = None
+depends_on = None
+
+
+def upgrade():
+%s
+
+def downgrade():
+%s`, strings.ReplaceAll(name, "_", " "), revision, revises, now.Format("2006-01-02 15:04:05.000000"),
+		pythonString(revision), downRevision, alembicOps(plan.Up), alembicOps(plan.Down))
+	return []File{{Name: fmt.Sprintf("%s_%s.py", revision, name), Content: []byte(b.String())}}, nil
+}
+
+// alembicOps renders the statements as op.execute calls. Leading enum
+// values run in an autocommit block, outside of the migration's
+// transaction.
+func alembicOps(statements []string) string {
+	if len(statements) == 0 {
+		return "    pass\n"
+	}
+	var b strings.Builder
+	values, rest := splitEnumValues(statements)
+	if len(values) > 0 {
+		b.WriteString("    with op.get_context().autocommit_block():\n")
+		for _, stmt := range values {
+			fmt.Fprintf(&b, "        op.execute(%s)\n", pythonBlock(stmt))
+		}
+	}
+	for _, stmt := range rest {
+		fmt.Fprintf(&b, "    op.execute(%s)\n", pythonBlock(stmt))
+	}
+	return b.String()
+}
+
+var djangoFile = regexp.MustCompile(`^((\d{4})_\w+)\.py$`)
+
+// writeDjango writes a migration module running the plan through RunSQL.
+// The app label is the name of the directory holding the migrations
+// package, the dependency is the latest existing migration. A migration
+// adding enum values isn't atomic, the values are added first and the rest
+// is wrapped in BEGIN/COMMIT, like in up.sql and down.sql.
+func writeDjango(dir, name string, plan *MigrationPlan, now time.Time) ([]File, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+	previous, number := "", 0
+	for _, entry := range entries {
+		m := djangoFile.FindStringSubmatch(entry.Name())
+		if m == nil {
+			continue
+		}
+		n, _ := strconv.Atoi(m[2])
+		if n > number || n == number && m[1] > previous {
+			previous, number = m[1], n
+		}
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	app := filepath.Base(filepath.Dir(abs))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Generated by schema migrate-plan on %s\n\nfrom django.db import migrations\n\n\n", now.Format("2006-01-02 15:04"))
+	b.WriteString("class Migration(migrations.Migration):\n\n")
+	up, down := plan.Up, plan.Down
+	upValues, _ := splitEnumValues(up)
+	downValues, _ := splitEnumValues(down)
+	if len(upValues) > 0 || len(downValues) > 0 {
+		b.WriteString("    atomic = False\n\n")
+		up, down = explicitTransaction(up), explicitTransaction(down)
+	}
+	if previous == "" {
+		b.WriteString("    initial = True\n\n    dependencies = []\n\n")
+	} else {
+		fmt.Fprintf(&b, "    dependencies = [\n        (%s, %s),\n    ]\n\n", pythonString(app), pythonString(previous))
+	}
+	b.WriteString("    operations = [\n        migrations.RunSQL(\n")
+	fmt.Fprintf(&b, "            sql=%s,\n", pythonList(up, "                "))
+	fmt.Fprintf(&b, "            reverse_sql=%s,\n", pythonList(down, "                "))
+	b.WriteString("        ),\n    ]\n")
+
+	files := []File{{Name: fmt.Sprintf("%04d_%s.py", number+1, name), Content: []byte(b.String())}}
+	if previous == "" {
+		if _, err := os.Stat(filepath.Join(dir, "__init__.py")); os.IsNotExist(err) {
+			files = append(files, File{Name: "__init__.py"})
+		}
+	}
+	return files, nil
+}
+
+// explicitTransaction wraps the statements after the leading enum values
+// in BEGIN and COMMIT.
+func explicitTransaction(statements []string) []string {
+	values, rest := splitEnumValues(statements)
+	if len(rest) == 0 {
+		return values
+	}
+	wrapped := append(slices.Clip(values), "BEGIN;")
+	wrapped = append(wrapped, rest...)
+	return append(wrapped, "COMMIT;")
+}
+
+// pythonString returns s as a Python string literal.
+func pythonString(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`, "\n", `\n`).Replace(s) + "'"
+}
+
+// pythonBlock returns s as a triple quoted Python string literal.
+func pythonBlock(s string) string {
+	return `"""` + strings.NewReplacer(`\`, `\\`, `"""`, `\"\"\"`).Replace(s) + `"""`
+}
+
+func pythonList(statements []string, indent string) string {
+	if len(statements) == 0 {
+		return "[]"
+	}
+	var b strings.Builder
+	b.WriteString("[\n")
+	for _, stmt := range statements {
+		fmt.Fprintf(&b, "%s%s,\n", indent, pythonBlock(stmt))
+	}
+	b.WriteString(indent[4:] + "]")
+	return b.String()
+}
@@ -0,0 +1,168 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+var migrationTime = time.Date(2026, 10, 1, 12, 30, 0, 0, time.UTC)
+
+var alembicRevisionID = regexp.MustCompile(`[0-9a-f]{12}`)
+
+// writeMigrationFiles renders the plan into a fresh blog/migrations
+// directory and returns the files with the random Alembic revision replaced
+// by a fixed one.
+func writeMigrationFiles(t *testing.T, format string, plan *MigrationPlan, existing ...string) []File {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "blog", "migrations")
+	for _, name := range existing {
+		content := ""
+		if strings.HasSuffix(name, ".py") && format == "alembic" {
+			content = alembicFixture(name)
+		}
+		if err := writeFile(filepath.Join(dir, name), []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files, err := MigrationFiles(format, dir, "Add comments", plan, migrationTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format == "alembic" {
+		for i := range files {
+			files[i].Name = alembicRevisionID.ReplaceAllString(files[i].Name, "0123456789ab")
+			files[i].Content = alembicRevisionID.ReplaceAll(files[i].Content, []byte("0123456789ab"))
+		}
+	}
+	return files
+}
+
+// alembicFixture returns a revision file for <revision>_<down revision>.py.
+func alembicFixture(name string) string {
+	revision, down, _ := strings.Cut(strings.TrimSuffix(name, ".py"), "_")
+	downRevision := "None"
+	if down != "" {
+		downRevision = pythonString(down)
+	}
+	return "revision = " + pythonString(revision) + "\ndown_revision = " + downRevision + "\n"
+}
+
+func fileNames(files []File) []string {
+	var names []string
+	for _, f := range files {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+func TestMigrationFiles(t *testing.T) {
+	plans := map[string]*MigrationPlan{
+		"table": PlanMigration(blogSchema(), blogSchemaWith(addTable(commentsTable()))),
+		"enum": PlanMigration(blogSchema(), blogSchemaWith(
+			addTable(commentsTable()),
+			addEnumValues("post_status", "archived"),
+		)),
+	}
+	for _, format := range MigrationFormats {
+		for plan, migration := range plans {
+			t.Run(format+"/"+plan, func(t *testing.T) {
+				for _, f := range writeMigrationFiles(t, format, migration) {
+					golden(t, filepath.Join("migrations", format, plan, f.Name), f.Content)
+				}
+			})
+		}
+	}
+}
+
+func TestMigrationFilesEnumValuesOutsideTransaction(t *testing.T) {
+	// Removing a value recreates the enum, so the down migration adds it
+	// back first.
+	plan := PlanMigration(blogSchemaWith(addEnumValues("post_status", "archived")), blogSchema())
+	if values, _ := splitEnumValues(plan.Down); len(values) == 0 {
+		t.Fatalf("down migration doesn't add enum values: %q", plan.Down)
+	}
+	files := writeMigrationFiles(t, "flyway", plan)
+	if want := []string{"V1__add_comments.sql", "U1__add_comments.sql", "U1__add_comments.sql.conf"}; !slices.Equal(fileNames(files), want) {
+		t.Fatalf("files = %q, want %q", fileNames(files), want)
+	}
+	if undo := string(files[1].Content); !strings.HasPrefix(undo, "ALTER TYPE post_status ADD VALUE") || !strings.Contains(undo, "BEGIN;") {
+		t.Errorf("undo migration doesn't add the values before its transaction:\n%s", undo)
+	}
+
+	files = writeMigrationFiles(t, "django", plan)
+	if django := string(files[0].Content); !strings.Contains(django, "atomic = False") {
+		t.Errorf("django migration adding enum values is atomic:\n%s", django)
+	}
+}
+
+func TestMigrationFilesContinueSequence(t *testing.T) {
+	plan := PlanMigration(blogSchema(), blogSchemaWith(addTable(commentsTable())))
+	tests := []struct {
+		format   string
+		existing []string
+		want     []string
+		contains string
+	}{
+		{
+			format:   "golang-migrate",
+			existing: []string{"000001_init.up.sql", "000001_init.down.sql", "000002_users.up.sql", "000002_users.down.sql"},
+			want:     []string{"000003_add_comments.up.sql", "000003_add_comments.down.sql"},
+		},
+		{
+			format:   "golang-migrate",
+			existing: []string{"20250101000000_init.up.sql"},
+			want:     []string{"20261001123000_add_comments.up.sql", "20261001123000_add_comments.down.sql"},
+		},
+		{
+			format:   "goose",
+			existing: []string{"00001_init.sql", "00002_users.sql"},
+			want:     []string{"00003_add_comments.sql"},
+		},
+		{
+			format:   "flyway",
+			existing: []string{"V1__init.sql", "V2_1__users.sql", "U2__users.sql"},
+			want:     []string{"V3__add_comments.sql", "U3__add_comments.sql"},
+		},
+		{
+			format:   "alembic",
+			existing: []string{"1a2b3c_.py", "4d5e6f_1a2b3c.py"},
+			want:     []string{"0123456789ab_add_comments.py"},
+			contains: "down_revision = '4d5e6f'",
+		},
+		{
+			format:   "django",
+			existing: []string{"__init__.py", "0001_initial.py", "0002_users.py"},
+			want:     []string{"0003_add_comments.py"},
+			contains: "('blog', '0002_users')",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			files := writeMigrationFiles(t, tt.format, plan, tt.existing...)
+			if got := fileNames(files); !slices.Equal(got, tt.want) {
+				t.Errorf("files = %q, want %q", got, tt.want)
+			}
+			if tt.contains != "" && !strings.Contains(string(files[0].Content), tt.contains) {
+				t.Errorf("%s doesn't contain %s:\n%s", files[0].Name, tt.contains, files[0].Content)
+			}
+		})
+	}
+}
+
+func TestAlembicMultipleHeads(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"1a2b3c_.py", "4d5e6f_1a2b3c.py", "7a8b9c_1a2b3c.py"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(alembicFixture(name)), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := MigrationFiles("alembic", dir, "comments", &MigrationPlan{}, migrationTime)
+	if err == nil || !strings.Contains(err.Error(), "4d5e6f, 7a8b9c") {
+		t.Errorf("err = %v, want multiple heads error", err)
+	}
+}
@@ -0,0 +1,222 @@
+package internal
+
+import "strings"
+
+// Schema is the introspected model of a single database schema.
+// Every command that reads a live database builds one of these first
+// and renders it afterwards, so the introspection queries live in one place.
+type Schema struct {
+	Name      string     `json:"name"`
+	Enums     []Enum     `json:"enums,omitempty"`
+	Functions []Function `json:"functions,omitempty"`
+	Tables    []Table    `json:"tables"`
+	Views     []View     `json:"views,omitempty"`
+}
+
+type Table struct {
+	Name        string             `json:"name"`
+	Comment     string             `json:"comment,omitempty"`
+	Columns     []Column           `json:"columns"`
+	PrimaryKey  *PrimaryKey        `json:"primary_key,omitempty"`
+	ForeignKeys []ForeignKey       `json:"foreign_keys,omitempty"`
+	Uniques     []UniqueConstraint `json:"uniques,omitempty"`
+	Checks      []CheckConstraint  `json:"checks,omitempty"`
+	Indexes     []Index            `json:"indexes,omitempty"`
+}
+
+type Column struct {
+	ColumnName string `json:"column_name"`
+	// DataType is the information_schema data_type, e.g. "character varying",
+	// "ARRAY" or "USER-DEFINED". UDTName carries the underlying type name
+	// ("varchar", "_int4", the enum name) and FullType the formatted type
+	// including modifiers, e.g. "character varying(255)" or "integer[]".
+	DataType           string `json:"data_type"`
+	UDTName            string `json:"udt_name"`
+	FullType           string `json:"full_type"`
+	IsNullable         string `json:"is_nullable"`
+	CharMaxLength      int64  `json:"char_max_length,omitempty"`
+	NumericPrecision   int64  `json:"numeric_precision,omitempty"`
+	NumericScale       int64  `json:"numeric_scale,omitempty"`
+	Default            string `json:"default,omitempty"`
+	IsIdentity         bool   `json:"is_identity,omitempty"`
+	IdentityGeneration string `json:"identity_generation,omitempty"`
+	Comment            string `json:"comment,omitempty"`
+}
+
+type PrimaryKey struct {
+	ConstraintName string   `json:"constraint_name"`
+	Columns        []string `json:"columns"`
+}
+
+type ForeignKey struct {
+	ConstraintName string   `json:"constraint_name"`
+	SourceTable    string   `json:"source_table"`
+	SourceColumns  []string `json:"source_columns"`
+	TargetTable    string   `json:"target_table"`
+	TargetColumns  []string `json:"target_columns"`
+	// OnDelete and OnUpdate hold the referential action as written in DDL,
+	// e.g. "CASCADE" or "SET NULL". NO ACTION is the default.
+	OnDelete string `json:"on_delete"`
+	OnUpdate string `json:"on_update"`
+}
+
+type UniqueConstraint struct {
+	ConstraintName string   `json:"constraint_name"`
+	Columns        []string `json:"columns"`
+}
+
+type CheckConstraint struct {
+	ConstraintName string `json:"constraint_name"`
+	Expression     string `json:"expression"`
+}
+
+type Index struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+	Unique  bool     `json:"unique,omitempty"`
+	Method  string   `json:"method"`
+	// Where is the predicate of a partial index, empty otherwise.
+	Where string `json:"where,omitempty"`
+	// Definition is the CREATE INDEX statement reported by the database.
+	Definition string `json:"definition"`
+}
+
+type Enum struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+type View struct {
+	Name         string `json:"name"`
+	Materialized bool   `json:"materialized,omitempty"`
+	Definition   string `json:"definition"`
+	// DependsOn lists the tables and views the view selects from.
+	DependsOn []string `json:"depends_on,omitempty"`
+}
+
+type Function struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+	// Definition is the complete CREATE OR REPLACE FUNCTION statement.
+	Definition string `json:"definition"`
+	// DependsOn lists the schema types used in the signature.
+	DependsOn []string `json:"depends_on,omitempty"`
+}
+
+// Table returns the table with the given name, or nil.
+func (s *Schema) Table(name string) *Table {
+	for i := range s.Tables {
+		if s.Tables[i].Name == name {
+			return &s.Tables[i]
+		}
+	}
+	return nil
+}
+
+// Enum returns the enum type with the given name, or nil.
+func (s *Schema) Enum(name string) *Enum {
+	for i := range s.Enums {
+		if s.Enums[i].Name == name {
+			return &s.Enums[i]
+		}
+	}
+	return nil
+}
+
+// Filter returns a copy of the schema reduced to a single table and the enum
+// types its columns use. Foreign keys pointing at other tables are kept.
+func (s *Schema) Filter(tableName string) *Schema {
+	out := &Schema{Name: s.Name}
+	t := s.Table(tableName)
+	if t == nil {
+		return out
+	}
+	out.Tables = []Table{*t}
+	for _, col := range t.Columns {
+		if e := s.Enum(col.ElementType()); e != nil && out.Enum(e.Name) == nil {
+			out.Enums = append(out.Enums, *e)
+		}
+	}
+	return out
+}
+
+// Column returns the column with the given name, or nil.
+func (t *Table) Column(name string) *Column {
+	for i := range t.Columns {
+		if t.Columns[i].ColumnName == name {
+			return &t.Columns[i]
+		}
+	}
+	return nil
+}
+
+// IsPrimaryKey reports whether the column is part of the primary key.
+func (t *Table) IsPrimaryKey(column string) bool {
+	if t.PrimaryKey == nil {
+		return false
+	}
+	for _, c := range t.PrimaryKey.Columns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+// IsUnique reports whether the column alone is covered by a unique
+// constraint or unique index.
+func (t *Table) IsUnique(column string) bool {
+	for _, u := range t.Uniques {
+		if len(u.Columns) == 1 && u.Columns[0] == column {
+			return true
+		}
+	}
+	for _, idx := range t.Indexes {
+		if idx.Unique && idx.Where == "" && len(idx.Columns) == 1 && idx.Columns[0] == column {
+			return true
+		}
+	}
+	return false
+}
+
+// ForeignKeyFor returns the single-column foreign key on the column, or nil.
+func (t *Table) ForeignKeyFor(column string) *ForeignKey {
+	for i := range t.ForeignKeys {
+		fk := &t.ForeignKeys[i]
+		if len(fk.SourceColumns) == 1 && fk.SourceColumns[0] == column {
+			return fk
+		}
+	}
+	return nil
+}
+
+// Nullable reports whether the column accepts NULL.
+func (c Column) Nullable() bool {
+	return c.IsNullable == "YES"
+}
+
+// IsArray reports whether the column is a Postgres array.
+func (c Column) IsArray() bool {
+	return c.DataType == "ARRAY"
+}
+
+// ElementType returns the udt name of the column, or of its elements for
+// array columns ("_int4" becomes "int4").
+func (c Column) ElementType() string {
+	if c.IsArray() {
+		return strings.TrimPrefix(c.UDTName, "_")
+	}
+	return c.UDTName
+}
+
+// IsSerial reports whether the column default draws from a sequence, which
+// is how serial and bigserial columns show up in the catalog.
+func (c Column) IsSerial() bool {
+	return strings.HasPrefix(c.Default, "nextval(")
+}
+
+// IsGenerated reports whether the database fills the column on insert,
+// either from a sequence or an identity.
+func (c Column) IsGenerated() bool {
+	return c.IsSerial() || c.IsIdentity
+}
@@ -0,0 +1,218 @@
+package internal
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// golden compares content with the file of that name in testdata, or
+// rewrites it with -update.
+func golden(t *testing.T, name string, content []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := writeFile(path, content); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file, run go test -update: %v", err)
+	}
+	if !bytes.Equal(content, want) {
+		t.Errorf("%s differs from the golden file, run go test -update to accept it:\n%s", name, content)
+	}
+}
+
+// compilePython checks that the files are valid Python with py_compile,
+// then runs script next to them when the required packages are installed.
+func compilePython(t *testing.T, files []File, script string, requires ...string) {
+	t.Helper()
+	python, err := exec.LookPath("python3")
+	if err != nil {
+		t.Skip("python3 not found")
+	}
+	dir := t.TempDir()
+	for _, f := range files {
+		path := filepath.Join(dir, filepath.FromSlash(f.Name))
+		if err := writeFile(path, f.Content); err != nil {
+			t.Fatal(err)
+		}
+		if out, err := exec.Command(python, "-m", "py_compile", path).CombinedOutput(); err != nil {
+			t.Fatalf("py_compile %s: %v\n%s", f.Name, err, out)
+		}
+	}
+	for _, pkg := range requires {
+		if exec.Command(python, "-c", "import "+pkg).Run() != nil {
+			t.Logf("%s not installed, only compiled", pkg)
+			return
+		}
+	}
+	cmd := exec.Command(python, "-c", script)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%s: %v\n%s", script, err, out)
+	}
+}
+
+// blogSchema is a small schema with the constructs the writers have to
+// handle: an enum, identity and serial keys, text keys, check constraints,
+// two foreign keys between the same tables and a join table.
+func blogSchema() *Schema {
+	return &Schema{
+		Name:  "public",
+		Enums: []Enum{{Name: "post_status", Values: []string{"draft", "published"}}},
+		Tables: []Table{
+			{
+				Name:    "users",
+				Comment: "People who write posts",
+				Columns: []Column{
+					{ColumnName: "id", DataType: "bigint", UDTName: "int8", FullType: "bigint", IsNullable: "NO", IsIdentity: true, IdentityGeneration: "BY DEFAULT"},
+					{ColumnName: "email", DataType: "text", UDTName: "text", FullType: "text", IsNullable: "NO"},
+					{ColumnName: "name", DataType: "character varying", UDTName: "varchar", FullType: "character varying(100)", CharMaxLength: 100, IsNullable: "YES"},
+					{ColumnName: "bio", DataType: "text", UDTName: "text", FullType: "text", IsNullable: "NO", Default: "''::text"},
+					{ColumnName: "created_at", DataType: "timestamp with time zone", UDTName: "timestamptz", FullType: "timestamp with time zone", IsNullable: "NO", Default: "now()"},
+				},
+				PrimaryKey: &PrimaryKey{ConstraintName: "users_pkey", Columns: []string{"id"}},
+				Uniques:    []UniqueConstraint{{ConstraintName: "users_email_key", Columns: []string{"email"}}},
+			},
+			{
+				Name: "posts",
+				Columns: []Column{
+					{ColumnName: "id", DataType: "bigint", UDTName: "int8", FullType: "bigint", IsNullable: "NO", IsIdentity: true, IdentityGeneration: "BY DEFAULT"},
+					{ColumnName: "author_id", DataType: "bigint", UDTName: "int8", FullType: "bigint", IsNullable: "NO"},
+					{ColumnName: "editor_id", DataType: "bigint", UDTName: "int8", FullType: "bigint", IsNullable: "YES"},
+					{ColumnName: "type", DataType: "text", UDTName: "text", FullType: "text", IsNullable: "NO", Default: "'post'::text"},
+					{ColumnName: "status", DataType: "USER-DEFINED", UDTName: "post_status", FullType: "post_status", IsNullable: "NO", Default: "'draft'::post_status"},
+					{ColumnName: "title", DataType: "character varying", UDTName: "varchar", FullType: "character varying(200)", CharMaxLength: 200, IsNullable: "NO"},
+					{ColumnName: "rating", DataType: "integer", UDTName: "int4", FullType: "integer", IsNullable: "YES"},
+				},
+				PrimaryKey: &PrimaryKey{ConstraintName: "posts_pkey", Columns: []string{"id"}},
+				ForeignKeys: []ForeignKey{
+					{ConstraintName: "posts_author_id_fkey", SourceTable: "posts", SourceColumns: []string{"author_id"}, TargetTable: "users", TargetColumns: []string{"id"}, OnDelete: "CASCADE", OnUpdate: "NO ACTION"},
+					{ConstraintName: "posts_editor_id_fkey", SourceTable: "posts", SourceColumns: []string{"editor_id"}, TargetTable: "users", TargetColumns: []string{"id"}, OnDelete: "SET NULL", OnUpdate: "NO ACTION"},
+				},
+				Checks: []CheckConstraint{
+					{ConstraintName: "posts_type_check", Expression: "(type = ANY (ARRAY['post'::text, 'page'::text]))"},
+					{ConstraintName: "posts_rating_check", Expression: "((rating >= 1) AND (rating <= 5))"},
+					{ConstraintName: "posts_title_check", Expression: "(length((title)::text) > 0)"},
+				},
+				Indexes: []Index{
+					{Name: "posts_author_id_idx", Columns: []string{"author_id"}, Method: "btree", Definition: "CREATE INDEX posts_author_id_idx ON public.posts USING btree (author_id)"},
+				},
+			},
+			{
+				Name: "tags",
+				Columns: []Column{
+					{ColumnName: "id", DataType: "integer", UDTName: "int4", FullType: "integer", IsNullable: "NO", Default: "nextval('tags_id_seq'::regclass)"},
+					{ColumnName: "name", DataType: "text", UDTName: "text", FullType: "text", IsNullable: "NO"},
+				},
+				PrimaryKey: &PrimaryKey{ConstraintName: "tags_pkey", Columns: []string{"id"}},
+				Indexes: []Index{
+					{Name: "tags_name_key", Columns: []string{"name"}, Unique: true, Method: "btree", Definition: "CREATE UNIQUE INDEX tags_name_key ON public.tags USING btree (name)"},
+				},
+			},
+			{
+				Name: "post_tags",
+				Columns: []Column{
+					{ColumnName: "post_id", DataType: "bigint", UDTName: "int8", FullType: "bigint", IsNullable: "NO"},
+					{ColumnName: "tag_id", DataType: "integer", UDTName: "int4", FullType: "integer", IsNullable: "NO"},
+				},
+				PrimaryKey: &PrimaryKey{ConstraintName: "post_tags_pkey", Columns: []string{"post_id", "tag_id"}},
+				ForeignKeys: []ForeignKey{
+					{ConstraintName: "post_tags_post_id_fkey", SourceTable: "post_tags", SourceColumns: []string{"post_id"}, TargetTable: "posts", TargetColumns: []string{"id"}, OnDelete: "CASCADE", OnUpdate: "NO ACTION"},
+					{ConstraintName: "post_tags_tag_id_fkey", SourceTable: "post_tags", SourceColumns: []string{"tag_id"}, TargetTable: "tags", TargetColumns: []string{"id"}, OnDelete: "CASCADE", OnUpdate: "NO ACTION"},
+				},
+			},
+		},
+	}
+}
+
+// blogSchemaWith returns blogSchema after the changes, to compare with the
+// unchanged schema.
+func blogSchemaWith(changes ...func(s *Schema)) *Schema {
+	s := blogSchema()
+	for _, change := range changes {
+		change(s)
+	}
+	return s
+}
+
+// columnTypes holds the information_schema names of the types used in tests.
+var columnTypes = map[string][2]string{
+	"bigint":  {"bigint", "int8"},
+	"integer": {"integer", "int4"},
+	"text":    {"text", "text"},
+	"boolean": {"boolean", "bool"},
+}
+
+// column returns a column of one of the columnTypes, or of an enum.
+func column(name, fullType string, nullable bool) Column {
+	col := Column{ColumnName: name, DataType: "USER-DEFINED", UDTName: fullType, FullType: fullType, IsNullable: "NO"}
+	if names, ok := columnTypes[fullType]; ok {
+		col.DataType, col.UDTName = names[0], names[1]
+	}
+	if nullable {
+		col.IsNullable = "YES"
+	}
+	return col
+}
+
+// commentsTable is a table to add to blogSchema, referencing posts.
+func commentsTable() Table {
+	return Table{
+		Name:       "comments",
+		Columns:    []Column{column("id", "bigint", false), column("post_id", "bigint", false)},
+		PrimaryKey: &PrimaryKey{ConstraintName: "comments_pkey", Columns: []string{"id"}},
+		ForeignKeys: []ForeignKey{
+			{ConstraintName: "comments_post_id_fkey", SourceTable: "comments", SourceColumns: []string{"post_id"}, TargetTable: "posts", TargetColumns: []string{"id"}, OnDelete: "CASCADE"},
+		},
+	}
+}
+
+func addTable(table Table) func(s *Schema) {
+	return func(s *Schema) { s.Tables = append(s.Tables, table) }
+}
+
+func dropTable(name string) func(s *Schema) {
+	return func(s *Schema) {
+		s.Tables = slices.DeleteFunc(s.Tables, func(t Table) bool { return t.Name == name })
+	}
+}
+
+func addColumn(table string, col Column) func(s *Schema) {
+	return func(s *Schema) {
+		t := s.Table(table)
+		t.Columns = append(t.Columns, col)
+	}
+}
+
+func dropColumn(table, name string) func(s *Schema) {
+	return func(s *Schema) {
+		t := s.Table(table)
+		t.Columns = slices.DeleteFunc(t.Columns, func(c Column) bool { return c.ColumnName == name })
+	}
+}
+
+func alterColumn(table, name string, change func(c *Column)) func(s *Schema) {
+	return func(s *Schema) { change(s.Table(table).Column(name)) }
+}
+
+func alterTable(name string, change func(t *Table)) func(s *Schema) {
+	return func(s *Schema) { change(s.Table(name)) }
+}
+
+func addEnumValues(name string, values ...string) func(s *Schema) {
+	return func(s *Schema) {
+		e := s.Enum(name)
+		e.Values = append(e.Values, values...)
+	}
+}
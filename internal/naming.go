@@ -0,0 +1,158 @@
+package internal
+
+import (
+	"strings"
+	"unicode"
+)
+
+// splitWords splits snake_case, kebab-case, camelCase and PascalCase names
+// into lower case words.
+func splitWords(name string) []string {
+	var words []string
+	var word []rune
+	runes := []rune(name)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			if len(word) > 0 {
+				words = append(words, string(word))
+				word = nil
+			}
+			continue
+		case unicode.IsUpper(r) && len(word) > 0:
+			prevLower := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || (unicode.IsUpper(runes[i-1]) && nextLower) {
+				words = append(words, string(word))
+				word = nil
+			}
+		}
+		word = append(word, unicode.ToLower(r))
+	}
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+	return words
+}
+
+// pascalCase turns a name into PascalCase, e.g. "user_id" into "UserId".
+func pascalCase(name string) string {
+	var b strings.Builder
+	for _, word := range splitWords(name) {
+		r := []rune(word)
+		b.WriteString(strings.ToUpper(string(r[0])) + string(r[1:]))
+	}
+	return b.String()
+}
+
+// camelCase turns a name into camelCase, e.g. "user_id" into "userId".
+func camelCase(name string) string {
+	p := []rune(pascalCase(name))
+	if len(p) == 0 {
+		return ""
+	}
+	return strings.ToLower(string(p[0])) + string(p[1:])
+}
+
+// snakeCase turns a name into snake_case.
+func snakeCase(name string) string {
+	return strings.Join(splitWords(name), "_")
+}
+
+// className returns the class name of a table's model, e.g. OrderItem for
+// order_items.
+func className(name string) string {
+	class := pascalCase(singular(name))
+	if class == "" || class[0] >= '0' && class[0] <= '9' {
+		class = "T" + class
+	}
+	return class
+}
+
+// irregularPlurals covers the nouns that commonly name tables and don't
+// follow the suffix rules.
+var irregularPlurals = map[string]string{
+	"person": "people", "child": "children", "man": "men", "woman": "women",
+	"mouse": "mice", "goose": "geese", "foot": "feet", "tooth": "teeth",
+	"datum": "data", "criterion": "criteria", "index": "indices",
+}
+
+var uncountable = map[string]bool{
+	"data": true, "metadata": true, "information": true, "equipment": true,
+	"news": true, "series": true, "species": true, "media": true, "feedback": true,
+}
+
+// singular returns the singular of the last word of a snake_case name,
+// e.g. "order_items" becomes "order_item".
+func singular(name string) string {
+	words := splitWords(name)
+	if len(words) == 0 {
+		return name
+	}
+	last := words[len(words)-1]
+	words[len(words)-1] = singularWord(last)
+	return strings.Join(words, "_")
+}
+
+// plural returns the plural of the last word of a snake_case name.
+func plural(name string) string {
+	words := splitWords(name)
+	if len(words) == 0 {
+		return name
+	}
+	last := words[len(words)-1]
+	words[len(words)-1] = pluralWord(last)
+	return strings.Join(words, "_")
+}
+
+func singularWord(w string) string {
+	if uncountable[w] {
+		return w
+	}
+	for one, many := range irregularPlurals {
+		if w == many {
+			return one
+		}
+	}
+	switch {
+	case strings.HasSuffix(w, "ies") && len(w) > 4:
+		return w[:len(w)-3] + "y"
+	case strings.HasSuffix(w, "ouses"):
+		return w[:len(w)-1]
+	case strings.HasSuffix(w, "uses"):
+		return w[:len(w)-2]
+	case strings.HasSuffix(w, "sses"), strings.HasSuffix(w, "shes"), strings.HasSuffix(w, "ches"),
+		strings.HasSuffix(w, "xes"), strings.HasSuffix(w, "zzes"):
+		return w[:len(w)-2]
+	case strings.HasSuffix(w, "ss"), strings.HasSuffix(w, "us"), strings.HasSuffix(w, "is"):
+		return w
+	case strings.HasSuffix(w, "s") && len(w) > 1:
+		return w[:len(w)-1]
+	}
+	return w
+}
+
+func pluralWord(w string) string {
+	if uncountable[w] {
+		return w
+	}
+	if many, ok := irregularPlurals[w]; ok {
+		return many
+	}
+	for _, many := range irregularPlurals {
+		if w == many {
+			return w
+		}
+	}
+	switch {
+	case strings.HasSuffix(w, "y") && len(w) > 1 && !strings.ContainsRune("aeiou", rune(w[len(w)-2])):
+		return w[:len(w)-1] + "ies"
+	case strings.HasSuffix(w, "s"), strings.HasSuffix(w, "sh"), strings.HasSuffix(w, "ch"),
+		strings.HasSuffix(w, "x"), strings.HasSuffix(w, "z"):
+		if strings.HasSuffix(w, "s") && singularWord(w) != w {
+			return w
+		}
+		return w + "es"
+	}
+	return w + "s"
+}
@@ -0,0 +1,40 @@
+package internal
+
+import "testing"
+
+func TestNaming(t *testing.T) {
+	tests := []struct {
+		name                     string
+		pascal, camel, snake     string
+		singular, pluralOfSingle string
+	}{
+		{"order_items", "OrderItems", "orderItems", "order_items", "order_item", "order_items"},
+		{"UserProfile", "UserProfile", "userProfile", "user_profile", "user_profile", "user_profiles"},
+		{"HTTPRequests", "HttpRequests", "httpRequests", "http_requests", "http_request", "http_requests"},
+		{"categories", "Categories", "categories", "categories", "category", "categories"},
+		{"people", "People", "people", "people", "person", "people"},
+		{"addresses", "Addresses", "addresses", "addresses", "address", "addresses"},
+		{"statuses", "Statuses", "statuses", "statuses", "status", "statuses"},
+		{"metadata", "Metadata", "metadata", "metadata", "metadata", "metadata"},
+		{"boxes", "Boxes", "boxes", "boxes", "box", "boxes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pascalCase(tt.name); got != tt.pascal {
+				t.Errorf("pascalCase = %s, want %s", got, tt.pascal)
+			}
+			if got := camelCase(tt.name); got != tt.camel {
+				t.Errorf("camelCase = %s, want %s", got, tt.camel)
+			}
+			if got := snakeCase(tt.name); got != tt.snake {
+				t.Errorf("snakeCase = %s, want %s", got, tt.snake)
+			}
+			if got := singular(tt.name); got != tt.singular {
+				t.Errorf("singular = %s, want %s", got, tt.singular)
+			}
+			if got := plural(tt.singular); got != tt.pluralOfSingle {
+				t.Errorf("plural(%s) = %s, want %s", tt.singular, got, tt.pluralOfSingle)
+			}
+		})
+	}
+}
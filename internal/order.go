@@ -0,0 +1,271 @@
+package internal
+
+import (
+	"sort"
+	"strings"
+)
+
+// ObjectKind identifies the kind of a schema object in a creation order.
+// The values also break ties between objects that don't depend on each other,
+// so types come before functions, functions before tables and tables before views.
+type ObjectKind int
+
+const (
+	KindEnum ObjectKind = iota
+	KindFunction
+	KindTable
+	KindView
+	// KindForeignKey marks a deferred foreign key, created after all tables.
+	KindForeignKey
+)
+
+// SchemaObject points at one object of a Schema by kind and index.
+type SchemaObject struct {
+	Kind  ObjectKind
+	Index int
+}
+
+// CreationOrder is an order in which the objects of a schema can be created
+// without referring to anything that doesn't exist yet.
+type CreationOrder struct {
+	Objects []SchemaObject
+	// DeferredForeignKeys take part in a reference cycle between tables and
+	// are added with ALTER TABLE once every table exists.
+	DeferredForeignKeys []ForeignKey
+}
+
+// IsDeferred reports whether the foreign key is created after the tables.
+func (o *CreationOrder) IsDeferred(fk ForeignKey) bool {
+	for _, d := range o.DeferredForeignKeys {
+		if d.SourceTable == fk.SourceTable && d.ConstraintName == fk.ConstraintName {
+			return true
+		}
+	}
+	return false
+}
+
+// OrderSchema sorts the schema objects topologically by dependency.
+// Foreign keys between tables that reference each other in a cycle are
+// pulled out of the table definitions so the remaining graph is acyclic.
+func OrderSchema(s *Schema) *CreationOrder {
+	order := &CreationOrder{DeferredForeignKeys: cyclicForeignKeys(s)}
+
+	type node struct {
+		obj  SchemaObject
+		name string
+		deps map[int]bool
+	}
+	var nodes []*node
+	byKey := make(map[string]int)
+	add := func(kind ObjectKind, index int, name string) {
+		byKey[objectKey(kind, name)] = len(nodes)
+		nodes = append(nodes, &node{obj: SchemaObject{Kind: kind, Index: index}, name: name, deps: map[int]bool{}})
+	}
+	for i, e := range s.Enums {
+		add(KindEnum, i, e.Name)
+	}
+	for i, f := range s.Functions {
+		add(KindFunction, i, f.Name+"("+f.Arguments+")")
+	}
+	for i, t := range s.Tables {
+		add(KindTable, i, t.Name)
+	}
+	for i, v := range s.Views {
+		add(KindView, i, v.Name)
+	}
+	dependOn := func(from int, kind ObjectKind, name string) {
+		if to, ok := byKey[objectKey(kind, name)]; ok && to != from {
+			nodes[from].deps[to] = true
+		}
+	}
+
+	for _, f := range s.Functions {
+		from := byKey[objectKey(KindFunction, f.Name+"("+f.Arguments+")")]
+		for _, dep := range f.DependsOn {
+			// A function can take an enum or the row type of a table.
+			dependOn(from, KindEnum, dep)
+			dependOn(from, KindTable, dep)
+		}
+	}
+	for _, t := range s.Tables {
+		from := byKey[objectKey(KindTable, t.Name)]
+		var exprs []string
+		for _, col := range t.Columns {
+			dependOn(from, KindEnum, col.ElementType())
+			exprs = append(exprs, col.Default)
+		}
+		for _, c := range t.Checks {
+			exprs = append(exprs, c.Expression)
+		}
+		for _, f := range s.Functions {
+			for _, expr := range exprs {
+				if referencesFunction(expr, f.Name) {
+					dependOn(from, KindFunction, f.Name+"("+f.Arguments+")")
+					break
+				}
+			}
+		}
+		for _, fk := range t.ForeignKeys {
+			if !order.IsDeferred(fk) {
+				dependOn(from, KindTable, fk.TargetTable)
+			}
+		}
+	}
+	for _, v := range s.Views {
+		from := byKey[objectKey(KindView, v.Name)]
+		for _, dep := range v.DependsOn {
+			dependOn(from, KindTable, dep)
+			dependOn(from, KindView, dep)
+		}
+	}
+
+	// Kahn's algorithm, always picking the lowest ready kind and name so the
+	// output is stable between runs.
+	less := func(a, b int) bool {
+		if nodes[a].obj.Kind != nodes[b].obj.Kind {
+			return nodes[a].obj.Kind < nodes[b].obj.Kind
+		}
+		return nodes[a].name < nodes[b].name
+	}
+	done := make([]bool, len(nodes))
+	for len(order.Objects) < len(nodes) {
+		next := -1
+		for i, n := range nodes {
+			if done[i] {
+				continue
+			}
+			ready := true
+			for dep := range n.deps {
+				if !done[dep] {
+					ready = false
+					break
+				}
+			}
+			if ready && (next == -1 || less(i, next)) {
+				next = i
+			}
+		}
+		if next == -1 {
+			// What is left still forms a cycle that can't be deferred, for
+			// instance a function over a table's row type used in that
+			// table's default. Emit the lowest object and carry on.
+			for i := range nodes {
+				if !done[i] && (next == -1 || less(i, next)) {
+					next = i
+				}
+			}
+		}
+		done[next] = true
+		order.Objects = append(order.Objects, nodes[next].obj)
+	}
+	return order
+}
+
+// objectName returns the name of the object obj points at.
+func (s *Schema) objectName(obj SchemaObject) string {
+	switch obj.Kind {
+	case KindEnum:
+		return s.Enums[obj.Index].Name
+	case KindFunction:
+		return s.Functions[obj.Index].Name
+	case KindView:
+		return s.Views[obj.Index].Name
+	default:
+		return s.Tables[obj.Index].Name
+	}
+}
+
+func objectKey(kind ObjectKind, name string) string {
+	return string(rune('0'+kind)) + ":" + name
+}
+
+// cyclicForeignKeys returns the foreign keys whose source and target table
+// belong to the same strongly connected component of the reference graph.
+// Self references are left inline, Postgres accepts those in CREATE TABLE.
+func cyclicForeignKeys(s *Schema) []ForeignKey {
+	index := make(map[string]int)
+	lowlink := make(map[string]int)
+	onStack := make(map[string]bool)
+	component := make(map[string]int)
+	var stack []string
+	counter, components := 0, 0
+
+	var connect func(name string)
+	connect = func(name string) {
+		index[name] = counter
+		lowlink[name] = counter
+		counter++
+		stack = append(stack, name)
+		onStack[name] = true
+
+		for _, fk := range s.Table(name).ForeignKeys {
+			target := fk.TargetTable
+			if s.Table(target) == nil {
+				continue
+			}
+			if _, seen := index[target]; !seen {
+				connect(target)
+				lowlink[name] = min(lowlink[name], lowlink[target])
+			} else if onStack[target] {
+				lowlink[name] = min(lowlink[name], index[target])
+			}
+		}
+
+		if lowlink[name] == index[name] {
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				component[top] = components
+				if top == name {
+					break
+				}
+			}
+			components++
+		}
+	}
+	for _, t := range s.Tables {
+		if _, seen := index[t.Name]; !seen {
+			connect(t.Name)
+		}
+	}
+
+	var deferred []ForeignKey
+	for _, t := range s.Tables {
+		for _, fk := range t.ForeignKeys {
+			if fk.TargetTable == fk.SourceTable || s.Table(fk.TargetTable) == nil {
+				continue
+			}
+			if component[fk.SourceTable] == component[fk.TargetTable] {
+				deferred = append(deferred, fk)
+			}
+		}
+	}
+	sort.SliceStable(deferred, func(i, j int) bool {
+		if deferred[i].SourceTable != deferred[j].SourceTable {
+			return deferred[i].SourceTable < deferred[j].SourceTable
+		}
+		return deferred[i].ConstraintName < deferred[j].ConstraintName
+	})
+	return deferred
+}
+
+// referencesFunction reports whether expr calls the function name, matching
+// on identifier boundaries so "now(" doesn't match "my_now(".
+func referencesFunction(expr, name string) bool {
+	for offset := 0; ; {
+		i := strings.Index(expr[offset:], name+"(")
+		if i < 0 {
+			return false
+		}
+		i += offset
+		if i == 0 || !isIdentChar(expr[i-1]) {
+			return true
+		}
+		offset = i + len(name)
+	}
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '$' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
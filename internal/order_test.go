@@ -0,0 +1,128 @@
+package internal
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// orderNames lists the objects of a creation order as "kind name" lines.
+func orderNames(s *Schema, order *CreationOrder) []string {
+	var names []string
+	for _, obj := range order.Objects {
+		switch obj.Kind {
+		case KindEnum:
+			names = append(names, "enum "+s.Enums[obj.Index].Name)
+		case KindFunction:
+			names = append(names, "function "+s.Functions[obj.Index].Name)
+		case KindTable:
+			names = append(names, "table "+s.Tables[obj.Index].Name)
+		case KindView:
+			names = append(names, "view "+s.Views[obj.Index].Name)
+		}
+	}
+	return names
+}
+
+func fkNames(fks []ForeignKey) []string {
+	var names []string
+	for _, fk := range fks {
+		names = append(names, fk.ConstraintName)
+	}
+	return names
+}
+
+func TestOrderSchema(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   *Schema
+		want     []string
+		deferred []string
+	}{
+		{
+			name:   "referenced tables first",
+			schema: blogSchema(),
+			want:   []string{"enum post_status", "table tags", "table users", "table posts", "table post_tags"},
+		},
+		{
+			name: "functions and views",
+			schema: blogSchemaWith(
+				func(s *Schema) {
+					s.Views = []View{
+						{Name: "recent_posts", DependsOn: []string{"published_posts"}},
+						{Name: "published_posts", DependsOn: []string{"posts"}},
+					}
+					s.Functions = []Function{{Name: "slugify", Arguments: "text"}}
+				},
+				alterColumn("tags", "name", func(c *Column) { c.Default = "slugify('tag'::text)" }),
+			),
+			want: []string{"enum post_status", "function slugify", "table tags", "table users", "table posts",
+				"table post_tags", "view published_posts", "view recent_posts"},
+		},
+		{
+			name: "cycle deferred, self reference kept",
+			schema: blogSchemaWith(
+				addColumn("users", column("pinned_post_id", "bigint", true)),
+				addColumn("users", column("invited_by", "bigint", true)),
+				alterTable("users", func(t *Table) {
+					t.ForeignKeys = append(t.ForeignKeys,
+						ForeignKey{ConstraintName: "users_pinned_post_id_fkey", SourceTable: "users", SourceColumns: []string{"pinned_post_id"}, TargetTable: "posts", TargetColumns: []string{"id"}},
+						ForeignKey{ConstraintName: "users_invited_by_fkey", SourceTable: "users", SourceColumns: []string{"invited_by"}, TargetTable: "users", TargetColumns: []string{"id"}},
+					)
+				}),
+			),
+			want:     []string{"enum post_status", "table posts", "table tags", "table post_tags", "table users"},
+			deferred: []string{"posts_author_id_fkey", "posts_editor_id_fkey", "users_pinned_post_id_fkey"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := OrderSchema(tt.schema)
+			if got := orderNames(tt.schema, order); !slices.Equal(got, tt.want) {
+				t.Errorf("order = %q, want %q", got, tt.want)
+			}
+			got := fkNames(order.DeferredForeignKeys)
+			slices.Sort(got)
+			if !slices.Equal(got, tt.deferred) {
+				t.Errorf("deferred = %q, want %q", got, tt.deferred)
+			}
+		})
+	}
+}
+
+func TestPostgresDDL(t *testing.T) {
+	var b strings.Builder
+	if _, err := WriteDDL(&b, blogSchema(), "postgres"); err != nil {
+		t.Fatal(err)
+	}
+	golden(t, "schema.sql", []byte(b.String()))
+}
+
+func TestPostgresDDLDefersCycles(t *testing.T) {
+	schema := blogSchemaWith(
+		addColumn("users", column("pinned_post_id", "bigint", true)),
+		alterTable("users", func(t *Table) {
+			t.ForeignKeys = append(t.ForeignKeys, ForeignKey{ConstraintName: "users_pinned_post_id_fkey", SourceTable: "users",
+				SourceColumns: []string{"pinned_post_id"}, TargetTable: "posts", TargetColumns: []string{"id"}, OnDelete: "SET NULL"})
+		}),
+		func(s *Schema) {
+			s.Functions = []Function{{Name: "slugify", Arguments: "text", Definition: "CREATE OR REPLACE FUNCTION public.slugify(text) RETURNS text LANGUAGE sql AS $$ SELECT lower($1) $$"}}
+		},
+	)
+	var b strings.Builder
+	if _, err := WriteDDL(&b, schema, "postgres"); err != nil {
+		t.Fatal(err)
+	}
+	ddl := b.String()
+	if !strings.HasPrefix(ddl, disableFunctionBodyChecks) {
+		t.Errorf("dump with functions doesn't disable function body checks:\n%s", ddl)
+	}
+	for _, fk := range []string{"posts_author_id_fkey", "users_pinned_post_id_fkey"} {
+		if strings.Contains(ddl, "    CONSTRAINT "+fk) {
+			t.Errorf("%s is inline although it is part of a cycle", fk)
+		}
+	}
+	if !strings.HasSuffix(ddl, "ALTER TABLE users ADD CONSTRAINT users_pinned_post_id_fkey FOREIGN KEY (pinned_post_id) REFERENCES posts(id) ON DELETE SET NULL;\n\n") {
+		t.Errorf("deferred foreign keys are not added at the end:\n%s", ddl)
+	}
+}
@@ -0,0 +1,108 @@
+package internal
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// File is one named piece of command output. Name is a slash separated path
+// relative to the output directory.
+type File struct {
+	Name    string
+	Content []byte
+}
+
+// Output is where a command writes its results, as given by --out and
+// --split.
+//
+// Path "-" writes to stdout. An existing directory, or a path ending in a
+// separator, receives the files under their own names. Any other path is a
+// file that receives all content. An empty Path writes to the file names in
+// the current directory, which is what the commands always did.
+type Output struct {
+	Path  string
+	Split bool
+}
+
+// Stdout reports whether the output goes to standard output. Commands use it
+// to keep their progress messages off the data stream.
+func (o Output) Stdout() bool {
+	return o.Path == "-"
+}
+
+// Write writes the files to the output destination.
+func (o Output) Write(files []File) error {
+	if o.Stdout() {
+		if o.Split {
+			return fmt.Errorf("--split needs a directory to write to, not stdout")
+		}
+		for _, f := range files {
+			if _, err := os.Stdout.Write(f.Content); err != nil {
+				return fmt.Errorf("failed to write to stdout: %w", err)
+			}
+		}
+		return nil
+	}
+
+	if o.Path == "" || o.Split || o.isDir() {
+		dir := o.Path
+		if dir == "" {
+			dir = "."
+		}
+		for _, f := range files {
+			path := filepath.Join(dir, filepath.FromSlash(f.Name))
+			if err := writeFile(path, f.Content); err != nil {
+				return err
+			}
+		}
+		if len(files) == 1 {
+			log.Printf("Output written to %s", filepath.Join(dir, filepath.FromSlash(files[0].Name)))
+		} else {
+			log.Printf("%d files written to %s", len(files), dir)
+		}
+		return nil
+	}
+
+	var content []byte
+	for _, f := range files {
+		content = append(content, f.Content...)
+	}
+	if err := writeFile(o.Path, content); err != nil {
+		return err
+	}
+	log.Printf("Output written to %s", o.Path)
+	return nil
+}
+
+// Target returns the path a file of the given name is written to, or ""
+// when it goes to stdout.
+func (o Output) Target(name string) string {
+	switch {
+	case o.Stdout():
+		return ""
+	case o.Path == "" || o.Split || o.isDir():
+		return filepath.Join(o.Path, filepath.FromSlash(name))
+	}
+	return o.Path
+}
+
+func (o Output) isDir() bool {
+	if strings.HasSuffix(o.Path, "/") || strings.HasSuffix(o.Path, string(filepath.Separator)) {
+		return true
+	}
+	info, err := os.Stat(o.Path)
+	return err == nil && info.IsDir()
+}
+
+func writeFile(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package internal
+
+import (
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var outputFiles = []File{
+	{Name: "schema.sql", Content: []byte("CREATE TABLE a ();\n")},
+	{Name: "tables/b.sql", Content: []byte("CREATE TABLE b ();\n")},
+}
+
+// readTree returns the files below dir with their content.
+func readTree(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	files := make(map[string]string)
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		files[filepath.ToSlash(rel)] = string(content)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestOutputWrite(t *testing.T) {
+	both := map[string]string{"schema.sql": "CREATE TABLE a ();\n", "tables/b.sql": "CREATE TABLE b ();\n"}
+	tests := []struct {
+		name  string
+		path  string
+		split bool
+		want  map[string]string
+	}{
+		{name: "file", path: "out.sql", want: map[string]string{"out.sql": "CREATE TABLE a ();\nCREATE TABLE b ();\n"}},
+		{name: "new directory", path: "db/", want: prefixed("db/", both)},
+		{name: "split", path: "db", split: true, want: prefixed("db/", both)},
+		{name: "existing directory", path: ".", want: both},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			out := Output{Path: filepath.Join(dir, tt.path), Split: tt.split}
+			if strings.HasSuffix(tt.path, "/") {
+				out.Path += "/"
+			}
+			if err := out.Write(outputFiles); err != nil {
+				t.Fatal(err)
+			}
+			if got := readTree(t, dir); !maps.Equal(got, tt.want) {
+				t.Errorf("wrote %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func prefixed(prefix string, files map[string]string) map[string]string {
+	out := make(map[string]string)
+	for name, content := range files {
+		out[prefix+name] = content
+	}
+	return out
+}
+
+func TestOutputDefaultsToWorkingDirectory(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	if err := (Output{}).Write(outputFiles[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if got := readTree(t, dir); !maps.Equal(got, map[string]string{"schema.sql": "CREATE TABLE a ();\n"}) {
+		t.Errorf("wrote %q", got)
+	}
+}
+
+func TestOutputStdout(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	err = Output{Path: "-"}.Write(outputFiles)
+	os.Stdout = stdout
+	w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := io.ReadAll(r)
+	if string(got) != "CREATE TABLE a ();\nCREATE TABLE b ();\n" {
+		t.Errorf("stdout = %q", got)
+	}
+
+	if err := (Output{Path: "-", Split: true}).Write(outputFiles); err == nil {
+		t.Error("--split to stdout succeeded, want an error")
+	}
+}
+
+func TestSchemaFilesSplit(t *testing.T) {
+	schema := blogSchemaWith(func(s *Schema) {
+		s.Functions = []Function{{Name: "slugify", Arguments: "text", Definition: "CREATE OR REPLACE FUNCTION public.slugify(text) RETURNS text LANGUAGE sql AS $$ SELECT lower($1) $$"}}
+		s.Views = []View{{Name: "published_posts", Definition: " SELECT id FROM posts WHERE status = 'published'", DependsOn: []string{"posts"}}}
+	})
+	files, _, err := schemaFiles(schema, "postgres", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	content := make(map[string]string)
+	for _, f := range files {
+		names = append(names, f.Name)
+		content[f.Name] = string(f.Content)
+	}
+	want := []string{"types/post_status.sql", "functions/slugify.sql", "tables/tags.sql", "tables/users.sql",
+		"tables/posts.sql", "tables/post_tags.sql", "views/published_posts.sql", "order.txt"}
+	if !slices.Equal(names, want) {
+		t.Fatalf("files = %q, want %q", names, want)
+	}
+	if order := strings.Join(want[:len(want)-1], "\n") + "\n"; content["order.txt"] != order {
+		t.Errorf("order.txt = %q, want %q", content["order.txt"], order)
+	}
+	if !strings.HasPrefix(content["functions/slugify.sql"], disableFunctionBodyChecks) {
+		t.Errorf("function file doesn't disable body checks:\n%s", content["functions/slugify.sql"])
+	}
+	if strings.Contains(content["tables/posts.sql"], disableFunctionBodyChecks) {
+		t.Errorf("table file disables function body checks:\n%s", content["tables/posts.sql"])
+	}
+
+	files, _, err = schemaFiles(schema, "postgres", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Name != "schema.sql" || !strings.HasPrefix(string(files[0].Content), disableFunctionBodyChecks) {
+		t.Errorf("single file dump = %v", files)
+	}
+}
+
+func TestOutputTarget(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		out  Output
+		want string
+	}{
+		{Output{}, "down.sql"},
+		{Output{Path: "-"}, ""},
+		{Output{Path: filepath.Join(dir, "plan.sql")}, filepath.Join(dir, "plan.sql")},
+		{Output{Path: dir}, filepath.Join(dir, "down.sql")},
+		{Output{Path: "migrations", Split: true}, filepath.Join("migrations", "down.sql")},
+	}
+	for _, tt := range tests {
+		if got := tt.out.Target("down.sql"); got != tt.want {
+			t.Errorf("%+v.Target(down.sql) = %q, want %q", tt.out, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// protoTypes maps udt names to proto3 scalar types, or to the well-known
+// message types for timestamps and intervals.
+var protoTypes = map[string]string{
+	"int2": "int32", "int4": "int32", "int8": "int64", "float4": "float", "float8": "double",
+	"bool": "bool", "bytea": "bytes",
+	"timestamp": "google.protobuf.Timestamp", "timestamptz": "google.protobuf.Timestamp",
+	"interval": "google.protobuf.Duration",
+}
+
+// protoWrappers are the well-known wrapper messages of the scalar types,
+// which tell null apart from the zero value.
+var protoWrappers = map[string]string{
+	"int32": "google.protobuf.Int32Value", "int64": "google.protobuf.Int64Value",
+	"float": "google.protobuf.FloatValue", "double": "google.protobuf.DoubleValue",
+	"bool": "google.protobuf.BoolValue", "string": "google.protobuf.StringValue",
+	"bytes": "google.protobuf.BytesValue",
+}
+
+// protoImports are the files declaring the well-known types.
+var protoImports = map[string]string{
+	"google.protobuf.Timestamp": "google/protobuf/timestamp.proto",
+	"google.protobuf.Duration":  "google/protobuf/duration.proto",
+}
+
+// protoField is a field of a message, or a value of an enum, as numbered
+// in a .proto file.
+type protoField struct {
+	name   string
+	typ    string
+	number int
+}
+
+// protoBlock holds the numbers of a message or enum.
+type protoBlock struct {
+	fields        []protoField
+	reserved      []int
+	reservedNames []string
+}
+
+var (
+	protoBlockStart = regexp.MustCompile(`^\s*(?:message|enum)\s+(\w+)\s*\{`)
+	protoFieldLine  = regexp.MustCompile(`^\s*((?:(?:repeated|optional)\s+)?[\w.]+)\s+(\w+)\s*=\s*(\d+)\s*;`)
+	protoEnumLine   = regexp.MustCompile(`^\s*(\w+)\s*=\s*(\d+)\s*;`)
+	protoReserved   = regexp.MustCompile(`^\s*reserved\s+(.+);`)
+)
+
+// parseProtoNumbers reads the field and enum value numbers, and the
+// reserved ones, of a .proto file written before. It only understands the
+// files ProtoSchema writes.
+func parseProtoNumbers(content string) map[string]*protoBlock {
+	blocks := make(map[string]*protoBlock)
+	var current *protoBlock
+	for _, line := range strings.Split(content, "\n") {
+		if m := protoBlockStart.FindStringSubmatch(line); m != nil {
+			current = &protoBlock{}
+			blocks[m[1]] = current
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		if m := protoReserved.FindStringSubmatch(line); m != nil {
+			for _, part := range strings.Split(m[1], ",") {
+				part = strings.TrimSpace(part)
+				if n, err := strconv.Atoi(part); err == nil {
+					current.reserved = append(current.reserved, n)
+				} else if name, err := strconv.Unquote(part); err == nil {
+					current.reservedNames = append(current.reservedNames, name)
+				}
+			}
+		} else if m := protoFieldLine.FindStringSubmatch(line); m != nil {
+			n, _ := strconv.Atoi(m[3])
+			current.fields = append(current.fields, protoField{name: m[2], typ: m[1], number: n})
+		} else if m := protoEnumLine.FindStringSubmatch(line); m != nil {
+			n, _ := strconv.Atoi(m[2])
+			current.fields = append(current.fields, protoField{name: m[1], number: n})
+		}
+	}
+	return blocks
+}
+
+// number numbers the fields of a message or enum. A field keeps the
+// number it had before unless its type changed; new fields get numbers
+// above any used before. The numbers and names of dropped fields are
+// reserved, so they're never reused.
+func (previous *protoBlock) number(fields []protoField, first int) protoBlock {
+	if previous == nil {
+		previous = &protoBlock{}
+	}
+	next := first
+	for _, n := range previous.reserved {
+		next = max(next, n+1)
+	}
+	for _, f := range previous.fields {
+		next = max(next, f.number+1)
+	}
+
+	var block protoBlock
+	kept := make(map[int]bool)
+	names := make(map[string]bool)
+	for _, f := range fields {
+		names[f.name] = true
+		numbered := false
+		for _, old := range previous.fields {
+			if old.name == f.name && old.typ == f.typ {
+				f.number, numbered = old.number, true
+			}
+		}
+		if !numbered {
+			// 19000 to 19999 are reserved for the protobuf implementation.
+			if next >= 19000 && next <= 19999 {
+				next = 20000
+			}
+			f.number = next
+			next++
+		}
+		kept[f.number] = true
+		block.fields = append(block.fields, f)
+	}
+
+	block.reserved = slices.Clone(previous.reserved)
+	for _, old := range previous.fields {
+		if !kept[old.number] {
+			block.reserved = append(block.reserved, old.number)
+		}
+		if !names[old.name] {
+			block.reservedNames = append(block.reservedNames, old.name)
+		}
+	}
+	for _, name := range previous.reservedNames {
+		// A name added back is a new field with a new number.
+		if !names[name] {
+			block.reservedNames = append(block.reservedNames, name)
+		}
+	}
+	slices.Sort(block.reserved)
+	block.reserved = slices.Compact(block.reserved)
+	slices.Sort(block.reservedNames)
+	block.reservedNames = slices.Compact(block.reservedNames)
+	return block
+}
+
+// render writes the reserved numbers and names of a block.
+func (block protoBlock) render(b *strings.Builder) {
+	if len(block.reserved) > 0 {
+		var numbers []string
+		for _, n := range block.reserved {
+			numbers = append(numbers, strconv.Itoa(n))
+		}
+		fmt.Fprintf(b, "  reserved %s;\n", strings.Join(numbers, ", "))
+	}
+	if len(block.reservedNames) > 0 {
+		fmt.Fprintf(b, "  reserved %s;\n", strings.Join(tsStrings(block.reservedNames), ", "))
+	}
+}
+
+// ProtoSchema renders the schema as a proto3 file with a message per table
+// and an enum per enum type. Nullable columns use the well-known wrapper
+// types, or optional for enums, timestamps google.protobuf.Timestamp and
+// intervals google.protobuf.Duration; numeric, date, time and uuid values
+// are strings. Field numbers are taken from previous, the file written the
+// last time if any, so messages stay compatible as the tables change.
+func ProtoSchema(schema *Schema, pkg string, previous []byte) []byte {
+	numbers := parseProtoNumbers(string(previous))
+	taken := make(map[string]bool)
+	messages := make(map[string]string)
+	for _, t := range schema.Tables {
+		name := className(t.Name)
+		for taken[name] {
+			name += "Row"
+		}
+		taken[name] = true
+		messages[t.Name] = name
+	}
+	enums := make(map[string]string)
+	for _, e := range schema.Enums {
+		name := pascalCase(e.Name)
+		if name == "" || name[0] >= '0' && name[0] <= '9' {
+			name = "E" + name
+		}
+		for taken[name] {
+			name += "Enum"
+		}
+		taken[name] = true
+		enums[e.Name] = name
+	}
+
+	imports := make(map[string]bool)
+	fieldType := func(col Column) string {
+		udt := col.ElementType()
+		typ, ok := protoTypes[udt]
+		if !ok {
+			typ = "string"
+		}
+		if enum, isEnum := enums[udt]; isEnum {
+			typ = enum
+		}
+		switch {
+		case col.IsArray():
+			typ = "repeated " + typ
+		case !col.Nullable():
+		case protoWrappers[typ] != "":
+			typ = protoWrappers[typ]
+			imports["google/protobuf/wrappers.proto"] = true
+		case enums[udt] != "":
+			typ = "optional " + typ
+		}
+		if file := protoImports[strings.TrimPrefix(typ, "repeated ")]; file != "" {
+			imports[file] = true
+		}
+		return typ
+	}
+
+	var body strings.Builder
+	for _, e := range schema.Enums {
+		name := enums[e.Name]
+		prefix := strings.ToUpper(snakeCase(name)) + "_"
+		fields := []protoField{{name: prefix + "UNSPECIFIED"}}
+		comments := make(map[string]string)
+		used := map[string]bool{fields[0].name: true}
+		for _, v := range e.Values {
+			value := prefix + strings.TrimPrefix(pythonConstant(v), "V_")
+			for used[value] {
+				value += "_"
+			}
+			used[value] = true
+			if pythonConstant(v) != strings.ToUpper(v) {
+				comments[value] = v
+			}
+			fields = append(fields, protoField{name: value})
+		}
+		block := numbers[name].number(fields, 0)
+		fmt.Fprintf(&body, "\nenum %s {\n", name)
+		block.render(&body)
+		for _, f := range block.fields {
+			fmt.Fprintf(&body, "  %s = %d;", f.name, f.number)
+			if comments[f.name] != "" {
+				fmt.Fprintf(&body, " // %s", oneLine(comments[f.name]))
+			}
+			body.WriteString("\n")
+		}
+		body.WriteString("}\n")
+	}
+	for _, t := range schema.Tables {
+		var fields []protoField
+		comments := make(map[string]string)
+		used := make(map[string]bool)
+		for _, col := range t.Columns {
+			name := snakeCase(col.ColumnName)
+			if name == "" || name[0] >= '0' && name[0] <= '9' {
+				name = "f_" + name
+			}
+			for used[name] {
+				name += "_"
+			}
+			used[name] = true
+			comments[name] = col.Comment
+			fields = append(fields, protoField{name: name, typ: fieldType(col)})
+		}
+		block := numbers[messages[t.Name]].number(fields, 1)
+		body.WriteString("\n")
+		if t.Comment != "" {
+			fmt.Fprintf(&body, "// %s\n", oneLine(t.Comment))
+		}
+		fmt.Fprintf(&body, "message %s {\n", messages[t.Name])
+		block.render(&body)
+		for _, f := range block.fields {
+			if comments[f.name] != "" {
+				fmt.Fprintf(&body, "  // %s\n", oneLine(comments[f.name]))
+			}
+			fmt.Fprintf(&body, "  %s %s = %d;\n", f.typ, f.name, f.number)
+		}
+		body.WriteString("}\n")
+	}
+
+	var b strings.Builder
+	b.WriteString("// Generated by schema transform. Do not edit.\n")
+	b.WriteString("// Field numbers are kept from the previous version of this file.\n")
+	b.WriteString("syntax = \"proto3\";\n")
+	if pkg != "" {
+		fmt.Fprintf(&b, "\npackage %s;\n", pkg)
+	}
+	if len(imports) > 0 {
+		b.WriteString("\n")
+		var files []string
+		for file := range imports {
+			files = append(files, file)
+		}
+		slices.Sort(files)
+		for _, file := range files {
+			fmt.Fprintf(&b, "import %q;\n", file)
+		}
+	}
+	b.WriteString(body.String())
+	return []byte(b.String())
+}
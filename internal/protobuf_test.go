@@ -0,0 +1,115 @@
+package internal
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestProtoBlockNumber(t *testing.T) {
+	tests := []struct {
+		name          string
+		previous      *protoBlock
+		fields        []protoField
+		want          []int
+		reserved      []int
+		reservedNames []string
+	}{
+		{
+			name:   "first version",
+			fields: []protoField{{name: "id", typ: "int64"}, {name: "email", typ: "string"}},
+			want:   []int{1, 2},
+		},
+		{
+			name:     "numbers kept when fields move",
+			previous: &protoBlock{fields: []protoField{{"id", "int64", 1}, {"email", "string", 2}}},
+			fields:   []protoField{{name: "email", typ: "string"}, {name: "id", typ: "int64"}},
+			want:     []int{2, 1},
+		},
+		{
+			name:     "new field after the highest number",
+			previous: &protoBlock{fields: []protoField{{"id", "int64", 1}, {"email", "string", 5}}},
+			fields:   []protoField{{name: "id", typ: "int64"}, {name: "name", typ: "string"}, {name: "email", typ: "string"}},
+			want:     []int{1, 6, 5},
+		},
+		{
+			name:          "dropped field reserved",
+			previous:      &protoBlock{fields: []protoField{{"id", "int64", 1}, {"email", "string", 2}, {"name", "string", 3}}},
+			fields:        []protoField{{name: "id", typ: "int64"}, {name: "name", typ: "string"}},
+			want:          []int{1, 3},
+			reserved:      []int{2},
+			reservedNames: []string{"email"},
+		},
+		{
+			name:     "changed type gets a new number",
+			previous: &protoBlock{fields: []protoField{{"id", "int32", 1}}},
+			fields:   []protoField{{name: "id", typ: "int64"}},
+			want:     []int{2},
+			reserved: []int{1},
+		},
+		{
+			name:     "reserved numbers never reused",
+			previous: &protoBlock{fields: []protoField{{"id", "int64", 1}}, reserved: []int{2, 3}, reservedNames: []string{"email"}},
+			fields:   []protoField{{name: "id", typ: "int64"}, {name: "email", typ: "string"}},
+			want:     []int{1, 4},
+			reserved: []int{2, 3},
+		},
+		{
+			name:     "implementation range skipped",
+			previous: &protoBlock{fields: []protoField{{"id", "int64", 18999}}},
+			fields:   []protoField{{name: "id", typ: "int64"}, {name: "email", typ: "string"}},
+			want:     []int{18999, 20000},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			block := tt.previous.number(tt.fields, 1)
+			var got []int
+			for _, f := range block.fields {
+				got = append(got, f.number)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("numbers = %v, want %v", got, tt.want)
+			}
+			if !slices.Equal(block.reserved, tt.reserved) {
+				t.Errorf("reserved = %v, want %v", block.reserved, tt.reserved)
+			}
+			if !slices.Equal(block.reservedNames, tt.reservedNames) {
+				t.Errorf("reserved names = %q, want %q", block.reservedNames, tt.reservedNames)
+			}
+		})
+	}
+}
+
+func TestProtoSchema(t *testing.T) {
+	golden(t, "proto/schema.proto", ProtoSchema(blogSchema(), "blog.v1", nil))
+}
+
+func TestProtoSchemaKeepsNumbers(t *testing.T) {
+	first := ProtoSchema(blogSchema(), "blog.v1", nil)
+	schema := blogSchemaWith(
+		dropColumn("users", "name"),
+		// Moved to the front, the new column still gets the next number.
+		alterTable("users", func(t *Table) {
+			t.Columns = append([]Column{column("nickname", "text", true)}, t.Columns...)
+		}),
+		addEnumValues("post_status", "archived"),
+	)
+	second := string(ProtoSchema(schema, "blog.v1", first))
+
+	for _, want := range []string{
+		"  reserved 3;\n  reserved \"name\";\n",
+		"  google.protobuf.StringValue nickname = 6;\n",
+		"  int64 id = 1;\n",
+		"  string email = 2;\n",
+		"  POST_STATUS_ARCHIVED = 3;\n",
+		"  POST_STATUS_DRAFT = 1;\n",
+	} {
+		if !strings.Contains(second, want) {
+			t.Errorf("second version is missing %q:\n%s", want, second)
+		}
+	}
+	if third := ProtoSchema(schema, "blog.v1", []byte(second)); string(third) != second {
+		t.Errorf("numbering isn't stable:\n%s\nthen\n%s", second, third)
+	}
+}
@@ -0,0 +1,222 @@
+package internal
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// migrationHistoryTables are the bookkeeping tables migration frameworks
+// keep their applied versions in, keyed by table name.
+var migrationHistoryTables = map[string]string{
+	"schema_migrations":     "golang-migrate",
+	"goose_db_version":      "goose",
+	"flyway_schema_history": "flyway",
+	"alembic_version":       "alembic",
+	"django_migrations":     "django",
+}
+
+// RemoveMigrationHistory drops the migration frameworks' bookkeeping tables
+// from the schema, they are not part of what the migrations describe.
+func RemoveMigrationHistory(s *Schema) {
+	s.Tables = slices.DeleteFunc(s.Tables, func(t Table) bool {
+		_, ok := migrationHistoryTables[t.Name]
+		return ok
+	})
+}
+
+var (
+	flywayVersion = regexp.MustCompile(`^V(\d+(?:[._]\d+)*)__.*\.sql$`)
+	flywayUndo    = regexp.MustCompile(`^U\d+(?:[._]\d+)*__.*\.sql$`)
+	gooseUp       = regexp.MustCompile(`(?m)^--\s*\+goose\s+Up\b`)
+	gooseDown     = regexp.MustCompile(`(?m)^--\s*\+goose\s+Down\b`)
+)
+
+// UpMigrations reads the up scripts of a migrations directory in the order
+// the migration framework applies them. golang-migrate, goose and Flyway
+// directories are recognised by their file names, any other directory is
+// applied as plain .sql files in name order, skipping down scripts. The
+// .sql files that are not applied are listed in the returned warnings.
+func UpMigrations(dir string) ([]File, []string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+	type script struct {
+		version []int64
+		file    File
+	}
+	var golangMigrate, flyway, goose, plain []script
+	var down []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".sql") {
+			if strings.HasSuffix(name, ".py") && name != "__init__.py" {
+				return nil, nil, fmt.Errorf("%s looks like a Python migration, run the framework's migrate command against a scratch database instead", name)
+			}
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read migration: %w", err)
+		}
+		file := File{Name: name, Content: content}
+		switch {
+		case golangMigrateFile.MatchString(name):
+			golangMigrate = append(golangMigrate, script{versionNumbers(golangMigrateFile.FindStringSubmatch(name)[1]), file})
+		case flywayVersion.MatchString(name):
+			flyway = append(flyway, script{versionNumbers(flywayVersion.FindStringSubmatch(name)[1]), file})
+		case gooseFile.MatchString(name) && gooseUp.Match(content):
+			body := content[gooseUp.FindIndex(content)[1]:]
+			if down := gooseDown.FindIndex(body); down != nil {
+				body = body[:down[0]]
+			}
+			file.Content = body
+			goose = append(goose, script{versionNumbers(gooseFile.FindStringSubmatch(name)[1]), file})
+		case isDownScript(filepath.Join(dir, name)):
+			down = append(down, name)
+		default:
+			plain = append(plain, script{nil, file})
+		}
+	}
+
+	var scripts []script
+	var layout string
+	for _, found := range []struct {
+		layout  string
+		scripts []script
+	}{{"golang-migrate", golangMigrate}, {"Flyway", flyway}, {"goose", goose}, {"plain", plain}} {
+		if len(found.scripts) > 0 {
+			scripts, layout = found.scripts, found.layout
+			break
+		}
+	}
+	if len(scripts) == 0 {
+		return nil, nil, fmt.Errorf("no migrations found in %s", dir)
+	}
+	slices.SortStableFunc(scripts, func(a, b script) int {
+		if c := slices.Compare(a.version, b.version); c != 0 {
+			return c
+		}
+		return strings.Compare(a.file.Name, b.file.Name)
+	})
+	files := make([]File, len(scripts))
+	applied := make(map[string]bool)
+	for i, s := range scripts {
+		files[i] = s.file
+		applied[s.file.Name] = true
+	}
+
+	var warnings []string
+	for _, entry := range entries {
+		name := entry.Name()
+		switch {
+		case entry.IsDir() || !strings.HasSuffix(name, ".sql") || applied[name]:
+		case slices.Contains(down, name) || golangMigrateDownFile.MatchString(name) || flywayUndo.MatchString(name):
+			warnings = append(warnings, fmt.Sprintf("skipped %s: down migration", name))
+		default:
+			warnings = append(warnings, fmt.Sprintf("skipped %s: not one of the %s migrations", name, layout))
+		}
+	}
+	return files, warnings, nil
+}
+
+var golangMigrateDownFile = regexp.MustCompile(`^\d+_.*\.down\.sql$`)
+
+// isDownScript reports whether a plain migration reverts another one: its
+// name ends in .down.sql or _down.sql, or it is inside a down directory.
+func isDownScript(path string) bool {
+	name := strings.ToLower(filepath.Base(path))
+	if strings.HasSuffix(name, ".down.sql") || strings.HasSuffix(name, "_down.sql") {
+		return true
+	}
+	abs, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return false
+	}
+	return slices.Contains(strings.Split(filepath.ToSlash(abs), "/"), "down")
+}
+
+// versionNumbers splits a version like "1.2_3" into its numbers so versions
+// compare numerically.
+func versionNumbers(version string) []int64 {
+	var numbers []int64
+	for _, part := range strings.FieldsFunc(version, func(r rune) bool { return r == '.' || r == '_' }) {
+		n, _ := strconv.ParseInt(part, 10, 64)
+		numbers = append(numbers, n)
+	}
+	return numbers
+}
+
+// ReplayMigrations applies the scripts to an empty scratch database one
+// statement at a time, so statements like CREATE INDEX CONCURRENTLY run
+// outside an implicit transaction, and returns the resulting schema.
+func ReplayMigrations(db *sql.DB, scripts []File) (*Schema, error) {
+	existing, err := IntrospectPostgres(db, DefaultSchema)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing.Tables) > 0 || len(existing.Views) > 0 || len(existing.Enums) > 0 {
+		return nil, fmt.Errorf("scratch database is not empty")
+	}
+
+	// A single connection keeps BEGIN and COMMIT of a script on the same
+	// session.
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to scratch database: %w", err)
+	}
+	defer conn.Close()
+	for _, script := range scripts {
+		for _, stmt := range SplitStatements(string(script.Content)) {
+			if _, err := conn.ExecContext(context.Background(), stmt.SQL); err != nil {
+				return nil, fmt.Errorf("failed to apply %s (line %d): %w", script.Name, stmt.Line, err)
+			}
+		}
+	}
+
+	schema, err := IntrospectPostgres(db, DefaultSchema)
+	if err != nil {
+		return nil, err
+	}
+	RemoveMigrationHistory(schema)
+	return schema, nil
+}
+
+// CreateScratchDatabase creates an empty, uniquely named database on the
+// server of the given connection URL. It returns the URL of the new
+// database and a function dropping it again.
+func CreateScratchDatabase(db *sql.DB, databaseURL string) (string, func() error, error) {
+	if !IsDatabaseURL(databaseURL) {
+		return "", nil, fmt.Errorf("a scratch database can only be created for postgres:// URLs")
+	}
+	u, err := url.Parse(databaseURL)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to parse database URL: %w", err)
+	}
+	suffix := make([]byte, 6)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", nil, fmt.Errorf("failed to generate database name: %w", err)
+	}
+	name := "schema_verify_" + hex.EncodeToString(suffix)
+	if _, err := db.Exec("CREATE DATABASE " + quoteIdent(name)); err != nil {
+		return "", nil, fmt.Errorf("failed to create scratch database: %w", err)
+	}
+	u.Path = "/" + name
+	drop := func() error {
+		if _, err := db.Exec("DROP DATABASE IF EXISTS " + quoteIdent(name)); err != nil {
+			return fmt.Errorf("failed to drop scratch database %s: %w", name, err)
+		}
+		return nil
+	}
+	return u.String(), drop, nil
+}
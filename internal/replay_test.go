@@ -0,0 +1,133 @@
+package internal
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// migrationsDir writes the files, name to content, into a directory.
+func migrationsDir(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := writeFile(filepath.Join(dir, name), []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestUpMigrations(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		want     []string
+		content  string
+		warnings []string
+	}{
+		{
+			name: "golang-migrate",
+			files: map[string]string{
+				"000010_orders.up.sql": "", "000010_orders.down.sql": "",
+				"000002_users.up.sql": "", "000002_users.down.sql": "",
+			},
+			want:     []string{"000002_users.up.sql", "000010_orders.up.sql"},
+			warnings: []string{"skipped 000002_users.down.sql: down migration", "skipped 000010_orders.down.sql: down migration"},
+		},
+		{
+			name:     "flyway",
+			files:    map[string]string{"V1_10__orders.sql": "", "V1_2__users.sql": "", "U1_2__users.sql": "", "notes.sql": ""},
+			want:     []string{"V1_2__users.sql", "V1_10__orders.sql"},
+			warnings: []string{"skipped U1_2__users.sql: down migration", "skipped notes.sql: not one of the Flyway migrations"},
+		},
+		{
+			name: "goose up section",
+			files: map[string]string{
+				"00001_users.sql": "-- +goose Up\nCREATE TABLE users ();\n-- +goose Down\nDROP TABLE users;\n",
+			},
+			want:    []string{"00001_users.sql"},
+			content: "\nCREATE TABLE users ();\n",
+		},
+		{
+			name: "plain, down scripts by suffix only",
+			files: map[string]string{
+				"002_markdown_support.sql": "", "001_init.sql": "", "003_slowdown_log.sql": "",
+				"001_init_down.sql": "", "002_markdown_support.down.sql": "",
+			},
+			want: []string{"001_init.sql", "002_markdown_support.sql", "003_slowdown_log.sql"},
+			warnings: []string{
+				"skipped 001_init_down.sql: down migration",
+				"skipped 002_markdown_support.down.sql: down migration",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, warnings, err := UpMigrations(migrationsDir(t, tt.files))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := fileNames(files); !slices.Equal(got, tt.want) {
+				t.Errorf("migrations = %q, want %q", got, tt.want)
+			}
+			if tt.content != "" && string(files[0].Content) != tt.content {
+				t.Errorf("content = %q, want %q", files[0].Content, tt.content)
+			}
+			if !slices.Equal(warnings, tt.warnings) {
+				t.Errorf("warnings = %q, want %q", warnings, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestUpMigrationsErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		err   string
+	}{
+		{"python migrations", map[string]string{"0001_initial.py": "", "__init__.py": ""}, "looks like a Python migration"},
+		{"only down scripts", map[string]string{"001_init.down.sql": ""}, "no migrations found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := UpMigrations(migrationsDir(t, tt.files))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("err = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestIsDownScript(t *testing.T) {
+	tests := map[string]bool{
+		"migrations/001_init.sql":          false,
+		"migrations/002_markdown.sql":      false,
+		"migrations/003_countdown.sql":     false,
+		"migrations/001_init.down.sql":     true,
+		"migrations/001_init_DOWN.sql":     true,
+		"migrations/down/001_init.sql":     true,
+		"migrations/downgrades/001_x.sql":  false,
+		"migrations/up/001_init.down.sql":  true,
+		"migrations/up/001_init_down.psql": false,
+	}
+	for path, want := range tests {
+		if got := isDownScript(filepath.FromSlash(path)); got != want {
+			t.Errorf("isDownScript(%s) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestRemoveMigrationHistory(t *testing.T) {
+	schema := blogSchemaWith(addTable(Table{Name: "schema_migrations"}), addTable(Table{Name: "goose_db_version"}))
+	RemoveMigrationHistory(schema)
+	var names []string
+	for _, table := range schema.Tables {
+		names = append(names, table.Name)
+	}
+	if want := []string{"users", "posts", "tags", "post_tags"}; !slices.Equal(names, want) {
+		t.Errorf("tables = %q, want %q", names, want)
+	}
+}
@@ -8,295 +8,326 @@ import (
 	"net/http"
 	"os"
 	"runtime"
+	"strings"
 
 	"github.com/Ayobami6/schema_dump/utils"
 	"github.com/zalando/go-keyring"
 )
 
-type Column struct {
-	ColumnName string
-	DataType   string
-	IsNullable string
+// DumpOptions configures PostgresSchemaDump.
+type DumpOptions struct {
+	// TableName limits the dump to one table when set.
+	TableName string
+	// Dialect selects the SQL flavour of the DDL, see SupportedDialects.
+	Dialect string
+	// Format is "sql" for DDL, "json" for a schema snapshot, "jsonschema"
+	// for a JSON Schema per table, "openapi" for OpenAPI components,
+	// "graphql" for GraphQL SDL, "proto" for proto3 messages or "avro" for
+	// an Avro record schema per table.
+	Format string
+	// Package is the package of proto messages and the namespace of Avro
+	// records.
+	Package string
+	Output  Output
 }
 
-type ForeignKey struct {
-	SourceTable    string
-	SourceColumn   string
-	TargetTable    string
-	TargetColumn   string
-	ConstraintName string
-}
-
-func PostgresSchemaDump(db *sql.DB, tableName string) {
-	if tableName != "" {
-		dumpTableSchema(db, tableName)
-	} else {
-		dumpAllSchemas(db)
-	}
-}
-
-func dumpTableSchema(db *sql.DB, tableName string) {
-	rows, err := db.Query(`
-        SELECT table_name, column_name, data_type, is_nullable
-        FROM information_schema.columns
-        WHERE table_schema = 'public'
-        ORDER BY table_name, ordinal_position;
-    `)
+// PostgresSchemaDump writes the CREATE statements of the public schema, or of
+// a single table, to the output (schema.sql by default). Objects are emitted
+// in dependency order so the file can be replayed into an empty database.
+// With the json format the introspected model is written instead, as a
+// snapshot for diff and check, and the jsonschema, openapi, graphql, proto
+// and avro formats describe the rows of the tables.
+func PostgresSchemaDump(db *sql.DB, opts DumpOptions) error {
+	schema, err := IntrospectPostgres(db, DefaultSchema)
 	if err != nil {
-		log.Fatal(err)
+		return err
+	}
+	if opts.TableName != "" {
+		if schema.Table(opts.TableName) == nil {
+			return fmt.Errorf("table %s not found", opts.TableName)
+		}
+		schema = schema.Filter(opts.TableName)
 	}
-	defer rows.Close()
-	schema := make(map[string][]Column)
 
-	for rows.Next() {
-		var table, colName, dataType, nullable string
-		err := rows.Scan(&table, &colName, &dataType, &nullable)
+	switch opts.Format {
+	case "json":
+		if opts.Output.Split {
+			return fmt.Errorf("--split is not supported for json snapshots")
+		}
+		data, err := MarshalSnapshot(schema)
 		if err != nil {
-			log.Fatal(err)
+			return err
 		}
-
-		if table == tableName {
-			schema[table] = append(schema[table], Column{
-				ColumnName: colName,
-				DataType:   dataType,
-				IsNullable: nullable,
-			})
+		return opts.Output.Write([]File{{Name: "schema.json", Content: data}})
+	case "jsonschema":
+		files, err := JSONSchemaFiles(schema)
+		if err != nil {
+			return err
 		}
-
-	}
-
-	outFile, err := os.Create("schema.sql")
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer outFile.Close()
-
-	pkRows, err := db.Query(`
-        SELECT tc.table_name, kcu.column_name
-			FROM information_schema.table_constraints AS tc
-			JOIN information_schema.key_column_usage AS kcu
-			ON tc.constraint_name = kcu.constraint_name
-			AND tc.table_schema = kcu.table_schema
-			WHERE tc.constraint_type = 'PRIMARY KEY'
-			AND tc.table_schema = 'public';
-
-    `)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer pkRows.Close()
-
-	fkRows, err := db.Query(`
-    SELECT
-        tc.table_name AS source_table,
-        kcu.column_name AS source_column,
-        ccu.table_name AS target_table,
-        ccu.column_name AS target_column,
-        tc.constraint_name
-    FROM information_schema.table_constraints AS tc
-    JOIN information_schema.key_column_usage AS kcu
-        ON tc.constraint_name = kcu.constraint_name
-        AND tc.table_schema = kcu.table_schema
-    JOIN information_schema.constraint_column_usage AS ccu
-        ON ccu.constraint_name = tc.constraint_name
-        AND ccu.table_schema = tc.table_schema
-    WHERE tc.constraint_type = 'FOREIGN KEY'
-      AND tc.table_schema = 'public';
-`)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer fkRows.Close()
-
-	foreignKeys := make(map[string][]ForeignKey)
-	for fkRows.Next() {
-		var fk ForeignKey
-		if err := fkRows.Scan(&fk.SourceTable, &fk.SourceColumn, &fk.TargetTable, &fk.TargetColumn, &fk.ConstraintName); err != nil {
-			log.Fatal(err)
+		if len(files) > 1 && opts.Output.Path != "" && !opts.Output.isDir() {
+			return fmt.Errorf("--format jsonschema writes a file per table, --out must be a directory")
 		}
-
-		foreignKeys[fk.SourceTable] = append(foreignKeys[fk.SourceTable], fk)
-	}
-
-	primaryKeys := make(map[string][]string)
-	for pkRows.Next() {
-		var table, col string
-		err := pkRows.Scan(&table, &col)
+		return opts.Output.Write(files)
+	case "openapi":
+		if opts.Output.Split {
+			return fmt.Errorf("--split is not supported for openapi documents")
+		}
+		data, err := OpenAPIComponents(schema)
 		if err != nil {
-			log.Fatal(err)
+			return err
 		}
-		primaryKeys[table] = append(primaryKeys[table], col)
-	}
-
-	for table, columns := range schema {
-		fmt.Fprintf(outFile, "CREATE TABLE %s (\n", table)
-		for _, col := range columns {
-			null := "NOT NULL"
-			if col.IsNullable == "YES" {
-				null = "NULL"
-			}
-			comma := ","
-			// if i == len(columns)-1 {
-			// 	comma = ""
-			// }
-			fmt.Fprintf(outFile, "    %s %s %s%s\n", col.ColumnName, col.DataType, null, comma)
+		return opts.Output.Write([]File{{Name: "openapi.json", Content: data}})
+	case "graphql":
+		if opts.Output.Split {
+			return fmt.Errorf("--split is not supported for graphql schemas")
+		}
+		return opts.Output.Write([]File{{Name: "schema.graphql", Content: GraphQLSchema(schema)}})
+	case "proto":
+		if opts.Output.Split {
+			return fmt.Errorf("--split is not supported for proto files")
 		}
-		if fks, ok := foreignKeys[table]; ok {
-			for _, fk := range fks {
-				fmt.Fprintf(outFile, "    CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s(%s),\n",
-					fk.ConstraintName, fk.SourceColumn, fk.TargetTable, fk.TargetColumn)
+		// The file written last time holds the field numbers to keep.
+		var previous []byte
+		if path := opts.Output.Target("schema.proto"); path != "" {
+			previous, err = os.ReadFile(path)
+			if err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to read %s: %w", path, err)
 			}
 		}
-		// Add primary key constraint
-		if pkCols, ok := primaryKeys[table]; ok {
-			fmt.Fprintf(outFile, "    PRIMARY KEY (%s)\n", join(pkCols, ", "))
+		return opts.Output.Write([]File{{Name: "schema.proto", Content: ProtoSchema(schema, opts.Package, previous)}})
+	case "avro":
+		files, err := AvroSchemaFiles(schema, opts.Package)
+		if err != nil {
+			return err
+		}
+		if len(files) > 1 && opts.Output.Path != "" && !opts.Output.isDir() {
+			return fmt.Errorf("--format avro writes a file per table, --out must be a directory")
 		}
-
-		fmt.Fprintln(outFile, ");\n")
+		return opts.Output.Write(files)
 	}
 
-	fmt.Println("Schema written to schema.sql")
+	files, warnings, err := schemaFiles(schema, opts.Dialect, opts.Output.Split)
+	if err != nil {
+		return err
+	}
+	for _, warning := range warnings {
+		log.Printf("Warning: %s", warning)
+	}
+	return opts.Output.Write(files)
 }
 
-func dumpAllSchemas(db *sql.DB) {
-	rows, err := db.Query(`
-        SELECT table_name, column_name, data_type, is_nullable
-        FROM information_schema.columns
-        WHERE table_schema = 'public'
-        ORDER BY table_name, ordinal_position;
-    `)
+// schemaFiles renders the schema as the files of a dump, schema.sql or one
+// file per object when split.
+func schemaFiles(schema *Schema, dialect string, split bool) ([]File, []string, error) {
+	statements, warnings, err := RenderDDL(schema, dialect)
 	if err != nil {
-		log.Fatal(err)
+		return nil, nil, err
 	}
-	defer rows.Close()
-	schema := make(map[string][]Column)
-
-	for rows.Next() {
-		var table, colName, dataType, nullable string
-		err := rows.Scan(&table, &colName, &dataType, &nullable)
-		if err != nil {
-			log.Fatal(err)
+	files := DDLFiles(statements, split, "schema.sql")
+	if (dialect == "" || dialect == "postgres") && len(schema.Functions) > 0 {
+		// Without split there's only schema.sql; with it every function
+		// file may be replayed on its own.
+		for i := range files {
+			if !split || strings.HasPrefix(files[i].Name, objectDirs[KindFunction]+"/") {
+				files[i].Content = append([]byte(disableFunctionBodyChecks), files[i].Content...)
+			}
 		}
+	}
+	return files, warnings, nil
+}
 
-		schema[table] = append(schema[table], Column{
-			ColumnName: colName,
-			DataType:   dataType,
-			IsNullable: nullable,
+// disableFunctionBodyChecks heads Postgres dumps with functions, as in
+// pg_dump: functions are created before the tables their bodies use, which
+// CREATE FUNCTION would otherwise reject.
+const disableFunctionBodyChecks = "SET check_function_bodies = false;\n"
+
+// postgresStatements renders the schema as Postgres DDL in creation order,
+// followed by the foreign keys that had to be deferred to break cycles.
+func postgresStatements(schema *Schema) []DDLStatement {
+	order := OrderSchema(schema)
+	var statements []DDLStatement
+	for _, obj := range order.Objects {
+		statements = append(statements, DDLStatement{
+			Kind: obj.Kind,
+			Name: schema.objectName(obj),
+			SQL:  renderPostgresObject(schema, order, obj),
 		})
 	}
-
-	outFile, err := os.Create("schema.sql")
-	if err != nil {
-		log.Fatal(err)
+	for _, fk := range order.DeferredForeignKeys {
+		statements = append(statements, DDLStatement{
+			Kind: KindForeignKey,
+			Name: fk.SourceTable + "." + fk.ConstraintName,
+			SQL:  renderDeferredForeignKey(fk),
+		})
 	}
-	defer outFile.Close()
+	return statements
+}
 
-	pkRows, err := db.Query(`
-        SELECT tc.table_name, kcu.column_name
-			FROM information_schema.table_constraints AS tc
-			JOIN information_schema.key_column_usage AS kcu
-			ON tc.constraint_name = kcu.constraint_name
-			AND tc.table_schema = kcu.table_schema
-			WHERE tc.constraint_type = 'PRIMARY KEY'
-			AND tc.table_schema = 'public';
+func renderPostgresObject(schema *Schema, order *CreationOrder, obj SchemaObject) string {
+	switch obj.Kind {
+	case KindEnum:
+		return renderEnum(schema.Enums[obj.Index])
+	case KindFunction:
+		return schema.Functions[obj.Index].Definition + ";\n"
+	case KindView:
+		return renderView(schema.Views[obj.Index])
+	default:
+		return renderTable(schema.Tables[obj.Index], order)
+	}
+}
 
-    `)
-	if err != nil {
-		log.Fatal(err)
+func renderEnum(e Enum) string {
+	values := make([]string, len(e.Values))
+	for i, v := range e.Values {
+		values[i] = quoteLiteral(v)
 	}
-	defer pkRows.Close()
-
-	fkRows, err := db.Query(`
-    SELECT
-        tc.table_name AS source_table,
-        kcu.column_name AS source_column,
-        ccu.table_name AS target_table,
-        ccu.column_name AS target_column,
-        tc.constraint_name
-    FROM information_schema.table_constraints AS tc
-    JOIN information_schema.key_column_usage AS kcu
-        ON tc.constraint_name = kcu.constraint_name
-        AND tc.table_schema = kcu.table_schema
-    JOIN information_schema.constraint_column_usage AS ccu
-        ON ccu.constraint_name = tc.constraint_name
-        AND ccu.table_schema = tc.table_schema
-    WHERE tc.constraint_type = 'FOREIGN KEY'
-      AND tc.table_schema = 'public';
-`)
-	if err != nil {
-		log.Fatal(err)
+	return fmt.Sprintf("CREATE TYPE %s AS ENUM (%s);\n", quoteIdent(e.Name), strings.Join(values, ", "))
+}
+
+func renderView(v View) string {
+	kind := "VIEW"
+	if v.Materialized {
+		kind = "MATERIALIZED VIEW"
 	}
-	defer fkRows.Close()
+	definition := strings.TrimSuffix(strings.TrimSpace(v.Definition), ";")
+	return fmt.Sprintf("CREATE %s %s AS\n%s;\n", kind, quoteIdent(v.Name), definition)
+}
 
-	foreignKeys := make(map[string][]ForeignKey)
-	for fkRows.Next() {
-		var fk ForeignKey
-		if err := fkRows.Scan(&fk.SourceTable, &fk.SourceColumn, &fk.TargetTable, &fk.TargetColumn, &fk.ConstraintName); err != nil {
-			log.Fatal(err)
+func renderTable(t Table, order *CreationOrder) string {
+	var lines []string
+	for _, col := range t.Columns {
+		lines = append(lines, "    "+renderColumn(col))
+	}
+	if t.PrimaryKey != nil {
+		lines = append(lines, fmt.Sprintf("    CONSTRAINT %s PRIMARY KEY (%s)",
+			quoteIdent(t.PrimaryKey.ConstraintName), quoteIdents(t.PrimaryKey.Columns)))
+	}
+	for _, u := range t.Uniques {
+		lines = append(lines, fmt.Sprintf("    CONSTRAINT %s UNIQUE (%s)",
+			quoteIdent(u.ConstraintName), quoteIdents(u.Columns)))
+	}
+	for _, fk := range t.ForeignKeys {
+		if order != nil && order.IsDeferred(fk) {
+			continue
 		}
-		foreignKeys[fk.SourceTable] = append(foreignKeys[fk.SourceTable], fk)
+		lines = append(lines, "    "+renderForeignKey(fk))
+	}
+	for _, c := range t.Checks {
+		lines = append(lines, fmt.Sprintf("    CONSTRAINT %s %s", quoteIdent(c.ConstraintName), checkClause(c.Expression)))
 	}
 
-	primaryKeys := make(map[string][]string)
-	for pkRows.Next() {
-		var table, col string
-		err := pkRows.Scan(&table, &col)
-		if err != nil {
-			log.Fatal(err)
+	var b strings.Builder
+	fmt.Fprintf(&b, "CREATE TABLE %s (\n%s\n);\n", quoteIdent(t.Name), strings.Join(lines, ",\n"))
+	for _, idx := range t.Indexes {
+		fmt.Fprintf(&b, "%s;\n", idx.Definition)
+	}
+	if t.Comment != "" {
+		fmt.Fprintf(&b, "COMMENT ON TABLE %s IS %s;\n", quoteIdent(t.Name), quoteLiteral(t.Comment))
+	}
+	for _, col := range t.Columns {
+		if col.Comment != "" {
+			fmt.Fprintf(&b, "COMMENT ON COLUMN %s.%s IS %s;\n",
+				quoteIdent(t.Name), quoteIdent(col.ColumnName), quoteLiteral(col.Comment))
 		}
-		primaryKeys[table] = append(primaryKeys[table], col)
 	}
+	return b.String()
+}
 
-	for table, columns := range schema {
-		fmt.Fprintf(outFile, "CREATE TABLE %s (\n", table)
-		for _, col := range columns {
-			null := "NOT NULL"
-			if col.IsNullable == "YES" {
-				null = "NULL"
-			}
-			comma := ","
-			// if i == len(columns)-1 {
-			// 	comma = ""
-			// }
-			fmt.Fprintf(outFile, "    %s %s %s%s\n", col.ColumnName, col.DataType, null, comma)
-		}
-		if fks, ok := foreignKeys[table]; ok {
-			for _, fk := range fks {
-				fmt.Fprintf(outFile, "    CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s(%s),\n",
-					fk.ConstraintName, fk.SourceColumn, fk.TargetTable, fk.TargetColumn)
-			}
-		}
-		// Add primary key constraint
-		if pkCols, ok := primaryKeys[table]; ok {
-			fmt.Fprintf(outFile, "    PRIMARY KEY (%s)\n", join(pkCols, ", "))
-		}
+// serialTypes maps integer types to the serial pseudo-type that creates the
+// backing sequence, so a dumped nextval() default doesn't point at nothing.
+var serialTypes = map[string]string{
+	"smallint": "smallserial",
+	"integer":  "serial",
+	"bigint":   "bigserial",
+}
 
-		fmt.Fprintln(outFile, ");\n")
+func renderColumn(col Column) string {
+	dataType := col.FullType
+	def := col.Default
+	if serial, ok := serialTypes[dataType]; ok && col.IsSerial() {
+		dataType, def = serial, ""
+	}
+	parts := []string{quoteIdent(col.ColumnName), dataType}
+	if col.IsIdentity {
+		parts = append(parts, "GENERATED "+col.IdentityGeneration+" AS IDENTITY")
+	}
+	if def != "" {
+		parts = append(parts, "DEFAULT "+def)
 	}
+	null := "NOT NULL"
+	if col.Nullable() {
+		null = "NULL"
+	}
+	return strings.Join(append(parts, null), " ")
+}
 
-	fmt.Println("Schema written to schema.sql")
+// checkClause renders the CHECK clause of a constraint expression.
+// pg_get_expr parenthesizes comparisons but leaves a bare function call,
+// as in valid_balance(balance), so the parentheses are always added.
+func checkClause(expr string) string {
+	return "CHECK (" + stripParens(expr) + ")"
 }
 
-func join(slice []string, sep string) string {
-	out := ""
-	for i, s := range slice {
-		out += s
-		if i < len(slice)-1 {
-			out += sep
+// stripParens removes the parentheses wrapping a whole expression, as
+// pg_get_expr adds them around CHECK bodies and defaults.
+func stripParens(s string) string {
+	s = strings.TrimSpace(s)
+	for strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		depth, quoted := 0, false
+		for i := 0; i < len(s); i++ {
+			switch c := s[i]; {
+			case c == '\'':
+				quoted = !quoted
+			case quoted:
+			case c == '(':
+				depth++
+			case c == ')':
+				depth--
+			}
+			if depth == 0 && i < len(s)-1 {
+				return s
+			}
 		}
+		s = strings.TrimSpace(s[1 : len(s)-1])
+	}
+	return s
+}
+
+func renderForeignKey(fk ForeignKey) string {
+	clause := fmt.Sprintf("CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s(%s)",
+		quoteIdent(fk.ConstraintName), quoteIdents(fk.SourceColumns),
+		quoteIdent(fk.TargetTable), quoteIdents(fk.TargetColumns))
+	if fk.OnDelete != "" && fk.OnDelete != "NO ACTION" {
+		clause += " ON DELETE " + fk.OnDelete
+	}
+	if fk.OnUpdate != "" && fk.OnUpdate != "NO ACTION" {
+		clause += " ON UPDATE " + fk.OnUpdate
 	}
-	return out
+	return clause
+}
+
+func renderDeferredForeignKey(fk ForeignKey) string {
+	return fmt.Sprintf("ALTER TABLE %s ADD %s;\n", quoteIdent(fk.SourceTable), renderForeignKey(fk))
 }
 
 // transformToORMModel takes a language and transforms the SQL schema to the ORM model
 // It uses the AzureAIClient to send a request to the Azure OpenAI API
-func TransformToORMModel(lang, tableName string, db *sql.DB) error {
-	hasDumped := make(chan bool, 1)
+func TransformToORMModel(lang, tableName string, db *sql.DB, out Output) error {
+	hasDumped := make(chan error, 1)
+	var schemaDDL strings.Builder
 	// Generate the schema
 	go func() {
-		dumpTableSchema(db, tableName)
-		hasDumped <- true
-
+		schema, err := IntrospectPostgres(db, DefaultSchema)
+		if err != nil {
+			hasDumped <- err
+			return
+		}
+		if schema.Table(tableName) == nil {
+			hasDumped <- fmt.Errorf("table %s not found", tableName)
+			return
+		}
+		_, err = WriteDDL(&schemaDDL, schema.Filter(tableName), "postgres")
+		hasDumped <- err
 	}()
 
 	osName := runtime.GOOS
@@ -353,14 +384,11 @@ func TransformToORMModel(lang, tableName string, db *sql.DB) error {
 		}
 	}
 	// makes sure the dumpschema goroutine completes before sending prompt
-	<-hasDumped
-	// Read the schema from the file
-	schema, err := os.ReadFile("schema.sql")
-	if err != nil {
-		return fmt.Errorf("failed to read schema file: %w", err)
+	if err := <-hasDumped; err != nil {
+		return fmt.Errorf("failed to dump schema: %w", err)
 	}
 	// Create the prompt
-	prompt := fmt.Sprintf("Transform the following SQL schema to %s ORM model:\n%s", lang, string(schema))
+	prompt := fmt.Sprintf("Transform the following SQL schema to %s ORM model:\n%s", lang, schemaDDL.String())
 
 	// Create the AzureAIClient
 	client := utils.NewAzureAIClient("https://models.github.ai/inference/chat/completions", apiKey)
@@ -370,16 +398,12 @@ func TransformToORMModel(lang, tableName string, db *sql.DB) error {
 		log.Printf("Error creating completions: %v", err)
 		return fmt.Errorf("failed to create completions: %w", err)
 	}
-	// Write the response to a file
-	ormModelFile, err := os.Create("orm_model.md")
-	if err != nil {
-		log.Printf("Error creating ORM model file: %v", err)
-		return fmt.Errorf("failed to create ORM model file: %w", err)
-	}
-	_, err = ormModelFile.WriteString(response.(map[string]interface{})["choices"].([]interface{})[0].(map[string]interface{})["message"].(map[string]interface{})["content"].(string))
+	// Write the response to the output
+	content := response.(map[string]interface{})["choices"].([]interface{})[0].(map[string]interface{})["message"].(map[string]interface{})["content"].(string)
+	err = out.Write([]File{{Name: "orm_model.md", Content: []byte(content)}})
 	if err != nil {
-		log.Printf("Error writing to ORM model file: %v", err)
-		return fmt.Errorf("failed to write to ORM model file: %w", err)
+		log.Printf("Error writing ORM model: %v", err)
+		return fmt.Errorf("failed to write ORM model: %w", err)
 	}
 	return nil
 
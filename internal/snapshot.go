@@ -0,0 +1,53 @@
+package internal
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// MarshalSnapshot returns the schema model as indented JSON. Snapshots are
+// what diff, check and migrate-plan read when they are not given a live
+// database.
+func MarshalSnapshot(schema *Schema) ([]byte, error) {
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// ReadSnapshot reads a schema model written by MarshalSnapshot.
+func ReadSnapshot(path string) (*Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	var schema Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot %s: %w", path, err)
+	}
+	return &schema, nil
+}
+
+// IsDatabaseURL reports whether source is a Postgres connection URL rather
+// than a snapshot path.
+func IsDatabaseURL(source string) bool {
+	return strings.HasPrefix(source, "postgres://") || strings.HasPrefix(source, "postgresql://")
+}
+
+// LoadSchema reads a schema model from a Postgres connection URL or from a
+// snapshot file.
+func LoadSchema(source string) (*Schema, error) {
+	if !IsDatabaseURL(source) {
+		return ReadSnapshot(source)
+	}
+	db, err := sql.Open("postgres", source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	defer db.Close()
+	return IntrospectPostgres(db, DefaultSchema)
+}
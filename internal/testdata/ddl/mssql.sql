@@ -0,0 +1,43 @@
+CREATE TABLE [tags] (
+    [id] INT IDENTITY(1,1) NOT NULL,
+    [name] NVARCHAR(450) NOT NULL,
+    CONSTRAINT [tags_pkey] PRIMARY KEY ([id])
+);
+CREATE UNIQUE INDEX [tags_name_key] ON [tags] ([name]);
+
+CREATE TABLE [users] (
+    [id] BIGINT IDENTITY(1,1) NOT NULL,
+    [email] NVARCHAR(450) NOT NULL,
+    [name] NVARCHAR(100) NULL,
+    [bio] NVARCHAR(MAX) DEFAULT '' NOT NULL,
+    [created_at] DATETIMEOFFSET DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CONSTRAINT [users_pkey] PRIMARY KEY ([id]),
+    CONSTRAINT [users_email_key] UNIQUE ([email])
+);
+
+CREATE TABLE [posts] (
+    [id] BIGINT IDENTITY(1,1) NOT NULL,
+    [author_id] BIGINT NOT NULL,
+    [editor_id] BIGINT NULL,
+    [type] NVARCHAR(MAX) DEFAULT 'post' NOT NULL,
+    [status] NVARCHAR(9) DEFAULT 'draft' NOT NULL,
+    [title] NVARCHAR(200) NOT NULL,
+    [rating] INT NULL,
+    CONSTRAINT [posts_pkey] PRIMARY KEY ([id]),
+    CONSTRAINT [posts_author_id_fkey] FOREIGN KEY ([author_id]) REFERENCES [users] ([id]) ON DELETE CASCADE,
+    CONSTRAINT [posts_editor_id_fkey] FOREIGN KEY ([editor_id]) REFERENCES [users] ([id]),
+    CONSTRAINT [posts_type_check] CHECK ([type] IN ('post', 'page')),
+    CONSTRAINT [posts_rating_check] CHECK (([rating] >= 1) AND ([rating] <= 5)),
+    CONSTRAINT [posts_title_check] CHECK (LEN(([title])) > 0),
+    CHECK ([status] IN ('draft', 'published'))
+);
+CREATE INDEX [posts_author_id_idx] ON [posts] ([author_id]);
+
+CREATE TABLE [post_tags] (
+    [post_id] BIGINT NOT NULL,
+    [tag_id] INT NOT NULL,
+    CONSTRAINT [post_tags_pkey] PRIMARY KEY ([post_id], [tag_id]),
+    CONSTRAINT [post_tags_post_id_fkey] FOREIGN KEY ([post_id]) REFERENCES [posts] ([id]) ON DELETE CASCADE,
+    CONSTRAINT [post_tags_tag_id_fkey] FOREIGN KEY ([tag_id]) REFERENCES [tags] ([id]) ON DELETE CASCADE
+);
+
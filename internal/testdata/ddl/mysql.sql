@@ -0,0 +1,42 @@
+CREATE TABLE `tags` (
+    `id` INT AUTO_INCREMENT NOT NULL,
+    `name` VARCHAR(255) NOT NULL,
+    CONSTRAINT `tags_pkey` PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+CREATE UNIQUE INDEX `tags_name_key` ON `tags` (`name`);
+
+CREATE TABLE `users` (
+    `id` BIGINT AUTO_INCREMENT NOT NULL,
+    `email` VARCHAR(255) NOT NULL,
+    `name` VARCHAR(100) NULL,
+    `bio` LONGTEXT DEFAULT ('') NOT NULL,
+    `created_at` DATETIME(6) DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CONSTRAINT `users_pkey` PRIMARY KEY (`id`),
+    CONSTRAINT `users_email_key` UNIQUE (`email`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='People who write posts';
+
+CREATE TABLE `posts` (
+    `id` BIGINT AUTO_INCREMENT NOT NULL,
+    `author_id` BIGINT NOT NULL,
+    `editor_id` BIGINT NULL,
+    `type` LONGTEXT DEFAULT ('post') NOT NULL,
+    `status` ENUM('draft', 'published') DEFAULT 'draft' NOT NULL,
+    `title` VARCHAR(200) NOT NULL,
+    `rating` INT NULL,
+    CONSTRAINT `posts_pkey` PRIMARY KEY (`id`),
+    CONSTRAINT `posts_author_id_fkey` FOREIGN KEY (`author_id`) REFERENCES `users` (`id`) ON DELETE CASCADE,
+    CONSTRAINT `posts_editor_id_fkey` FOREIGN KEY (`editor_id`) REFERENCES `users` (`id`) ON DELETE SET NULL,
+    CONSTRAINT `posts_type_check` CHECK (`type` IN ('post', 'page')),
+    CONSTRAINT `posts_rating_check` CHECK ((`rating` >= 1) AND (`rating` <= 5)),
+    CONSTRAINT `posts_title_check` CHECK (CHAR_LENGTH((`title`)) > 0)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+CREATE INDEX `posts_author_id_idx` ON `posts` (`author_id`);
+
+CREATE TABLE `post_tags` (
+    `post_id` BIGINT NOT NULL,
+    `tag_id` INT NOT NULL,
+    CONSTRAINT `post_tags_pkey` PRIMARY KEY (`post_id`, `tag_id`),
+    CONSTRAINT `post_tags_post_id_fkey` FOREIGN KEY (`post_id`) REFERENCES `posts` (`id`) ON DELETE CASCADE,
+    CONSTRAINT `post_tags_tag_id_fkey` FOREIGN KEY (`tag_id`) REFERENCES `tags` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
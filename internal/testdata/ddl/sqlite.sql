@@ -0,0 +1,40 @@
+CREATE TABLE "tags" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "name" TEXT NOT NULL
+);
+CREATE UNIQUE INDEX "tags_name_key" ON "tags" ("name");
+
+CREATE TABLE "users" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "email" TEXT NOT NULL,
+    "name" VARCHAR(100) NULL,
+    "bio" TEXT DEFAULT '' NOT NULL,
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CONSTRAINT "users_email_key" UNIQUE ("email")
+);
+
+CREATE TABLE "posts" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "author_id" INTEGER NOT NULL,
+    "editor_id" INTEGER NULL,
+    "type" TEXT DEFAULT 'post' NOT NULL,
+    "status" TEXT DEFAULT 'draft' NOT NULL,
+    "title" VARCHAR(200) NOT NULL,
+    "rating" INTEGER NULL,
+    CONSTRAINT "posts_author_id_fkey" FOREIGN KEY ("author_id") REFERENCES "users" ("id") ON DELETE CASCADE,
+    CONSTRAINT "posts_editor_id_fkey" FOREIGN KEY ("editor_id") REFERENCES "users" ("id") ON DELETE SET NULL,
+    CONSTRAINT "posts_type_check" CHECK ("type" IN ('post', 'page')),
+    CONSTRAINT "posts_rating_check" CHECK (("rating" >= 1) AND ("rating" <= 5)),
+    CONSTRAINT "posts_title_check" CHECK (LENGTH(("title")) > 0),
+    CHECK ("status" IN ('draft', 'published'))
+);
+CREATE INDEX "posts_author_id_idx" ON "posts" ("author_id");
+
+CREATE TABLE "post_tags" (
+    "post_id" INTEGER NOT NULL,
+    "tag_id" INTEGER NOT NULL,
+    CONSTRAINT "post_tags_pkey" PRIMARY KEY ("post_id", "tag_id"),
+    CONSTRAINT "post_tags_post_id_fkey" FOREIGN KEY ("post_id") REFERENCES "posts" ("id") ON DELETE CASCADE,
+    CONSTRAINT "post_tags_tag_id_fkey" FOREIGN KEY ("tag_id") REFERENCES "tags" ("id") ON DELETE CASCADE
+);
+
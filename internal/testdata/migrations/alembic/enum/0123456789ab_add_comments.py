@@ -0,0 +1,36 @@
+"""add comments
+
+Revision ID: 0123456789ab
+Revises: 
+Create Date: 2026-10-01 12:30:00.000000
+
+"""
+from alembic import op
+
+
+# revision identifiers, used by Alembic.
+revision = '0123456789ab'
+down_revision = None
+branch_labels = None
+depends_on = None
+
+
+def upgrade():
+    with op.get_context().autocommit_block():
+        op.execute("""ALTER TYPE post_status ADD VALUE 'archived' AFTER 'published';""")
+    op.execute("""CREATE TABLE comments (
+    id bigint NOT NULL,
+    post_id bigint NOT NULL,
+    CONSTRAINT comments_pkey PRIMARY KEY (id)
+);""")
+    op.execute("""ALTER TABLE comments ADD CONSTRAINT comments_post_id_fkey FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE;""")
+
+
+def downgrade():
+    op.execute("""DROP TABLE comments;""")
+    op.execute("""ALTER TYPE post_status RENAME TO post_status_old;""")
+    op.execute("""CREATE TYPE post_status AS ENUM ('draft', 'published');""")
+    op.execute("""ALTER TABLE posts ALTER COLUMN status DROP DEFAULT;""")
+    op.execute("""ALTER TABLE posts ALTER COLUMN status TYPE post_status USING status::text::post_status;""")
+    op.execute("""ALTER TABLE posts ALTER COLUMN status SET DEFAULT 'draft'::post_status;""")
+    op.execute("""DROP TYPE post_status_old;""")
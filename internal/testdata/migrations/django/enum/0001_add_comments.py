@@ -0,0 +1,39 @@
+# Generated by schema migrate-plan on 2026-10-01 12:30
+
+from django.db import migrations
+
+
+class Migration(migrations.Migration):
+
+    atomic = False
+
+    initial = True
+
+    dependencies = []
+
+    operations = [
+        migrations.RunSQL(
+            sql=[
+                """ALTER TYPE post_status ADD VALUE 'archived' AFTER 'published';""",
+                """BEGIN;""",
+                """CREATE TABLE comments (
+    id bigint NOT NULL,
+    post_id bigint NOT NULL,
+    CONSTRAINT comments_pkey PRIMARY KEY (id)
+);""",
+                """ALTER TABLE comments ADD CONSTRAINT comments_post_id_fkey FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE;""",
+                """COMMIT;""",
+            ],
+            reverse_sql=[
+                """BEGIN;""",
+                """DROP TABLE comments;""",
+                """ALTER TYPE post_status RENAME TO post_status_old;""",
+                """CREATE TYPE post_status AS ENUM ('draft', 'published');""",
+                """ALTER TABLE posts ALTER COLUMN status DROP DEFAULT;""",
+                """ALTER TABLE posts ALTER COLUMN status TYPE post_status USING status::text::post_status;""",
+                """ALTER TABLE posts ALTER COLUMN status SET DEFAULT 'draft'::post_status;""",
+                """DROP TYPE post_status_old;""",
+                """COMMIT;""",
+            ],
+        ),
+    ]
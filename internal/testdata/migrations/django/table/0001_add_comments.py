@@ -0,0 +1,26 @@
+# Generated by schema migrate-plan on 2026-10-01 12:30
+
+from django.db import migrations
+
+
+class Migration(migrations.Migration):
+
+    initial = True
+
+    dependencies = []
+
+    operations = [
+        migrations.RunSQL(
+            sql=[
+                """CREATE TABLE comments (
+    id bigint NOT NULL,
+    post_id bigint NOT NULL,
+    CONSTRAINT comments_pkey PRIMARY KEY (id)
+);""",
+                """ALTER TABLE comments ADD CONSTRAINT comments_post_id_fkey FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE;""",
+            ],
+            reverse_sql=[
+                """DROP TABLE comments;""",
+            ],
+        ),
+    ]
@@ -0,0 +1,11 @@
+BEGIN;
+
+DROP TABLE comments;
+ALTER TYPE post_status RENAME TO post_status_old;
+CREATE TYPE post_status AS ENUM ('draft', 'published');
+ALTER TABLE posts ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE posts ALTER COLUMN status TYPE post_status USING status::text::post_status;
+ALTER TABLE posts ALTER COLUMN status SET DEFAULT 'draft'::post_status;
+DROP TYPE post_status_old;
+
+COMMIT;
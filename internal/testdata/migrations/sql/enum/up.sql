@@ -0,0 +1,12 @@
+ALTER TYPE post_status ADD VALUE 'archived' AFTER 'published';
+
+BEGIN;
+
+CREATE TABLE comments (
+    id bigint NOT NULL,
+    post_id bigint NOT NULL,
+    CONSTRAINT comments_pkey PRIMARY KEY (id)
+);
+ALTER TABLE comments ADD CONSTRAINT comments_post_id_fkey FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE;
+
+COMMIT;
@@ -0,0 +1,45 @@
+CREATE TYPE post_status AS ENUM ('draft', 'published');
+
+CREATE TABLE tags (
+    id serial NOT NULL,
+    name text NOT NULL,
+    CONSTRAINT tags_pkey PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX tags_name_key ON public.tags USING btree (name);
+
+CREATE TABLE users (
+    id bigint GENERATED BY DEFAULT AS IDENTITY NOT NULL,
+    email text NOT NULL,
+    name character varying(100) NULL,
+    bio text DEFAULT ''::text NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT users_pkey PRIMARY KEY (id),
+    CONSTRAINT users_email_key UNIQUE (email)
+);
+COMMENT ON TABLE users IS 'People who write posts';
+
+CREATE TABLE posts (
+    id bigint GENERATED BY DEFAULT AS IDENTITY NOT NULL,
+    author_id bigint NOT NULL,
+    editor_id bigint NULL,
+    type text DEFAULT 'post'::text NOT NULL,
+    status post_status DEFAULT 'draft'::post_status NOT NULL,
+    title character varying(200) NOT NULL,
+    rating integer NULL,
+    CONSTRAINT posts_pkey PRIMARY KEY (id),
+    CONSTRAINT posts_author_id_fkey FOREIGN KEY (author_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT posts_editor_id_fkey FOREIGN KEY (editor_id) REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT posts_type_check CHECK (type = ANY (ARRAY['post'::text, 'page'::text])),
+    CONSTRAINT posts_rating_check CHECK ((rating >= 1) AND (rating <= 5)),
+    CONSTRAINT posts_title_check CHECK (length((title)::text) > 0)
+);
+CREATE INDEX posts_author_id_idx ON public.posts USING btree (author_id);
+
+CREATE TABLE post_tags (
+    post_id bigint NOT NULL,
+    tag_id integer NOT NULL,
+    CONSTRAINT post_tags_pkey PRIMARY KEY (post_id, tag_id),
+    CONSTRAINT post_tags_post_id_fkey FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    CONSTRAINT post_tags_tag_id_fkey FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+);
+
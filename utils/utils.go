@@ -88,7 +88,7 @@ func (c *AzureAIClient) CreateCompletions(prompt string) (any, error) {
 		"messages": []map[string]interface{}{
 			{
 				"role":    "system",
-				"content": "You are an experience software engineer, with more that 20 years experience with a specialization in backend development with vast knowledge in Python, Go, CPP, Java, Rust, Typescript and SQL, you mostly be helping in transforming raw schema to an ORM model, for Python use Django orm/ Schalchemy if specified, for Go use GORM, for rust use diesel, for Typescript use Typeorm, for java use springboot jdbc.",
+				"content": "You are an experience software engineer, with more that 20 years experience with a specialization in backend development with vast knowledge in Python, Go, CPP, Java, Rust, Typescript and SQL, you mostly be helping in transforming raw schema to an ORM model, for Python use Django orm/ Schalchemy if specified, for Go use GORM, for rust use diesel, for Typescript use Typeorm, for java use JPA (Hibernate) entities with Spring Data repositories, for kotlin use Exposed, for C# use Entity Framework Core.",
 			},
 			{
 				"role":    "user",